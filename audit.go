@@ -0,0 +1,40 @@
+package rolestore
+
+import "context"
+
+// AuditEntry describes a privileged change made by the store
+type AuditEntry struct {
+	// Action is what was done, one of the AUDIT_ACTION_* constants
+	Action string
+
+	// Actor is the entity, which made (or approved) the change
+	Actor EntityRef
+
+	// Entity is the entity, which the change was made for
+	Entity EntityRef
+
+	// RoleID is the ID of the role concerned
+	RoleID string
+
+	// EntityRoleID is the ID of the role entity mapping concerned
+	EntityRoleID string
+
+	// Reason is why the change was made
+	Reason string
+
+	// ExpiresAt is when the change expires, empty if it does not
+	ExpiresAt string
+
+	// CreatedAt is when the change was made
+	CreatedAt string
+}
+
+// AuditHook is called with an audit entry, after the change it describes is saved
+type AuditHook func(ctx context.Context, entry AuditEntry)
+
+// audit calls the audit hook, if set
+func (store *store) audit(ctx context.Context, entry AuditEntry) {
+	if store.auditHook != nil {
+		store.auditHook(ctx, entry)
+	}
+}
@@ -4,19 +4,101 @@ const ERROR_EMPTY_ARRAY = "array cannot be empty"
 const ERROR_EMPTY_STRING = "string cannot be empty"
 const ERROR_NEGATIVE_NUMBER = "number cannot be negative"
 
+const COLUMN_ACTIVE_ROLE_IDS = "active_role_ids"
+const COLUMN_CAMPAIGN_ID = "campaign_id"
+const COLUMN_CLOSED_AT = "closed_at"
 const COLUMN_CREATED_AT = "created_at"
+const COLUMN_DECIDED_AT = "decided_at"
+const COLUMN_DECIDED_BY_ID = "decided_by_id"
+const COLUMN_DECIDED_BY_TYPE = "decided_by_type"
+const COLUMN_DECISION = "decision"
+const COLUMN_DECISION_NOTE = "decision_note"
+const COLUMN_DELEGATE_ID = "delegate_id"
+const COLUMN_DELEGATE_TYPE = "delegate_type"
+const COLUMN_DELEGATOR_ID = "delegator_id"
+const COLUMN_DELEGATOR_TYPE = "delegator_type"
+const COLUMN_DUE_AT = "due_at"
+const COLUMN_ENDS_AT = "ends_at"
 const COLUMN_ENTITY_ID = "entity_id"
+const COLUMN_ENTITY_ROLE_ID = "entity_role_id"
 const COLUMN_ENTITY_TYPE = "entity_type"
+const COLUMN_EXPIRES_AT = "expires_at"
 const COLUMN_HANDLE = "handle"
+const COLUMN_GRANTED_BY_ID = "granted_by_id"
+const COLUMN_GRANTED_BY_TYPE = "granted_by_type"
 const COLUMN_ID = "id"
+const COLUMN_JUSTIFICATION = "justification"
+const COLUMN_KIND = "kind"
+const COLUMN_MAX_ROLES = "max_roles"
 const COLUMN_MEMO = "memo"
 const COLUMN_METAS = "metas"
+const COLUMN_NO_REDELEGATION = "no_redelegation"
+const COLUMN_REASON = "reason"
+const COLUMN_REVIEWER_ID = "reviewer_id"
+const COLUMN_REVIEWER_TYPE = "reviewer_type"
 const COLUMN_ROLE_ID = "role_id"
+const COLUMN_ROLE_IDS = "role_ids"
 const COLUMN_STATUS = "status"
 const COLUMN_SOFT_DELETED_AT = "soft_deleted_at"
+const COLUMN_SOURCE = "source"
+const COLUMN_STARTS_AT = "starts_at"
 const COLUMN_TITLE = "title"
 const COLUMN_UPDATED_AT = "updated_at"
+const COLUMN_VERSION = "version"
+
+const ASC = "asc"
+const DESC = "desc"
 
 const ROLE_STATUS_ACTIVE = "active"
 const ROLE_STATUS_INACTIVE = "inactive"
 const ROLE_STATUS_DELETED = "deleted"
+
+const ENTITY_TYPE_USER = "user"
+
+const USER_ROLE_SYNC_NONE = ""
+const USER_ROLE_SYNC_TO_ENTITY_ROLES = "to_entity_roles"
+const USER_ROLE_SYNC_FROM_ENTITY_ROLES = "from_entity_roles"
+
+const ROLE_META_APPROVERS = "approvers"
+const ROLE_META_BREAK_GLASS = "break_glass"
+const ROLE_META_ELEVATION_MAX_DURATION = "elevation_max_duration"
+const ROLE_META_ENTITY_TYPES = "entity_types"
+const ROLE_META_MAX_HOLDERS = "max_holders"
+
+const SOD_KIND_STATIC = "static"
+const SOD_KIND_DYNAMIC = "dynamic"
+
+const ENTITY_ROLE_SOURCE_MANUAL = "manual"
+const ENTITY_ROLE_SOURCE_SSO = "sso"
+const ENTITY_ROLE_SOURCE_SYNC = "sync"
+const ENTITY_ROLE_SOURCE_API = "api"
+const ENTITY_ROLE_SOURCE_RULE = "rule"
+const ENTITY_ROLE_SOURCE_REQUEST = "request"
+const ENTITY_ROLE_SOURCE_ELEVATION = "elevation"
+
+const ENTITY_ROLE_META_REVIEW_REQUIRED = "review_required"
+const ENTITY_ROLE_META_REVIEWED_AT = "reviewed_at"
+const ENTITY_ROLE_META_REVIEWED_BY = "reviewed_by"
+
+const AUDIT_ACTION_ELEVATE = "elevate"
+const AUDIT_ACTION_BREAK_GLASS = "break_glass"
+const AUDIT_ACTION_BREAK_GLASS_REVIEWED = "break_glass_reviewed"
+
+const ROLE_REQUEST_STATUS_PENDING = "pending"
+const ROLE_REQUEST_STATUS_APPROVED = "approved"
+const ROLE_REQUEST_STATUS_REJECTED = "rejected"
+const ROLE_REQUEST_STATUS_CANCELLED = "cancelled"
+const ROLE_REQUEST_STATUS_EXPIRED = "expired"
+
+const ROLE_REQUEST_EVENT_CREATED = "created"
+const ROLE_REQUEST_EVENT_APPROVED = "approved"
+const ROLE_REQUEST_EVENT_REJECTED = "rejected"
+const ROLE_REQUEST_EVENT_CANCELLED = "cancelled"
+const ROLE_REQUEST_EVENT_EXPIRED = "expired"
+
+const REVIEW_CAMPAIGN_STATUS_OPEN = "open"
+const REVIEW_CAMPAIGN_STATUS_CLOSED = "closed"
+
+const REVIEW_DECISION_PENDING = "pending"
+const REVIEW_DECISION_KEEP = "keep"
+const REVIEW_DECISION_REVOKE = "revoke"
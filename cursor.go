@@ -0,0 +1,187 @@
+package rolestore
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/dromara/carbon/v2"
+)
+
+// CursorPage is a page of a keyset (cursor) paginated list
+type CursorPage[T any] struct {
+	// Items are the items on the page
+	Items []T
+
+	// NextCursor is the opaque cursor pointing to the next page,
+	// empty if there are no more items
+	NextCursor string
+
+	// HasMore indicates whether there are more items after this page
+	HasMore bool
+}
+
+// cursorKey is a column the keyset pagination is ordered by
+type cursorKey struct {
+	column string
+	asc    bool
+}
+
+// cursorData is the decoded content of an opaque cursor
+type cursorData struct {
+	Columns []string `json:"c"`
+	Values  []string `json:"v"`
+}
+
+// cursorKeys returns the keys for keyset pagination from the sort keys
+// of the query, always ending with the ID column, so that the order is
+// unique and stable. Without sort keys, the sort direction applies to the ID
+func cursorKeys(orderBys []OrderBy, sortDirection string) []cursorKey {
+	keys := []cursorKey{}
+
+	for _, orderBy := range orderBys {
+		keys = append(keys, cursorKey{column: orderBy.Column, asc: orderBy.IsAsc()})
+
+		if orderBy.Column == COLUMN_ID {
+			return keys // the ID is unique, the rest of the keys are redundant
+		}
+	}
+
+	asc := strings.EqualFold(sortDirection, ASC)
+
+	if len(keys) > 0 {
+		asc = keys[len(keys)-1].asc
+	}
+
+	return append(keys, cursorKey{column: COLUMN_ID, asc: asc})
+}
+
+// cursorColumns returns the column names of the keys
+func cursorColumns(keys []cursorKey) []string {
+	columns := make([]string, 0, len(keys))
+
+	for _, key := range keys {
+		columns = append(columns, key.column)
+	}
+
+	return columns
+}
+
+// cursorOrder returns the order expressions for the keys,
+// with the columns qualified by the table name
+func cursorOrder(keys []cursorKey, table string) []exp.OrderedExpression {
+	order := make([]exp.OrderedExpression, 0, len(keys))
+
+	for _, key := range keys {
+		if key.asc {
+			order = append(order, goqu.T(table).Col(key.column).Asc())
+		} else {
+			order = append(order, goqu.T(table).Col(key.column).Desc())
+		}
+	}
+
+	return order
+}
+
+// cursorCondition returns the condition selecting the rows after the cursor:
+//
+//	(k1 > v1) OR (k1 = v1 AND k2 > v2) OR ...
+//
+// with < instead of > for the descending keys, and the columns qualified by the table name
+func cursorCondition(keys []cursorKey, cursor cursorData, table string) (exp.Expression, error) {
+	if !slices.Equal(cursor.Columns, cursorColumns(keys)) || len(cursor.Values) != len(keys) {
+		return nil, errors.New("cursor does not match the order of the query")
+	}
+
+	or := []exp.Expression{}
+
+	for i, key := range keys {
+		and := []exp.Expression{}
+
+		for j := 0; j < i; j++ {
+			and = append(and, goqu.T(table).Col(keys[j].column).Eq(cursor.Values[j]))
+		}
+
+		if key.asc {
+			and = append(and, goqu.T(table).Col(key.column).Gt(cursor.Values[i]))
+		} else {
+			and = append(and, goqu.T(table).Col(key.column).Lt(cursor.Values[i]))
+		}
+
+		or = append(or, goqu.And(and...))
+	}
+
+	return goqu.Or(or...), nil
+}
+
+// cursorEncode creates an opaque cursor from the key values of the given row
+func cursorEncode(keys []cursorKey, data map[string]string) (string, error) {
+	cursor := cursorData{
+		Columns: cursorColumns(keys),
+		Values:  make([]string, 0, len(keys)),
+	}
+
+	for _, key := range keys {
+		value := data[key.column]
+
+		if isDateTimeColumn(key.column) {
+			value = carbon.Parse(value, carbon.UTC).ToDateTimeString(carbon.UTC)
+		}
+
+		cursor.Values = append(cursor.Values, value)
+	}
+
+	jsonBytes, err := json.Marshal(cursor)
+
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(jsonBytes), nil
+}
+
+// cursorDecode decodes an opaque cursor
+func cursorDecode(cursor string) (cursorData, error) {
+	data := cursorData{}
+
+	jsonBytes, err := base64.RawURLEncoding.DecodeString(cursor)
+
+	if err != nil {
+		return data, errors.New("cursor is invalid")
+	}
+
+	if err := json.Unmarshal(jsonBytes, &data); err != nil {
+		return data, errors.New("cursor is invalid")
+	}
+
+	return data, nil
+}
+
+// cursorEnsureColumns adds the key columns to the selected columns,
+// if specific columns are selected, as the cursor is built from them
+func cursorEnsureColumns(columns []string, keys []cursorKey) []string {
+	if len(columns) == 0 {
+		return columns // all columns selected
+	}
+
+	columns = slices.Clone(columns)
+
+	for _, key := range keys {
+		if !slices.Contains(columns, key.column) {
+			columns = append(columns, key.column)
+		}
+	}
+
+	return columns
+}
+
+// isDateTimeColumn returns whether the column holds a datetime value
+func isDateTimeColumn(column string) bool {
+	return column == COLUMN_CREATED_AT ||
+		column == COLUMN_UPDATED_AT ||
+		column == COLUMN_SOFT_DELETED_AT
+}
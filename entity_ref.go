@@ -0,0 +1,17 @@
+package rolestore
+
+// EntityRef is a reference to an entity (i.e. a user, a group) by its type and ID
+type EntityRef struct {
+	EntityType string
+	EntityID   string
+}
+
+// NewEntityRef creates a new entity reference
+func NewEntityRef(entityType string, entityID string) EntityRef {
+	return EntityRef{EntityType: entityType, EntityID: entityID}
+}
+
+// IsEmpty returns whether the reference is missing the entity type or ID
+func (ref EntityRef) IsEmpty() bool {
+	return ref.EntityType == "" || ref.EntityID == ""
+}
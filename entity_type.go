@@ -0,0 +1,86 @@
+package rolestore
+
+import (
+	"context"
+	"fmt"
+)
+
+// EntityIDValidator validates the ID of an entity of a registered type,
+// returning an error if the ID is not in the expected format
+type EntityIDValidator func(entityID string) error
+
+// validateEntity checks the entity type against the entity type registry,
+// and the entity ID against the validator of the type, if any.
+// Without a registry any entity type is accepted
+func (store *store) validateEntity(entityType string, entityID string) error {
+	if len(store.entityTypes) == 0 {
+		return nil
+	}
+
+	validator, registered := store.entityTypes[entityType]
+
+	if !registered {
+		return fmt.Errorf("%w: %s", ErrEntityTypeUnknown, entityType)
+	}
+
+	if validator == nil || entityID == "" {
+		return nil
+	}
+
+	if err := validator(entityID); err != nil {
+		return fmt.Errorf("rolestore: invalid %s entity ID %s: %w", entityType, entityID, err)
+	}
+
+	return nil
+}
+
+// validateEntityRoleQuery checks the entity types the query filters on
+// against the entity type registry
+func (store *store) validateEntityRoleQuery(query EntityRoleQueryInterface) error {
+	if len(store.entityTypes) == 0 {
+		return nil
+	}
+
+	if query.HasEntityType() {
+		entityID := ""
+
+		if query.HasEntityID() {
+			entityID = query.EntityID()
+		}
+
+		if err := store.validateEntity(query.EntityType(), entityID); err != nil {
+			return err
+		}
+	}
+
+	if query.HasEntityTypeIn() {
+		for _, entityType := range query.EntityTypeIn() {
+			if err := store.validateEntity(entityType, ""); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateEntityRole checks a role entity mapping before it is stored:
+// the entity type and ID against the registry, and the entity type
+// against the entity types the role declares
+func (store *store) validateEntityRole(ctx context.Context, entityRole EntityRoleInterface) error {
+	if err := store.validateEntity(entityRole.EntityType(), entityRole.EntityID()); err != nil {
+		return err
+	}
+
+	role, err := store.RoleFindByID(ctx, entityRole.RoleID())
+
+	if err != nil {
+		return err
+	}
+
+	if role != nil && !role.AllowsEntityType(entityRole.EntityType()) {
+		return fmt.Errorf("%w: role %s, entity type %s", ErrEntityTypeNotAllowed, role.Handle(), entityRole.EntityType())
+	}
+
+	return nil
+}
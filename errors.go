@@ -0,0 +1,62 @@
+package rolestore
+
+import "errors"
+
+// ErrConflict is returned by the update methods when no row matched the
+// expected version, i.e. the record was modified (or removed) by someone
+// else after it was read
+var ErrConflict = errors.New("rolestore: conflict, the record was modified by someone else")
+
+// ErrEntityTypeUnknown is returned when an entity type is not in the
+// entity type registry configured on the store
+var ErrEntityTypeUnknown = errors.New("rolestore: unknown entity type")
+
+// ErrEntityTypeNotAllowed is returned when a role is assigned to an entity
+// of a type, which the role does not declare in its entity types
+var ErrEntityTypeNotAllowed = errors.New("rolestore: entity type not allowed for role")
+
+// ErrSodViolation is matched (via errors.Is) by SodViolationError,
+// returned when an assignment would break a separation of duties constraint
+var ErrSodViolation = errors.New("rolestore: separation of duties violation")
+
+// ErrSessionNotFound is returned by the session methods when the session
+// does not exist or has expired
+var ErrSessionNotFound = errors.New("rolestore: session not found or expired")
+
+// ErrRoleMaxHolders is returned when a role is assigned to more entities
+// than its maximum holders limit allows
+var ErrRoleMaxHolders = errors.New("rolestore: role has reached its maximum number of holders")
+
+// ErrRoleRequestNotFound is returned by the role request methods
+// when the request does not exist
+var ErrRoleRequestNotFound = errors.New("rolestore: role request not found")
+
+// ErrRoleRequestNotPending is returned when deciding on, or cancelling,
+// a request which is no longer pending (or is past its expiry date)
+var ErrRoleRequestNotPending = errors.New("rolestore: role request is not pending")
+
+// ErrRoleRequestApproverNotAllowed is returned when a request is decided by
+// an entity, which is not an approver of the role, or is the requester itself
+var ErrRoleRequestApproverNotAllowed = errors.New("rolestore: entity is not allowed to approve the role request")
+
+// ErrReviewCampaignNotFound is returned by the access review methods
+// when the campaign does not exist
+var ErrReviewCampaignNotFound = errors.New("rolestore: review campaign not found")
+
+// ErrReviewCampaignClosed is returned when deciding on an item,
+// or closing a campaign, which is already closed
+var ErrReviewCampaignClosed = errors.New("rolestore: review campaign is closed")
+
+// ErrReviewerNotAllowed is returned when an item is decided by an entity,
+// which is not the reviewer assigned to the item
+var ErrReviewerNotAllowed = errors.New("rolestore: entity is not the reviewer of the review item")
+
+// ErrElevationNotAllowed is returned when a just-in-time elevation lacks
+// a valid approver (for roles which are not break-glass), or exceeds the
+// maximum elevation duration of the role
+var ErrElevationNotAllowed = errors.New("rolestore: elevation not allowed")
+
+// ErrDelegationNotAllowed is returned when an entity delegates a role,
+// which it does not hold, or holds only by a delegation not allowing
+// redelegation
+var ErrDelegationNotAllowed = errors.New("rolestore: delegation not allowed")
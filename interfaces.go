@@ -3,6 +3,9 @@ package rolestore
 import (
 	"context"
 	"database/sql"
+	"io"
+	"iter"
+	"time"
 
 	"github.com/dromara/carbon/v2"
 )
@@ -17,6 +20,13 @@ type StoreInterface interface {
 	// DB returns the underlying database connection
 	DB() *sql.DB
 
+	// WithTx runs fn in a transaction, committing on success and rolling back
+	// on error or panic. Nested calls use savepoints, where supported
+	WithTx(ctx context.Context, fn func(txCtx context.Context) error) error
+
+	// WithTxOptions runs fn in a transaction with the given options (i.e. isolation level)
+	WithTxOptions(ctx context.Context, opts *sql.TxOptions, fn func(txCtx context.Context) error) error
+
 	// == Role Methods =======================================================//
 
 	// RoleCount returns the number of roles based on the given query options
@@ -40,15 +50,31 @@ type StoreInterface interface {
 	// RoleList returns a list of roles based on the given query options
 	RoleList(ctx context.Context, query RoleQueryInterface) ([]RoleInterface, error)
 
+	// RoleListByCursor returns a page of roles using keyset (cursor) pagination
+	RoleListByCursor(ctx context.Context, query RoleQueryInterface) (CursorPage[RoleInterface], error)
+
+	// RoleListPaged returns a page of roles together with the total count
+	RoleListPaged(ctx context.Context, query RoleQueryInterface) (PagedResult[RoleInterface], error)
+
+	// RoleIter streams the roles matching the query, without loading them all into memory
+	RoleIter(ctx context.Context, query RoleQueryInterface) iter.Seq2[RoleInterface, error]
+
+	// RoleSeats returns how many of the maximum holders of the role are used
+	RoleSeats(ctx context.Context, roleID string) (RoleSeats, error)
+
 	// RoleSoftDelete soft deletes a role
 	RoleSoftDelete(ctx context.Context, role RoleInterface) error
 
 	// RoleSoftDeleteByID soft deletes a role by its ID
 	RoleSoftDeleteByID(ctx context.Context, id string) error
 
-	// RoleUpdate updates a role
+	// RoleUpdate updates a role, returns ErrConflict if the role
+	// was modified by someone else since it was read
 	RoleUpdate(ctx context.Context, role RoleInterface) error
 
+	// RoleUpsertByHandle creates the role, or updates the existing role with the same handle
+	RoleUpsertByHandle(ctx context.Context, role RoleInterface) error
+
 	// == EntityRole Methods =================================================//
 
 	// EntityRoleCount returns the number of role entities mappings based on the given query options
@@ -72,14 +98,211 @@ type StoreInterface interface {
 	// EntityRoleList returns a list of role entity mappings based on the given query options
 	EntityRoleList(ctx context.Context, query EntityRoleQueryInterface) ([]EntityRoleInterface, error)
 
+	// EntityRoleListByCursor returns a page of role entity mappings using keyset (cursor) pagination
+	EntityRoleListByCursor(ctx context.Context, query EntityRoleQueryInterface) (CursorPage[EntityRoleInterface], error)
+
+	// EntityRoleListPaged returns a page of role entity mappings together with the total count
+	EntityRoleListPaged(ctx context.Context, query EntityRoleQueryInterface) (PagedResult[EntityRoleInterface], error)
+
+	// EntityRoleIter streams the role entity mappings matching the query, without loading them all into memory
+	EntityRoleIter(ctx context.Context, query EntityRoleQueryInterface) iter.Seq2[EntityRoleInterface, error]
+
+	// EntityRoleListWithRole returns a list of role entity mappings, each together with its role
+	EntityRoleListWithRole(ctx context.Context, query EntityRoleQueryInterface) ([]EntityRoleWithRole, error)
+
+	// EntityRoleRevokeBySource soft deletes the role entity mappings with the given source, optionally granted by the given entity
+	EntityRoleRevokeBySource(ctx context.Context, source string, grantedBy EntityRef) (int64, error)
+
 	// EntityRoleSoftDelete soft deletes a role entity mapping
 	EntityRoleSoftDelete(ctx context.Context, entityRole EntityRoleInterface) error
 
 	// EntityRoleSoftDeleteByID soft deletes a role entity mapping by its ID
 	EntityRoleSoftDeleteByID(ctx context.Context, id string) error
 
-	// EntityRoleUpdate updates a role entity mapping
+	// EntityRoleUpdate updates a role entity mapping, returns ErrConflict
+	// if the mapping was modified by someone else since it was read
 	EntityRoleUpdate(ctx context.Context, entityRole EntityRoleInterface) error
+
+	// EntityRoleUpsert creates the role entity mapping, or updates the existing one with the same entity type, entity ID and role ID
+	EntityRoleUpsert(ctx context.Context, entityRole EntityRoleInterface) error
+
+	// == Offboarding Methods ================================================//
+
+	// EntityRevokeAll revokes all roles of the entity, by soft deleting or deleting the mappings
+	EntityRevokeAll(ctx context.Context, entityType string, entityID string, hardDelete bool) (EntityRolesChange, error)
+
+	// EntityCopyRoles assigns all roles of the from entity to the to entity, skipping duplicates
+	EntityCopyRoles(ctx context.Context, from EntityRef, to EntityRef) (EntityRolesChange, error)
+
+	// EntityTransferRoles moves all roles of the from entity to the to entity
+	EntityTransferRoles(ctx context.Context, from EntityRef, to EntityRef) (EntityRolesChange, error)
+
+	// == User Methods =======================================================//
+
+	// UserRoles returns the active roles of the user
+	UserRoles(ctx context.Context, user UserInterface) ([]RoleInterface, error)
+
+	// UserAssignRole assigns the role with the given handle to the user
+	UserAssignRole(ctx context.Context, user UserInterface, handle string) error
+
+	// UserHasRole returns whether the user holds the active role with the given handle
+	UserHasRole(ctx context.Context, user UserInterface, handle string) (bool, error)
+
+	// UserSyncRole syncs the legacy role field of the user with the role entity mappings
+	UserSyncRole(ctx context.Context, user UserInterface) error
+
+	// == Separation of Duties Methods =======================================//
+
+	// SodConstraintCreate creates a separation of duties constraint
+	SodConstraintCreate(ctx context.Context, constraint SodConstraintInterface) error
+
+	// SodConstraintDelete deletes a separation of duties constraint
+	SodConstraintDelete(ctx context.Context, constraint SodConstraintInterface) error
+
+	// SodConstraintDeleteByID deletes a separation of duties constraint by its ID
+	SodConstraintDeleteByID(ctx context.Context, id string) error
+
+	// SodConstraintFindByID returns a separation of duties constraint by its ID
+	SodConstraintFindByID(ctx context.Context, id string) (SodConstraintInterface, error)
+
+	// SodConstraintList returns the separation of duties constraints
+	SodConstraintList(ctx context.Context) ([]SodConstraintInterface, error)
+
+	// SodViolations reports the separation of duties violations in the current data
+	SodViolations(ctx context.Context) ([]SodViolation, error)
+
+	// == Session Methods ====================================================//
+
+	// SessionCreate creates a role activation session for the entity, expiring after the ttl
+	SessionCreate(ctx context.Context, entityType string, entityID string, ttl time.Duration) (SessionInterface, error)
+
+	// SessionFindByID returns an unexpired session by its ID
+	SessionFindByID(ctx context.Context, id string) (SessionInterface, error)
+
+	// SessionDelete deletes a session by its ID
+	SessionDelete(ctx context.Context, id string) error
+
+	// SessionDeleteExpired deletes the expired sessions, returns the number deleted
+	SessionDeleteExpired(ctx context.Context) (int64, error)
+
+	// SessionActivateRole activates a role assigned to the session entity in the session
+	SessionActivateRole(ctx context.Context, sessionID string, roleID string) error
+
+	// SessionDeactivateRole deactivates a role in the session
+	SessionDeactivateRole(ctx context.Context, sessionID string, roleID string) error
+
+	// SessionHasRole returns whether the role is active in the session and still assigned
+	SessionHasRole(ctx context.Context, sessionID string, roleID string) (bool, error)
+
+	// == Elevation Methods ==================================================//
+
+	// EntityRoleElevate grants a role for a bounded duration, just in time
+	EntityRoleElevate(ctx context.Context, options ElevationOptions) (EntityRoleInterface, error)
+
+	// EntityRoleListReviewRequired returns the break-glass elevations not reviewed yet
+	EntityRoleListReviewRequired(ctx context.Context) ([]EntityRoleInterface, error)
+
+	// EntityRoleMarkReviewed marks a break-glass elevation as reviewed
+	EntityRoleMarkReviewed(ctx context.Context, entityRoleID string, reviewer EntityRef) error
+
+	// == Role Request Methods ===============================================//
+
+	// RoleRequestCreate creates a pending request of an entity for a role
+	RoleRequestCreate(ctx context.Context, request RoleRequestInterface) error
+
+	// RoleRequestApprove approves a pending request and assigns the role to the requesting entity
+	RoleRequestApprove(ctx context.Context, requestID string, approver EntityRef, note string) (EntityRoleInterface, error)
+
+	// RoleRequestReject rejects a pending request
+	RoleRequestReject(ctx context.Context, requestID string, approver EntityRef, note string) error
+
+	// RoleRequestCancel cancels a pending request, i.e. on behalf of the requesting entity
+	RoleRequestCancel(ctx context.Context, requestID string) error
+
+	// RoleRequestExpireStale marks the pending requests past their expiry date as expired, returns the number expired
+	RoleRequestExpireStale(ctx context.Context) (int64, error)
+
+	// RoleRequestFindByID returns a role request by its ID
+	RoleRequestFindByID(ctx context.Context, id string) (RoleRequestInterface, error)
+
+	// RoleRequestList returns the role requests based on the given query options
+	RoleRequestList(ctx context.Context, query RoleRequestQueryInterface) ([]RoleRequestInterface, error)
+
+	// RoleRequestListForApprover returns the pending requests for the roles the entity may approve
+	RoleRequestListForApprover(ctx context.Context, approver EntityRef) ([]RoleRequestInterface, error)
+
+	// == Access Review Methods ==============================================//
+
+	// ReviewCampaignCreate creates a review campaign with a snapshot of the assignments of its roles
+	ReviewCampaignCreate(ctx context.Context, campaign ReviewCampaignInterface, reviewer ReviewerResolver) error
+
+	// ReviewCampaignClose closes a campaign and revokes the assignments decided to be revoked
+	ReviewCampaignClose(ctx context.Context, campaignID string) (int64, error)
+
+	// ReviewCampaignFindByID returns a review campaign by its ID
+	ReviewCampaignFindByID(ctx context.Context, id string) (ReviewCampaignInterface, error)
+
+	// ReviewCampaignList returns the review campaigns with the given status, or all if empty
+	ReviewCampaignList(ctx context.Context, status string) ([]ReviewCampaignInterface, error)
+
+	// ReviewCampaignProgress returns how many items of the campaign are decided
+	ReviewCampaignProgress(ctx context.Context, campaignID string) (ReviewProgress, error)
+
+	// ReviewCampaignReport writes the items of the campaign with their decisions as CSV
+	ReviewCampaignReport(ctx context.Context, campaignID string, w io.Writer) error
+
+	// ReviewItemAssignReviewer assigns the reviewer of a review item
+	ReviewItemAssignReviewer(ctx context.Context, itemID string, reviewer EntityRef) error
+
+	// ReviewItemDecide records the keep or revoke decision of the reviewer of a review item
+	ReviewItemDecide(ctx context.Context, itemID string, reviewer EntityRef, decision string, note string) error
+
+	// ReviewItemList returns the items of the campaign, optionally only those of the reviewer
+	ReviewItemList(ctx context.Context, campaignID string, reviewer EntityRef) ([]ReviewItemInterface, error)
+
+	// == Delegation Methods =================================================//
+
+	// DelegationCreate delegates a role held by the delegator to the delegate for a time window,
+	// ending at the latest when the delegator stops holding the role. The delegated role
+	// counts as held by the delegate for the static separation of duties constraints
+	DelegationCreate(ctx context.Context, delegation DelegationInterface) error
+
+	// DelegationFindByID returns a delegation by its ID
+	DelegationFindByID(ctx context.Context, id string) (DelegationInterface, error)
+
+	// DelegationList returns the delegations matching the options
+	DelegationList(ctx context.Context, options DelegationListOptions) ([]DelegationInterface, error)
+
+	// DelegationRevoke revokes a delegation by its ID
+	DelegationRevoke(ctx context.Context, id string) error
+
+	// EntityEffectiveRoles returns the active roles the entity holds, directly or by delegation
+	EntityEffectiveRoles(ctx context.Context, entityType string, entityID string) ([]RoleInterface, error)
+
+	// EntityHasRole returns whether the entity holds the active role, directly or by delegation
+	EntityHasRole(ctx context.Context, entityType string, entityID string, roleID string) (bool, error)
+
+	// == Lookup Methods =====================================================//
+
+	// EntitiesRoles returns the active roles of each of the given entities, keyed by entity ID
+	EntitiesRoles(ctx context.Context, entityType string, entityIDs []string) (map[string][]RoleInterface, error)
+
+	// RoleEntities returns the entities holding the role with the given handle
+	RoleEntities(ctx context.Context, handle string, options RoleEntitiesOptions) ([]EntityRef, error)
+
+	// RoleEntitiesCount returns the number of distinct entities holding the role with the given handle
+	RoleEntitiesCount(ctx context.Context, handle string, options RoleEntitiesOptions) (int64, error)
+
+	// == Statistics Methods =================================================//
+
+	// RoleAssignmentCounts returns the number of live assignments of live roles, optionally per entity type too
+	RoleAssignmentCounts(ctx context.Context, groupByEntityType bool) ([]RoleAssignmentCount, error)
+
+	// RoleListUnused returns the live roles without live assignments
+	RoleListUnused(ctx context.Context) ([]RoleInterface, error)
+
+	// EntityRoleCountHistogram returns how many entities hold each number of live roles
+	EntityRoleCountHistogram(ctx context.Context, entityType string) ([]EntityRoleCountBucket, error)
 }
 
 type RoleInterface interface {
@@ -91,22 +314,39 @@ type RoleInterface interface {
 
 	// methods
 
+	AllowsEntityType(entityType string) bool
 	IsActive() bool
+	IsApprover(entity EntityRef) bool
+	IsBreakGlass() bool
 	IsInactive() bool
 	IsSoftDeleted() bool
 
 	// setters and getters
 
+	Approvers() []EntityRef
+	SetApprovers(approvers []EntityRef) error
+
+	SetBreakGlass(breakGlass bool) error
+
 	CreatedAt() string
 	CreatedAtCarbon() carbon.Carbon
 	SetCreatedAt(createdAt string) RoleInterface
 
+	ElevationMaxDuration() time.Duration
+	SetElevationMaxDuration(maxDuration time.Duration) error
+
+	EntityTypes() []string
+	SetEntityTypes(entityTypes []string) error
+
 	Handle() string
 	SetHandle(handle string) RoleInterface
 
 	ID() string
 	SetID(id string) RoleInterface
 
+	MaxHolders() int
+	SetMaxHolders(maxHolders int) error
+
 	Memo() string
 	SetMemo(memo string) RoleInterface
 
@@ -128,6 +368,9 @@ type RoleInterface interface {
 	UpdatedAt() string
 	UpdatedAtCarbon() carbon.Carbon
 	SetUpdatedAt(updatedAt string) RoleInterface
+
+	Version() int
+	SetVersion(version int) RoleInterface
 }
 
 type EntityRoleInterface interface {
@@ -153,6 +396,12 @@ type EntityRoleInterface interface {
 	EntityID() string
 	SetEntityID(entityID string) EntityRoleInterface
 
+	GrantedByID() string
+	SetGrantedByID(grantedByID string) EntityRoleInterface
+
+	GrantedByType() string
+	SetGrantedByType(grantedByType string) EntityRoleInterface
+
 	ID() string
 	SetID(id string) EntityRoleInterface
 
@@ -164,6 +413,9 @@ type EntityRoleInterface interface {
 	Metas() (map[string]string, error)
 	SetMetas(metas map[string]string) error
 
+	Reason() string
+	SetReason(reason string) EntityRoleInterface
+
 	RoleID() string
 	SetRoleID(roleID string) EntityRoleInterface
 
@@ -171,9 +423,322 @@ type EntityRoleInterface interface {
 	SoftDeletedAtCarbon() carbon.Carbon
 	SetSoftDeletedAt(softDeletedAt string) EntityRoleInterface
 
+	Source() string
+	SetSource(source string) EntityRoleInterface
+
 	UpdatedAt() string
 	UpdatedAtCarbon() carbon.Carbon
 	SetUpdatedAt(updatedAt string) EntityRoleInterface
+
+	Version() int
+	SetVersion(version int) EntityRoleInterface
+}
+
+type SodConstraintInterface interface {
+	// from dataobject
+
+	Data() map[string]string
+	DataChanged() map[string]string
+	MarkAsNotDirty()
+
+	// methods
+
+	IsSoftDeleted() bool
+
+	// setters and getters
+
+	CreatedAt() string
+	CreatedAtCarbon() carbon.Carbon
+	SetCreatedAt(createdAt string) SodConstraintInterface
+
+	ID() string
+	SetID(id string) SodConstraintInterface
+
+	Kind() string
+	SetKind(kind string) SodConstraintInterface
+
+	MaxRoles() int
+	SetMaxRoles(maxRoles int) SodConstraintInterface
+
+	Memo() string
+	SetMemo(memo string) SodConstraintInterface
+
+	RoleIDs() ([]string, error)
+	SetRoleIDs(roleIDs []string) error
+
+	SoftDeletedAt() string
+	SoftDeletedAtCarbon() carbon.Carbon
+	SetSoftDeletedAt(softDeletedAt string) SodConstraintInterface
+
+	Title() string
+	SetTitle(title string) SodConstraintInterface
+
+	UpdatedAt() string
+	UpdatedAtCarbon() carbon.Carbon
+	SetUpdatedAt(updatedAt string) SodConstraintInterface
+}
+
+type SessionInterface interface {
+	// from dataobject
+
+	Data() map[string]string
+	DataChanged() map[string]string
+	MarkAsNotDirty()
+
+	// methods
+
+	IsExpired() bool
+
+	// setters and getters
+
+	ActiveRoleIDs() ([]string, error)
+	SetActiveRoleIDs(roleIDs []string) error
+
+	CreatedAt() string
+	CreatedAtCarbon() carbon.Carbon
+	SetCreatedAt(createdAt string) SessionInterface
+
+	EntityID() string
+	SetEntityID(entityID string) SessionInterface
+
+	EntityType() string
+	SetEntityType(entityType string) SessionInterface
+
+	ExpiresAt() string
+	ExpiresAtCarbon() carbon.Carbon
+	SetExpiresAt(expiresAt string) SessionInterface
+
+	ID() string
+	SetID(id string) SessionInterface
+
+	UpdatedAt() string
+	UpdatedAtCarbon() carbon.Carbon
+	SetUpdatedAt(updatedAt string) SessionInterface
+}
+
+type RoleRequestInterface interface {
+	// from dataobject
+
+	Data() map[string]string
+	DataChanged() map[string]string
+	MarkAsNotDirty()
+
+	// methods
+
+	IsExpired() bool
+	IsPending() bool
+
+	// setters and getters
+
+	CreatedAt() string
+	CreatedAtCarbon() carbon.Carbon
+	SetCreatedAt(createdAt string) RoleRequestInterface
+
+	DecidedAt() string
+	DecidedAtCarbon() carbon.Carbon
+	SetDecidedAt(decidedAt string) RoleRequestInterface
+
+	DecidedByID() string
+	SetDecidedByID(decidedByID string) RoleRequestInterface
+
+	DecidedByType() string
+	SetDecidedByType(decidedByType string) RoleRequestInterface
+
+	DecisionNote() string
+	SetDecisionNote(decisionNote string) RoleRequestInterface
+
+	EntityID() string
+	SetEntityID(entityID string) RoleRequestInterface
+
+	EntityRoleID() string
+	SetEntityRoleID(entityRoleID string) RoleRequestInterface
+
+	EntityType() string
+	SetEntityType(entityType string) RoleRequestInterface
+
+	ExpiresAt() string
+	ExpiresAtCarbon() carbon.Carbon
+	SetExpiresAt(expiresAt string) RoleRequestInterface
+
+	ID() string
+	SetID(id string) RoleRequestInterface
+
+	Justification() string
+	SetJustification(justification string) RoleRequestInterface
+
+	RoleID() string
+	SetRoleID(roleID string) RoleRequestInterface
+
+	Status() string
+	SetStatus(status string) RoleRequestInterface
+
+	UpdatedAt() string
+	UpdatedAtCarbon() carbon.Carbon
+	SetUpdatedAt(updatedAt string) RoleRequestInterface
+}
+
+type ReviewCampaignInterface interface {
+	// from dataobject
+
+	Data() map[string]string
+	DataChanged() map[string]string
+	MarkAsNotDirty()
+
+	// methods
+
+	IsClosed() bool
+	IsOpen() bool
+
+	// setters and getters
+
+	ClosedAt() string
+	ClosedAtCarbon() carbon.Carbon
+	SetClosedAt(closedAt string) ReviewCampaignInterface
+
+	CreatedAt() string
+	CreatedAtCarbon() carbon.Carbon
+	SetCreatedAt(createdAt string) ReviewCampaignInterface
+
+	DueAt() string
+	DueAtCarbon() carbon.Carbon
+	SetDueAt(dueAt string) ReviewCampaignInterface
+
+	ID() string
+	SetID(id string) ReviewCampaignInterface
+
+	Memo() string
+	SetMemo(memo string) ReviewCampaignInterface
+
+	RoleIDs() ([]string, error)
+	SetRoleIDs(roleIDs []string) error
+
+	Status() string
+	SetStatus(status string) ReviewCampaignInterface
+
+	Title() string
+	SetTitle(title string) ReviewCampaignInterface
+
+	UpdatedAt() string
+	UpdatedAtCarbon() carbon.Carbon
+	SetUpdatedAt(updatedAt string) ReviewCampaignInterface
+}
+
+type ReviewItemInterface interface {
+	// from dataobject
+
+	Data() map[string]string
+	DataChanged() map[string]string
+	MarkAsNotDirty()
+
+	// methods
+
+	IsPending() bool
+	Reviewer() EntityRef
+
+	// setters and getters
+
+	CampaignID() string
+	SetCampaignID(campaignID string) ReviewItemInterface
+
+	CreatedAt() string
+	CreatedAtCarbon() carbon.Carbon
+	SetCreatedAt(createdAt string) ReviewItemInterface
+
+	DecidedAt() string
+	DecidedAtCarbon() carbon.Carbon
+	SetDecidedAt(decidedAt string) ReviewItemInterface
+
+	Decision() string
+	SetDecision(decision string) ReviewItemInterface
+
+	DecisionNote() string
+	SetDecisionNote(decisionNote string) ReviewItemInterface
+
+	EntityID() string
+	SetEntityID(entityID string) ReviewItemInterface
+
+	EntityRoleID() string
+	SetEntityRoleID(entityRoleID string) ReviewItemInterface
+
+	EntityType() string
+	SetEntityType(entityType string) ReviewItemInterface
+
+	ID() string
+	SetID(id string) ReviewItemInterface
+
+	ReviewerID() string
+	SetReviewerID(reviewerID string) ReviewItemInterface
+
+	ReviewerType() string
+	SetReviewerType(reviewerType string) ReviewItemInterface
+
+	RoleID() string
+	SetRoleID(roleID string) ReviewItemInterface
+
+	UpdatedAt() string
+	UpdatedAtCarbon() carbon.Carbon
+	SetUpdatedAt(updatedAt string) ReviewItemInterface
+}
+
+type DelegationInterface interface {
+	// from dataobject
+
+	Data() map[string]string
+	DataChanged() map[string]string
+	MarkAsNotDirty()
+
+	// methods
+
+	Delegate() EntityRef
+	Delegator() EntityRef
+	IsActive() bool
+	IsSoftDeleted() bool
+
+	// setters and getters
+
+	CreatedAt() string
+	CreatedAtCarbon() carbon.Carbon
+	SetCreatedAt(createdAt string) DelegationInterface
+
+	DelegateID() string
+	SetDelegateID(delegateID string) DelegationInterface
+
+	DelegateType() string
+	SetDelegateType(delegateType string) DelegationInterface
+
+	DelegatorID() string
+	SetDelegatorID(delegatorID string) DelegationInterface
+
+	DelegatorType() string
+	SetDelegatorType(delegatorType string) DelegationInterface
+
+	EndsAt() string
+	EndsAtCarbon() carbon.Carbon
+	SetEndsAt(endsAt string) DelegationInterface
+
+	ID() string
+	SetID(id string) DelegationInterface
+
+	Memo() string
+	SetMemo(memo string) DelegationInterface
+
+	NoRedelegation() bool
+	SetNoRedelegation(noRedelegation bool) DelegationInterface
+
+	RoleID() string
+	SetRoleID(roleID string) DelegationInterface
+
+	SoftDeletedAt() string
+	SoftDeletedAtCarbon() carbon.Carbon
+	SetSoftDeletedAt(softDeletedAt string) DelegationInterface
+
+	StartsAt() string
+	StartsAtCarbon() carbon.Carbon
+	SetStartsAt(startsAt string) DelegationInterface
+
+	UpdatedAt() string
+	UpdatedAtCarbon() carbon.Carbon
+	SetUpdatedAt(updatedAt string) DelegationInterface
 }
 
 type UserInterface interface {
@@ -0,0 +1,41 @@
+package rolestore
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/gouniverse/sb"
+)
+
+// metaExpression returns a dialect specific expression, which extracts
+// the value of the meta with the given key from the JSON metas column.
+// The expression is NULL, if the meta does not exist
+func (store *store) metaExpression(column exp.IdentifierExpression, key string) exp.LiteralExpression {
+	path := `$."` + key + `"`
+
+	switch store.dbDriverName {
+	case sb.DIALECT_SQLITE:
+		return goqu.L("json_extract(?, ?)", column, path)
+	case sb.DIALECT_MYSQL:
+		return goqu.L("JSON_UNQUOTE(JSON_EXTRACT(?, ?))", column, path)
+	case sb.DIALECT_POSTGRES:
+		return goqu.L("(CAST(? AS jsonb) ->> ?)", column, key)
+	}
+
+	return goqu.L("JSON_VALUE(?, ?)", column, path)
+}
+
+// validateMetaKey checks the meta key can be safely used in a JSON path
+func validateMetaKey(key string) error {
+	if key == "" {
+		return errors.New("meta key cannot be empty")
+	}
+
+	if strings.ContainsAny(key, `"\`) {
+		return errors.New("meta key cannot contain quotes or backslashes: " + key)
+	}
+
+	return nil
+}
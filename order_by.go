@@ -0,0 +1,106 @@
+package rolestore
+
+import (
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+)
+
+// OrderBy is a sort key of a query, i.e. OrderBy{COLUMN_TITLE, ASC}
+type OrderBy struct {
+	// Column is the column to sort by, one of the COLUMN_* constants
+	Column string
+
+	// Direction is the sort direction, ASC or DESC (default)
+	Direction string
+}
+
+// IsAsc returns whether the sort direction is ascending
+func (o OrderBy) IsAsc() bool {
+	return strings.EqualFold(o.Direction, ASC)
+}
+
+// roleColumns are the columns of the role table, which can be sorted by
+var roleColumns = []string{
+	COLUMN_ID,
+	COLUMN_STATUS,
+	COLUMN_HANDLE,
+	COLUMN_TITLE,
+	COLUMN_METAS,
+	COLUMN_MEMO,
+	COLUMN_VERSION,
+	COLUMN_CREATED_AT,
+	COLUMN_UPDATED_AT,
+	COLUMN_SOFT_DELETED_AT,
+}
+
+// entityRoleColumns are the columns of the entity role table, which can be sorted by
+var entityRoleColumns = []string{
+	COLUMN_ID,
+	COLUMN_ENTITY_TYPE,
+	COLUMN_ENTITY_ID,
+	COLUMN_ROLE_ID,
+	COLUMN_METAS,
+	COLUMN_MEMO,
+	COLUMN_GRANTED_BY_TYPE,
+	COLUMN_GRANTED_BY_ID,
+	COLUMN_REASON,
+	COLUMN_SOURCE,
+	COLUMN_VERSION,
+	COLUMN_CREATED_AT,
+	COLUMN_UPDATED_AT,
+	COLUMN_SOFT_DELETED_AT,
+}
+
+// roleRequestColumns are the columns of the role request table, which can be sorted by
+var roleRequestColumns = []string{
+	COLUMN_ID,
+	COLUMN_STATUS,
+	COLUMN_ENTITY_TYPE,
+	COLUMN_ENTITY_ID,
+	COLUMN_ROLE_ID,
+	COLUMN_DECIDED_AT,
+	COLUMN_EXPIRES_AT,
+	COLUMN_CREATED_AT,
+	COLUMN_UPDATED_AT,
+}
+
+// isSortDirection returns whether the value is a valid sort direction
+func isSortDirection(direction string) bool {
+	return strings.EqualFold(direction, ASC) || strings.EqualFold(direction, DESC)
+}
+
+// queryOrderBys returns the sort keys of a query, either the multiple sort keys,
+// or the single order by column with its sort direction (defaults to DESC)
+func queryOrderBys(orderBys []OrderBy, orderBy string, sortDirection string) []OrderBy {
+	if len(orderBys) > 0 {
+		return orderBys
+	}
+
+	if orderBy == "" {
+		return []OrderBy{}
+	}
+
+	if sortDirection == "" {
+		sortDirection = DESC
+	}
+
+	return []OrderBy{{Column: orderBy, Direction: sortDirection}}
+}
+
+// orderExpressions returns the goqu order expressions for the sort keys,
+// with the columns qualified by the table name
+func orderExpressions(orderBys []OrderBy, table string) []exp.OrderedExpression {
+	order := make([]exp.OrderedExpression, 0, len(orderBys))
+
+	for _, orderBy := range orderBys {
+		if orderBy.IsAsc() {
+			order = append(order, goqu.T(table).Col(orderBy.Column).Asc())
+		} else {
+			order = append(order, goqu.T(table).Col(orderBy.Column).Desc())
+		}
+	}
+
+	return order
+}
@@ -0,0 +1,46 @@
+package rolestore
+
+// PagedResult is a page of an offset paginated list, together with
+// the total number of items matching the query
+type PagedResult[T any] struct {
+	// Items are the items on the page
+	Items []T
+
+	// Total is the total number of items matching the query, on all pages
+	Total int64
+
+	// Page is the current page number, starting from 1
+	Page int
+
+	// PerPage is the maximum number of items per page
+	PerPage int
+
+	// TotalPages is the total number of pages
+	TotalPages int
+}
+
+// newPagedResult creates a paged result from the items, the total count
+// and the offset and limit used by the query (limit 0 means no limit)
+func newPagedResult[T any](items []T, total int64, offset int, limit int) PagedResult[T] {
+	result := PagedResult[T]{
+		Items:   items,
+		Total:   total,
+		Page:    1,
+		PerPage: limit,
+	}
+
+	if limit < 1 {
+		result.PerPage = int(total)
+
+		if total > 0 {
+			result.TotalPages = 1
+		}
+
+		return result
+	}
+
+	result.Page = offset/limit + 1
+	result.TotalPages = int((total + int64(limit) - 1) / int64(limit))
+
+	return result
+}
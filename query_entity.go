@@ -1,10 +1,18 @@
 package rolestore
 
-import "errors"
+import (
+	"errors"
+	"maps"
+	"slices"
+)
 
 type EntityRoleQueryInterface interface {
 	Validate() error
 
+	// Clone returns a copy of the query, which can be modified
+	// without affecting the original query
+	Clone() EntityRoleQueryInterface
+
 	Columns() []string
 	SetColumns(columns []string) EntityRoleQueryInterface
 
@@ -20,14 +28,43 @@ type EntityRoleQueryInterface interface {
 	CreatedAtLte() string
 	SetCreatedAtLte(createdAtLte string) EntityRoleQueryInterface
 
+	HasCursor() bool
+	Cursor() string
+	SetCursor(cursor string) EntityRoleQueryInterface
+
 	HasEntityID() bool
 	EntityID() string
 	SetEntityID(entityID string) EntityRoleQueryInterface
 
+	HasEntityIDIn() bool
+	EntityIDIn() []string
+	SetEntityIDIn(entityIDIn []string) EntityRoleQueryInterface
+
+	HasEntityIDNotIn() bool
+	EntityIDNotIn() []string
+	SetEntityIDNotIn(entityIDNotIn []string) EntityRoleQueryInterface
+
 	HasEntityType() bool
 	EntityType() string
 	SetEntityType(entityType string) EntityRoleQueryInterface
 
+	HasEntityTypeIn() bool
+	EntityTypeIn() []string
+	SetEntityTypeIn(entityTypeIn []string) EntityRoleQueryInterface
+
+	HasEntityTypeNotIn() bool
+	EntityTypeNotIn() []string
+	SetEntityTypeNotIn(entityTypeNotIn []string) EntityRoleQueryInterface
+
+	// GrantedByID and GrantedByType filter by the entity, which granted the role
+	HasGrantedByID() bool
+	GrantedByID() string
+	SetGrantedByID(grantedByID string) EntityRoleQueryInterface
+
+	HasGrantedByType() bool
+	GrantedByType() string
+	SetGrantedByType(grantedByType string) EntityRoleQueryInterface
+
 	HasID() bool
 	ID() string
 	SetID(id string) EntityRoleQueryInterface
@@ -40,6 +77,18 @@ type EntityRoleQueryInterface interface {
 	Limit() int
 	SetLimit(limit int) EntityRoleQueryInterface
 
+	// MetaEquals filters by metas having the given values.
+	// Each call to SetMetaEquals adds a condition for one meta key
+	HasMetaEquals() bool
+	MetaEquals() map[string]string
+	SetMetaEquals(key string, value string) EntityRoleQueryInterface
+
+	// MetaExists filters by metas, which exist.
+	// Each call to SetMetaExists adds a condition for one meta key
+	HasMetaExists() bool
+	MetaExists() []string
+	SetMetaExists(key string) EntityRoleQueryInterface
+
 	HasOffset() bool
 	Offset() int
 	SetOffset(offset int) EntityRoleQueryInterface
@@ -48,18 +97,67 @@ type EntityRoleQueryInterface interface {
 	OrderBy() string
 	SetOrderBy(orderBy string) EntityRoleQueryInterface
 
+	// OrderBys are multiple sort keys, each with its own direction.
+	// Cannot be used together with OrderBy
+	HasOrderBys() bool
+	OrderBys() []OrderBy
+	SetOrderBys(orderBys []OrderBy) EntityRoleQueryInterface
+
+	// RoleHandle filters by the handle of the role (joins the role table)
+	HasRoleHandle() bool
+	RoleHandle() string
+	SetRoleHandle(roleHandle string) EntityRoleQueryInterface
+
 	HasRoleID() bool
 	RoleID() string
 	SetRoleID(roleID string) EntityRoleQueryInterface
 
+	HasRoleIDIn() bool
+	RoleIDIn() []string
+	SetRoleIDIn(roleIDIn []string) EntityRoleQueryInterface
+
+	HasRoleIDNotIn() bool
+	RoleIDNotIn() []string
+	SetRoleIDNotIn(roleIDNotIn []string) EntityRoleQueryInterface
+
+	// RoleStatus filters by the status of the role (joins the role table)
+	HasRoleStatus() bool
+	RoleStatus() string
+	SetRoleStatus(roleStatus string) EntityRoleQueryInterface
+
 	HasSortDirection() bool
 	SortDirection() string
 	SetSortDirection(sortDirection string) EntityRoleQueryInterface
 
+	// Source filters by the source of the assignment, one of the ENTITY_ROLE_SOURCE_* constants
+	HasSource() bool
+	Source() string
+	SetSource(source string) EntityRoleQueryInterface
+
+	HasSourceIn() bool
+	SourceIn() []string
+	SetSourceIn(sourceIn []string) EntityRoleQueryInterface
+
 	HasSoftDeletedIncluded() bool
 	SoftDeletedIncluded() bool
 	SetSoftDeletedIncluded(softDeletedIncluded bool) EntityRoleQueryInterface
 
+	HasSoftDeletedAtGte() bool
+	SoftDeletedAtGte() string
+	SetSoftDeletedAtGte(softDeletedAtGte string) EntityRoleQueryInterface
+
+	HasSoftDeletedAtLte() bool
+	SoftDeletedAtLte() string
+	SetSoftDeletedAtLte(softDeletedAtLte string) EntityRoleQueryInterface
+
+	HasUpdatedAtGte() bool
+	UpdatedAtGte() string
+	SetUpdatedAtGte(updatedAtGte string) EntityRoleQueryInterface
+
+	HasUpdatedAtLte() bool
+	UpdatedAtLte() string
+	SetUpdatedAtLte(updatedAtLte string) EntityRoleQueryInterface
+
 	hasProperty(name string) bool
 }
 
@@ -90,6 +188,22 @@ func (c *roleEntityQueryImplementation) Validate() error {
 		return errors.New("role query. entity_type cannot be empty")
 	}
 
+	if c.HasEntityIDIn() && len(c.EntityIDIn()) == 0 {
+		return errors.New("role query. entity_id_in cannot be empty")
+	}
+
+	if c.HasEntityIDNotIn() && len(c.EntityIDNotIn()) == 0 {
+		return errors.New("role query. entity_id_not_in cannot be empty")
+	}
+
+	if c.HasEntityTypeIn() && len(c.EntityTypeIn()) == 0 {
+		return errors.New("role query. entity_type_in cannot be empty")
+	}
+
+	if c.HasEntityTypeNotIn() && len(c.EntityTypeNotIn()) == 0 {
+		return errors.New("role query. entity_type_not_in cannot be empty")
+	}
+
 	if c.HasID() && c.ID() == "" {
 		return errors.New("role query. id cannot be empty")
 	}
@@ -98,6 +212,54 @@ func (c *roleEntityQueryImplementation) Validate() error {
 		return errors.New("role query. id_in cannot be empty")
 	}
 
+	if c.HasGrantedByID() && c.GrantedByID() == "" {
+		return errors.New("role query. granted_by_id cannot be empty")
+	}
+
+	if c.HasGrantedByType() && c.GrantedByType() == "" {
+		return errors.New("role query. granted_by_type cannot be empty")
+	}
+
+	if c.HasSource() && c.Source() == "" {
+		return errors.New("role query. source cannot be empty")
+	}
+
+	if c.HasSourceIn() && len(c.SourceIn()) == 0 {
+		return errors.New("role query. source_in cannot be empty")
+	}
+
+	if c.HasRoleHandle() && c.RoleHandle() == "" {
+		return errors.New("role query. role_handle cannot be empty")
+	}
+
+	if c.HasRoleStatus() && c.RoleStatus() == "" {
+		return errors.New("role query. role_status cannot be empty")
+	}
+
+	if c.HasRoleIDIn() && len(c.RoleIDIn()) == 0 {
+		return errors.New("role query. role_id_in cannot be empty")
+	}
+
+	if c.HasRoleIDNotIn() && len(c.RoleIDNotIn()) == 0 {
+		return errors.New("role query. role_id_not_in cannot be empty")
+	}
+
+	if c.HasSoftDeletedAtGte() && c.SoftDeletedAtGte() == "" {
+		return errors.New("role query. soft_deleted_at_gte cannot be empty")
+	}
+
+	if c.HasSoftDeletedAtLte() && c.SoftDeletedAtLte() == "" {
+		return errors.New("role query. soft_deleted_at_lte cannot be empty")
+	}
+
+	if c.HasUpdatedAtGte() && c.UpdatedAtGte() == "" {
+		return errors.New("role query. updated_at_gte cannot be empty")
+	}
+
+	if c.HasUpdatedAtLte() && c.UpdatedAtLte() == "" {
+		return errors.New("role query. updated_at_lte cannot be empty")
+	}
+
 	if c.HasOrderBy() && c.OrderBy() == "" {
 		return errors.New("role query. order_by cannot be empty")
 	}
@@ -106,6 +268,32 @@ func (c *roleEntityQueryImplementation) Validate() error {
 		return errors.New("role query. sort_direction cannot be empty")
 	}
 
+	if c.HasOrderBy() && !slices.Contains(entityRoleColumns, c.OrderBy()) {
+		return errors.New("role query. order_by is not a valid column: " + c.OrderBy())
+	}
+
+	if c.HasSortDirection() && !isSortDirection(c.SortDirection()) {
+		return errors.New("role query. sort_direction must be asc or desc")
+	}
+
+	if c.HasOrderBys() && len(c.OrderBys()) == 0 {
+		return errors.New("role query. order_bys cannot be empty")
+	}
+
+	if c.HasOrderBys() && c.HasOrderBy() {
+		return errors.New("role query. order_by and order_bys cannot be used together")
+	}
+
+	for _, orderBy := range c.OrderBys() {
+		if !slices.Contains(entityRoleColumns, orderBy.Column) {
+			return errors.New("role query. order_bys column is not a valid column: " + orderBy.Column)
+		}
+
+		if orderBy.Direction != "" && !isSortDirection(orderBy.Direction) {
+			return errors.New("role query. order_bys direction must be asc or desc")
+		}
+	}
+
 	if c.HasLimit() && c.Limit() <= 0 {
 		return errors.New("role query. limit must be greater than 0")
 	}
@@ -114,9 +302,35 @@ func (c *roleEntityQueryImplementation) Validate() error {
 		return errors.New("role query. offset must be greater than or equal to 0")
 	}
 
+	for key := range c.MetaEquals() {
+		if err := validateMetaKey(key); err != nil {
+			return errors.New("role query. meta_equals " + err.Error())
+		}
+	}
+
+	for _, key := range c.MetaExists() {
+		if err := validateMetaKey(key); err != nil {
+			return errors.New("role query. meta_exists " + err.Error())
+		}
+	}
+
+	if c.HasCursor() && c.Cursor() == "" {
+		return errors.New("role query. cursor cannot be empty")
+	}
+
+	if c.HasCursor() && c.HasOffset() {
+		return errors.New("role query. cursor and offset cannot be used together")
+	}
+
 	return nil
 }
 
+func (c *roleEntityQueryImplementation) Clone() EntityRoleQueryInterface {
+	return &roleEntityQueryImplementation{
+		properties: maps.Clone(c.properties),
+	}
+}
+
 func (c *roleEntityQueryImplementation) Columns() []string {
 	if !c.hasProperty("columns") {
 		return []string{}
@@ -185,6 +399,24 @@ func (c *roleEntityQueryImplementation) SetCreatedAtLte(createdAtLte string) Ent
 	return c
 }
 
+func (c *roleEntityQueryImplementation) HasCursor() bool {
+	return c.hasProperty("cursor")
+}
+
+func (c *roleEntityQueryImplementation) Cursor() string {
+	if !c.HasCursor() {
+		return ""
+	}
+
+	return c.properties["cursor"].(string)
+}
+
+func (c *roleEntityQueryImplementation) SetCursor(cursor string) EntityRoleQueryInterface {
+	c.properties["cursor"] = cursor
+
+	return c
+}
+
 func (c *roleEntityQueryImplementation) HasEntityType() bool {
 	return c.hasProperty("entity_type")
 }
@@ -203,6 +435,42 @@ func (c *roleEntityQueryImplementation) SetEntityType(entityType string) EntityR
 	return c
 }
 
+func (c *roleEntityQueryImplementation) HasEntityTypeIn() bool {
+	return c.hasProperty("entity_type_in")
+}
+
+func (c *roleEntityQueryImplementation) EntityTypeIn() []string {
+	if !c.HasEntityTypeIn() {
+		return []string{}
+	}
+
+	return c.properties["entity_type_in"].([]string)
+}
+
+func (c *roleEntityQueryImplementation) SetEntityTypeIn(entityTypeIn []string) EntityRoleQueryInterface {
+	c.properties["entity_type_in"] = entityTypeIn
+
+	return c
+}
+
+func (c *roleEntityQueryImplementation) HasEntityTypeNotIn() bool {
+	return c.hasProperty("entity_type_not_in")
+}
+
+func (c *roleEntityQueryImplementation) EntityTypeNotIn() []string {
+	if !c.HasEntityTypeNotIn() {
+		return []string{}
+	}
+
+	return c.properties["entity_type_not_in"].([]string)
+}
+
+func (c *roleEntityQueryImplementation) SetEntityTypeNotIn(entityTypeNotIn []string) EntityRoleQueryInterface {
+	c.properties["entity_type_not_in"] = entityTypeNotIn
+
+	return c
+}
+
 func (c *roleEntityQueryImplementation) HasEntityID() bool {
 	return c.hasProperty("entity_id")
 }
@@ -221,6 +489,114 @@ func (c *roleEntityQueryImplementation) SetEntityID(entityID string) EntityRoleQ
 	return c
 }
 
+func (c *roleEntityQueryImplementation) HasEntityIDIn() bool {
+	return c.hasProperty("entity_id_in")
+}
+
+func (c *roleEntityQueryImplementation) EntityIDIn() []string {
+	if !c.HasEntityIDIn() {
+		return []string{}
+	}
+
+	return c.properties["entity_id_in"].([]string)
+}
+
+func (c *roleEntityQueryImplementation) SetEntityIDIn(entityIDIn []string) EntityRoleQueryInterface {
+	c.properties["entity_id_in"] = entityIDIn
+
+	return c
+}
+
+func (c *roleEntityQueryImplementation) HasEntityIDNotIn() bool {
+	return c.hasProperty("entity_id_not_in")
+}
+
+func (c *roleEntityQueryImplementation) EntityIDNotIn() []string {
+	if !c.HasEntityIDNotIn() {
+		return []string{}
+	}
+
+	return c.properties["entity_id_not_in"].([]string)
+}
+
+func (c *roleEntityQueryImplementation) SetEntityIDNotIn(entityIDNotIn []string) EntityRoleQueryInterface {
+	c.properties["entity_id_not_in"] = entityIDNotIn
+
+	return c
+}
+
+func (c *roleEntityQueryImplementation) HasGrantedByID() bool {
+	return c.hasProperty("granted_by_id")
+}
+
+func (c *roleEntityQueryImplementation) GrantedByID() string {
+	if !c.HasGrantedByID() {
+		return ""
+	}
+
+	return c.properties["granted_by_id"].(string)
+}
+
+func (c *roleEntityQueryImplementation) SetGrantedByID(grantedByID string) EntityRoleQueryInterface {
+	c.properties["granted_by_id"] = grantedByID
+
+	return c
+}
+
+func (c *roleEntityQueryImplementation) HasGrantedByType() bool {
+	return c.hasProperty("granted_by_type")
+}
+
+func (c *roleEntityQueryImplementation) GrantedByType() string {
+	if !c.HasGrantedByType() {
+		return ""
+	}
+
+	return c.properties["granted_by_type"].(string)
+}
+
+func (c *roleEntityQueryImplementation) SetGrantedByType(grantedByType string) EntityRoleQueryInterface {
+	c.properties["granted_by_type"] = grantedByType
+
+	return c
+}
+
+func (c *roleEntityQueryImplementation) HasSource() bool {
+	return c.hasProperty("source")
+}
+
+func (c *roleEntityQueryImplementation) Source() string {
+	if !c.HasSource() {
+		return ""
+	}
+
+	return c.properties["source"].(string)
+}
+
+func (c *roleEntityQueryImplementation) SetSource(source string) EntityRoleQueryInterface {
+	c.properties["source"] = source
+
+	return c
+}
+
+func (c *roleEntityQueryImplementation) HasSourceIn() bool {
+	return c.hasProperty("source_in")
+}
+
+func (c *roleEntityQueryImplementation) SourceIn() []string {
+	if !c.HasSourceIn() {
+		return []string{}
+	}
+
+	return c.properties["source_in"].([]string)
+}
+
+func (c *roleEntityQueryImplementation) SetSourceIn(sourceIn []string) EntityRoleQueryInterface {
+	c.properties["source_in"] = sourceIn
+
+	return c
+}
+
 func (c *roleEntityQueryImplementation) HasID() bool {
 	return c.hasProperty("id")
 }
@@ -275,6 +651,44 @@ func (c *roleEntityQueryImplementation) SetLimit(limit int) EntityRoleQueryInter
 	return c
 }
 
+func (c *roleEntityQueryImplementation) HasMetaEquals() bool {
+	return c.hasProperty("meta_equals")
+}
+
+func (c *roleEntityQueryImplementation) MetaEquals() map[string]string {
+	if !c.HasMetaEquals() {
+		return map[string]string{}
+	}
+
+	return c.properties["meta_equals"].(map[string]string)
+}
+
+func (c *roleEntityQueryImplementation) SetMetaEquals(key string, value string) EntityRoleQueryInterface {
+	metaEquals := maps.Clone(c.MetaEquals()) // copy, as the map may be shared with a clone
+	metaEquals[key] = value
+	c.properties["meta_equals"] = metaEquals
+
+	return c
+}
+
+func (c *roleEntityQueryImplementation) HasMetaExists() bool {
+	return c.hasProperty("meta_exists")
+}
+
+func (c *roleEntityQueryImplementation) MetaExists() []string {
+	if !c.HasMetaExists() {
+		return []string{}
+	}
+
+	return c.properties["meta_exists"].([]string)
+}
+
+func (c *roleEntityQueryImplementation) SetMetaExists(key string) EntityRoleQueryInterface {
+	c.properties["meta_exists"] = append(slices.Clone(c.MetaExists()), key) // copy, as the slice may be shared with a clone
+
+	return c
+}
+
 func (c *roleEntityQueryImplementation) HasOffset() bool {
 	return c.hasProperty("offset")
 }
@@ -311,6 +725,42 @@ func (c *roleEntityQueryImplementation) SetOrderBy(orderBy string) EntityRoleQue
 	return c
 }
 
+func (c *roleEntityQueryImplementation) HasOrderBys() bool {
+	return c.hasProperty("order_bys")
+}
+
+func (c *roleEntityQueryImplementation) OrderBys() []OrderBy {
+	if !c.HasOrderBys() {
+		return []OrderBy{}
+	}
+
+	return c.properties["order_bys"].([]OrderBy)
+}
+
+func (c *roleEntityQueryImplementation) SetOrderBys(orderBys []OrderBy) EntityRoleQueryInterface {
+	c.properties["order_bys"] = orderBys
+
+	return c
+}
+
+func (c *roleEntityQueryImplementation) HasRoleHandle() bool {
+	return c.hasProperty("role_handle")
+}
+
+func (c *roleEntityQueryImplementation) RoleHandle() string {
+	if !c.HasRoleHandle() {
+		return ""
+	}
+
+	return c.properties["role_handle"].(string)
+}
+
+func (c *roleEntityQueryImplementation) SetRoleHandle(roleHandle string) EntityRoleQueryInterface {
+	c.properties["role_handle"] = roleHandle
+
+	return c
+}
+
 func (c *roleEntityQueryImplementation) HasRoleID() bool {
 	return c.hasProperty("role_id")
 }
@@ -329,6 +779,60 @@ func (c *roleEntityQueryImplementation) SetRoleID(roleID string) EntityRoleQuery
 	return c
 }
 
+func (c *roleEntityQueryImplementation) HasRoleIDIn() bool {
+	return c.hasProperty("role_id_in")
+}
+
+func (c *roleEntityQueryImplementation) RoleIDIn() []string {
+	if !c.HasRoleIDIn() {
+		return []string{}
+	}
+
+	return c.properties["role_id_in"].([]string)
+}
+
+func (c *roleEntityQueryImplementation) SetRoleIDIn(roleIDIn []string) EntityRoleQueryInterface {
+	c.properties["role_id_in"] = roleIDIn
+
+	return c
+}
+
+func (c *roleEntityQueryImplementation) HasRoleIDNotIn() bool {
+	return c.hasProperty("role_id_not_in")
+}
+
+func (c *roleEntityQueryImplementation) RoleIDNotIn() []string {
+	if !c.HasRoleIDNotIn() {
+		return []string{}
+	}
+
+	return c.properties["role_id_not_in"].([]string)
+}
+
+func (c *roleEntityQueryImplementation) SetRoleIDNotIn(roleIDNotIn []string) EntityRoleQueryInterface {
+	c.properties["role_id_not_in"] = roleIDNotIn
+
+	return c
+}
+
+func (c *roleEntityQueryImplementation) HasRoleStatus() bool {
+	return c.hasProperty("role_status")
+}
+
+func (c *roleEntityQueryImplementation) RoleStatus() string {
+	if !c.HasRoleStatus() {
+		return ""
+	}
+
+	return c.properties["role_status"].(string)
+}
+
+func (c *roleEntityQueryImplementation) SetRoleStatus(roleStatus string) EntityRoleQueryInterface {
+	c.properties["role_status"] = roleStatus
+
+	return c
+}
+
 func (c *roleEntityQueryImplementation) HasSortDirection() bool {
 	return c.hasProperty("sort_direction")
 }
@@ -365,6 +869,42 @@ func (c *roleEntityQueryImplementation) SetSoftDeletedIncluded(softDeletedInclud
 	return c
 }
 
+func (c *roleEntityQueryImplementation) HasSoftDeletedAtGte() bool {
+	return c.hasProperty("soft_deleted_at_gte")
+}
+
+func (c *roleEntityQueryImplementation) SoftDeletedAtGte() string {
+	if !c.HasSoftDeletedAtGte() {
+		return ""
+	}
+
+	return c.properties["soft_deleted_at_gte"].(string)
+}
+
+func (c *roleEntityQueryImplementation) SetSoftDeletedAtGte(softDeletedAtGte string) EntityRoleQueryInterface {
+	c.properties["soft_deleted_at_gte"] = softDeletedAtGte
+
+	return c
+}
+
+func (c *roleEntityQueryImplementation) HasSoftDeletedAtLte() bool {
+	return c.hasProperty("soft_deleted_at_lte")
+}
+
+func (c *roleEntityQueryImplementation) SoftDeletedAtLte() string {
+	if !c.HasSoftDeletedAtLte() {
+		return ""
+	}
+
+	return c.properties["soft_deleted_at_lte"].(string)
+}
+
+func (c *roleEntityQueryImplementation) SetSoftDeletedAtLte(softDeletedAtLte string) EntityRoleQueryInterface {
+	c.properties["soft_deleted_at_lte"] = softDeletedAtLte
+
+	return c
+}
+
 func (c *roleEntityQueryImplementation) HasTitleLike() bool {
 	return c.hasProperty("title_like")
 }
@@ -383,6 +923,42 @@ func (c *roleEntityQueryImplementation) SetTitleLike(titleLike string) EntityRol
 	return c
 }
 
+func (c *roleEntityQueryImplementation) HasUpdatedAtGte() bool {
+	return c.hasProperty("updated_at_gte")
+}
+
+func (c *roleEntityQueryImplementation) UpdatedAtGte() string {
+	if !c.HasUpdatedAtGte() {
+		return ""
+	}
+
+	return c.properties["updated_at_gte"].(string)
+}
+
+func (c *roleEntityQueryImplementation) SetUpdatedAtGte(updatedAtGte string) EntityRoleQueryInterface {
+	c.properties["updated_at_gte"] = updatedAtGte
+
+	return c
+}
+
+func (c *roleEntityQueryImplementation) HasUpdatedAtLte() bool {
+	return c.hasProperty("updated_at_lte")
+}
+
+func (c *roleEntityQueryImplementation) UpdatedAtLte() string {
+	if !c.HasUpdatedAtLte() {
+		return ""
+	}
+
+	return c.properties["updated_at_lte"].(string)
+}
+
+func (c *roleEntityQueryImplementation) SetUpdatedAtLte(updatedAtLte string) EntityRoleQueryInterface {
+	c.properties["updated_at_lte"] = updatedAtLte
+
+	return c
+}
+
 func (c *roleEntityQueryImplementation) hasProperty(name string) bool {
 	_, ok := c.properties[name]
 	return ok
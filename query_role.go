@@ -1,10 +1,19 @@
 package rolestore
 
-import "errors"
+import (
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+)
 
 type RoleQueryInterface interface {
 	Validate() error
 
+	// Clone returns a copy of the query, which can be modified
+	// without affecting the original query
+	Clone() RoleQueryInterface
+
 	Columns() []string
 	SetColumns(columns []string) RoleQueryInterface
 
@@ -20,10 +29,18 @@ type RoleQueryInterface interface {
 	CreatedAtLte() string
 	SetCreatedAtLte(createdAtLte string) RoleQueryInterface
 
+	HasCursor() bool
+	Cursor() string
+	SetCursor(cursor string) RoleQueryInterface
+
 	HasHandle() bool
 	Handle() string
 	SetHandle(handle string) RoleQueryInterface
 
+	HasHandleIn() bool
+	HandleIn() []string
+	SetHandleIn(handleIn []string) RoleQueryInterface
+
 	HasID() bool
 	ID() string
 	SetID(id string) RoleQueryInterface
@@ -32,10 +49,26 @@ type RoleQueryInterface interface {
 	IDIn() []string
 	SetIDIn(idIn []string) RoleQueryInterface
 
+	HasIDNotIn() bool
+	IDNotIn() []string
+	SetIDNotIn(idNotIn []string) RoleQueryInterface
+
 	HasLimit() bool
 	Limit() int
 	SetLimit(limit int) RoleQueryInterface
 
+	// MetaEquals filters by metas having the given values.
+	// Each call to SetMetaEquals adds a condition for one meta key
+	HasMetaEquals() bool
+	MetaEquals() map[string]string
+	SetMetaEquals(key string, value string) RoleQueryInterface
+
+	// MetaExists filters by metas, which exist.
+	// Each call to SetMetaExists adds a condition for one meta key
+	HasMetaExists() bool
+	MetaExists() []string
+	SetMetaExists(key string) RoleQueryInterface
+
 	HasOffset() bool
 	Offset() int
 	SetOffset(offset int) RoleQueryInterface
@@ -44,6 +77,16 @@ type RoleQueryInterface interface {
 	OrderBy() string
 	SetOrderBy(orderBy string) RoleQueryInterface
 
+	// OrderBys are multiple sort keys, each with its own direction.
+	// Cannot be used together with OrderBy
+	HasOrderBys() bool
+	OrderBys() []OrderBy
+	SetOrderBys(orderBys []OrderBy) RoleQueryInterface
+
+	HasSearch() bool
+	Search() string
+	SetSearch(search string) RoleQueryInterface
+
 	HasSortDirection() bool
 	SortDirection() string
 	SetSortDirection(sortDirection string) RoleQueryInterface
@@ -60,10 +103,22 @@ type RoleQueryInterface interface {
 	StatusIn() []string
 	SetStatusIn(statusIn []string) RoleQueryInterface
 
+	HasStatusNotIn() bool
+	StatusNotIn() []string
+	SetStatusNotIn(statusNotIn []string) RoleQueryInterface
+
 	HasTitleLike() bool
 	TitleLike() string
 	SetTitleLike(titleLike string) RoleQueryInterface
 
+	HasUpdatedAtGte() bool
+	UpdatedAtGte() string
+	SetUpdatedAtGte(updatedAtGte string) RoleQueryInterface
+
+	HasUpdatedAtLte() bool
+	UpdatedAtLte() string
+	SetUpdatedAtLte(updatedAtLte string) RoleQueryInterface
+
 	hasProperty(name string) bool
 }
 
@@ -86,14 +141,38 @@ func (c *roleQueryImplementation) Validate() error {
 		return errors.New("role query. id_in cannot be empty")
 	}
 
+	if c.HasHandleIn() && len(c.HandleIn()) == 0 {
+		return errors.New("role query. handle_in cannot be empty")
+	}
+
+	if c.HasIDNotIn() && len(c.IDNotIn()) == 0 {
+		return errors.New("role query. id_not_in cannot be empty")
+	}
+
 	if c.HasStatus() && c.Status() == "" {
 		return errors.New("role query. status cannot be empty")
 	}
 
+	if c.HasStatusNotIn() && len(c.StatusNotIn()) == 0 {
+		return errors.New("role query. status_not_in cannot be empty")
+	}
+
+	if c.HasSearch() && strings.TrimSpace(c.Search()) == "" {
+		return errors.New("role query. search cannot be empty")
+	}
+
 	if c.HasTitleLike() && c.TitleLike() == "" {
 		return errors.New("role query. title_like cannot be empty")
 	}
 
+	if c.HasUpdatedAtGte() && c.UpdatedAtGte() == "" {
+		return errors.New("role query. updated_at_gte cannot be empty")
+	}
+
+	if c.HasUpdatedAtLte() && c.UpdatedAtLte() == "" {
+		return errors.New("role query. updated_at_lte cannot be empty")
+	}
+
 	if c.HasOrderBy() && c.OrderBy() == "" {
 		return errors.New("role query. order_by cannot be empty")
 	}
@@ -102,6 +181,32 @@ func (c *roleQueryImplementation) Validate() error {
 		return errors.New("role query. sort_direction cannot be empty")
 	}
 
+	if c.HasOrderBy() && !slices.Contains(roleColumns, c.OrderBy()) {
+		return errors.New("role query. order_by is not a valid column: " + c.OrderBy())
+	}
+
+	if c.HasSortDirection() && !isSortDirection(c.SortDirection()) {
+		return errors.New("role query. sort_direction must be asc or desc")
+	}
+
+	if c.HasOrderBys() && len(c.OrderBys()) == 0 {
+		return errors.New("role query. order_bys cannot be empty")
+	}
+
+	if c.HasOrderBys() && c.HasOrderBy() {
+		return errors.New("role query. order_by and order_bys cannot be used together")
+	}
+
+	for _, orderBy := range c.OrderBys() {
+		if !slices.Contains(roleColumns, orderBy.Column) {
+			return errors.New("role query. order_bys column is not a valid column: " + orderBy.Column)
+		}
+
+		if orderBy.Direction != "" && !isSortDirection(orderBy.Direction) {
+			return errors.New("role query. order_bys direction must be asc or desc")
+		}
+	}
+
 	if c.HasLimit() && c.Limit() <= 0 {
 		return errors.New("role query. limit must be greater than 0")
 	}
@@ -110,9 +215,35 @@ func (c *roleQueryImplementation) Validate() error {
 		return errors.New("role query. offset must be greater than or equal to 0")
 	}
 
+	for key := range c.MetaEquals() {
+		if err := validateMetaKey(key); err != nil {
+			return errors.New("role query. meta_equals " + err.Error())
+		}
+	}
+
+	for _, key := range c.MetaExists() {
+		if err := validateMetaKey(key); err != nil {
+			return errors.New("role query. meta_exists " + err.Error())
+		}
+	}
+
+	if c.HasCursor() && c.Cursor() == "" {
+		return errors.New("role query. cursor cannot be empty")
+	}
+
+	if c.HasCursor() && c.HasOffset() {
+		return errors.New("role query. cursor and offset cannot be used together")
+	}
+
 	return nil
 }
 
+func (c *roleQueryImplementation) Clone() RoleQueryInterface {
+	return &roleQueryImplementation{
+		properties: maps.Clone(c.properties),
+	}
+}
+
 func (c *roleQueryImplementation) Columns() []string {
 	if !c.hasProperty("columns") {
 		return []string{}
@@ -181,6 +312,24 @@ func (c *roleQueryImplementation) SetCreatedAtLte(createdAtLte string) RoleQuery
 	return c
 }
 
+func (c *roleQueryImplementation) HasCursor() bool {
+	return c.hasProperty("cursor")
+}
+
+func (c *roleQueryImplementation) Cursor() string {
+	if !c.HasCursor() {
+		return ""
+	}
+
+	return c.properties["cursor"].(string)
+}
+
+func (c *roleQueryImplementation) SetCursor(cursor string) RoleQueryInterface {
+	c.properties["cursor"] = cursor
+
+	return c
+}
+
 func (c *roleQueryImplementation) HasID() bool {
 	return c.hasProperty("id")
 }
@@ -203,6 +352,24 @@ func (c *roleQueryImplementation) SetHandle(handle string) RoleQueryInterface {
 	return c
 }
 
+func (c *roleQueryImplementation) HasHandleIn() bool {
+	return c.hasProperty("handle_in")
+}
+
+func (c *roleQueryImplementation) HandleIn() []string {
+	if !c.HasHandleIn() {
+		return []string{}
+	}
+
+	return c.properties["handle_in"].([]string)
+}
+
+func (c *roleQueryImplementation) SetHandleIn(handleIn []string) RoleQueryInterface {
+	c.properties["handle_in"] = handleIn
+
+	return c
+}
+
 func (c *roleQueryImplementation) ID() string {
 	if !c.HasID() {
 		return ""
@@ -235,6 +402,24 @@ func (c *roleQueryImplementation) SetIDIn(idIn []string) RoleQueryInterface {
 	return c
 }
 
+func (c *roleQueryImplementation) HasIDNotIn() bool {
+	return c.hasProperty("id_not_in")
+}
+
+func (c *roleQueryImplementation) IDNotIn() []string {
+	if !c.HasIDNotIn() {
+		return []string{}
+	}
+
+	return c.properties["id_not_in"].([]string)
+}
+
+func (c *roleQueryImplementation) SetIDNotIn(idNotIn []string) RoleQueryInterface {
+	c.properties["id_not_in"] = idNotIn
+
+	return c
+}
+
 func (c *roleQueryImplementation) HasLimit() bool {
 	return c.hasProperty("limit")
 }
@@ -253,6 +438,44 @@ func (c *roleQueryImplementation) SetLimit(limit int) RoleQueryInterface {
 	return c
 }
 
+func (c *roleQueryImplementation) HasMetaEquals() bool {
+	return c.hasProperty("meta_equals")
+}
+
+func (c *roleQueryImplementation) MetaEquals() map[string]string {
+	if !c.HasMetaEquals() {
+		return map[string]string{}
+	}
+
+	return c.properties["meta_equals"].(map[string]string)
+}
+
+func (c *roleQueryImplementation) SetMetaEquals(key string, value string) RoleQueryInterface {
+	metaEquals := maps.Clone(c.MetaEquals()) // copy, as the map may be shared with a clone
+	metaEquals[key] = value
+	c.properties["meta_equals"] = metaEquals
+
+	return c
+}
+
+func (c *roleQueryImplementation) HasMetaExists() bool {
+	return c.hasProperty("meta_exists")
+}
+
+func (c *roleQueryImplementation) MetaExists() []string {
+	if !c.HasMetaExists() {
+		return []string{}
+	}
+
+	return c.properties["meta_exists"].([]string)
+}
+
+func (c *roleQueryImplementation) SetMetaExists(key string) RoleQueryInterface {
+	c.properties["meta_exists"] = append(slices.Clone(c.MetaExists()), key) // copy, as the slice may be shared with a clone
+
+	return c
+}
+
 func (c *roleQueryImplementation) HasOffset() bool {
 	return c.hasProperty("offset")
 }
@@ -289,6 +512,42 @@ func (c *roleQueryImplementation) SetOrderBy(orderBy string) RoleQueryInterface
 	return c
 }
 
+func (c *roleQueryImplementation) HasOrderBys() bool {
+	return c.hasProperty("order_bys")
+}
+
+func (c *roleQueryImplementation) OrderBys() []OrderBy {
+	if !c.HasOrderBys() {
+		return []OrderBy{}
+	}
+
+	return c.properties["order_bys"].([]OrderBy)
+}
+
+func (c *roleQueryImplementation) SetOrderBys(orderBys []OrderBy) RoleQueryInterface {
+	c.properties["order_bys"] = orderBys
+
+	return c
+}
+
+func (c *roleQueryImplementation) HasSearch() bool {
+	return c.hasProperty("search")
+}
+
+func (c *roleQueryImplementation) Search() string {
+	if !c.HasSearch() {
+		return ""
+	}
+
+	return c.properties["search"].(string)
+}
+
+func (c *roleQueryImplementation) SetSearch(search string) RoleQueryInterface {
+	c.properties["search"] = search
+
+	return c
+}
+
 func (c *roleQueryImplementation) HasSortDirection() bool {
 	return c.hasProperty("sort_direction")
 }
@@ -361,6 +620,24 @@ func (c *roleQueryImplementation) SetStatusIn(statusIn []string) RoleQueryInterf
 	return c
 }
 
+func (c *roleQueryImplementation) HasStatusNotIn() bool {
+	return c.hasProperty("status_not_in")
+}
+
+func (c *roleQueryImplementation) StatusNotIn() []string {
+	if !c.HasStatusNotIn() {
+		return []string{}
+	}
+
+	return c.properties["status_not_in"].([]string)
+}
+
+func (c *roleQueryImplementation) SetStatusNotIn(statusNotIn []string) RoleQueryInterface {
+	c.properties["status_not_in"] = statusNotIn
+
+	return c
+}
+
 func (c *roleQueryImplementation) HasTitleLike() bool {
 	return c.hasProperty("title_like")
 }
@@ -379,6 +656,42 @@ func (c *roleQueryImplementation) SetTitleLike(titleLike string) RoleQueryInterf
 	return c
 }
 
+func (c *roleQueryImplementation) HasUpdatedAtGte() bool {
+	return c.hasProperty("updated_at_gte")
+}
+
+func (c *roleQueryImplementation) UpdatedAtGte() string {
+	if !c.HasUpdatedAtGte() {
+		return ""
+	}
+
+	return c.properties["updated_at_gte"].(string)
+}
+
+func (c *roleQueryImplementation) SetUpdatedAtGte(updatedAtGte string) RoleQueryInterface {
+	c.properties["updated_at_gte"] = updatedAtGte
+
+	return c
+}
+
+func (c *roleQueryImplementation) HasUpdatedAtLte() bool {
+	return c.hasProperty("updated_at_lte")
+}
+
+func (c *roleQueryImplementation) UpdatedAtLte() string {
+	if !c.HasUpdatedAtLte() {
+		return ""
+	}
+
+	return c.properties["updated_at_lte"].(string)
+}
+
+func (c *roleQueryImplementation) SetUpdatedAtLte(updatedAtLte string) RoleQueryInterface {
+	c.properties["updated_at_lte"] = updatedAtLte
+
+	return c
+}
+
 func (c *roleQueryImplementation) hasProperty(name string) bool {
 	_, ok := c.properties[name]
 	return ok
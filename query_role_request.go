@@ -0,0 +1,329 @@
+package rolestore
+
+import (
+	"errors"
+	"maps"
+	"slices"
+)
+
+type RoleRequestQueryInterface interface {
+	Validate() error
+
+	// Clone returns a copy of the query, which can be modified
+	// without affecting the original query
+	Clone() RoleRequestQueryInterface
+
+	HasEntityID() bool
+	EntityID() string
+	SetEntityID(entityID string) RoleRequestQueryInterface
+
+	HasEntityType() bool
+	EntityType() string
+	SetEntityType(entityType string) RoleRequestQueryInterface
+
+	HasID() bool
+	ID() string
+	SetID(id string) RoleRequestQueryInterface
+
+	HasLimit() bool
+	Limit() int
+	SetLimit(limit int) RoleRequestQueryInterface
+
+	HasOffset() bool
+	Offset() int
+	SetOffset(offset int) RoleRequestQueryInterface
+
+	HasOrderBy() bool
+	OrderBy() string
+	SetOrderBy(orderBy string) RoleRequestQueryInterface
+
+	HasRoleID() bool
+	RoleID() string
+	SetRoleID(roleID string) RoleRequestQueryInterface
+
+	HasRoleIDIn() bool
+	RoleIDIn() []string
+	SetRoleIDIn(roleIDIn []string) RoleRequestQueryInterface
+
+	HasSortDirection() bool
+	SortDirection() string
+	SetSortDirection(sortDirection string) RoleRequestQueryInterface
+
+	// Status filters by the status of the request, one of the ROLE_REQUEST_STATUS_* constants
+	HasStatus() bool
+	Status() string
+	SetStatus(status string) RoleRequestQueryInterface
+
+	HasStatusIn() bool
+	StatusIn() []string
+	SetStatusIn(statusIn []string) RoleRequestQueryInterface
+
+	hasProperty(name string) bool
+}
+
+func NewRoleRequestQuery() RoleRequestQueryInterface {
+	return &roleRequestQueryImplementation{
+		properties: make(map[string]any),
+	}
+}
+
+type roleRequestQueryImplementation struct {
+	properties map[string]any
+}
+
+func (c *roleRequestQueryImplementation) Validate() error {
+	if c.HasEntityID() && c.EntityID() == "" {
+		return errors.New("role request query. entity_id cannot be empty")
+	}
+
+	if c.HasEntityType() && c.EntityType() == "" {
+		return errors.New("role request query. entity_type cannot be empty")
+	}
+
+	if c.HasID() && c.ID() == "" {
+		return errors.New("role request query. id cannot be empty")
+	}
+
+	if c.HasRoleID() && c.RoleID() == "" {
+		return errors.New("role request query. role_id cannot be empty")
+	}
+
+	if c.HasRoleIDIn() && len(c.RoleIDIn()) < 1 {
+		return errors.New("role request query. role_id_in cannot be empty")
+	}
+
+	if c.HasStatus() && c.Status() == "" {
+		return errors.New("role request query. status cannot be empty")
+	}
+
+	if c.HasStatusIn() && len(c.StatusIn()) < 1 {
+		return errors.New("role request query. status_in cannot be empty")
+	}
+
+	if c.HasOrderBy() && !slices.Contains(roleRequestColumns, c.OrderBy()) {
+		return errors.New("role request query. order_by is not a valid column: " + c.OrderBy())
+	}
+
+	if c.HasSortDirection() && !isSortDirection(c.SortDirection()) {
+		return errors.New("role request query. sort_direction must be asc or desc")
+	}
+
+	if c.HasLimit() && c.Limit() < 1 {
+		return errors.New("role request query. limit must be greater than 0")
+	}
+
+	if c.HasOffset() && c.Offset() < 0 {
+		return errors.New("role request query. offset must be greater than or equal to 0")
+	}
+
+	return nil
+}
+
+func (c *roleRequestQueryImplementation) Clone() RoleRequestQueryInterface {
+	return &roleRequestQueryImplementation{
+		properties: maps.Clone(c.properties),
+	}
+}
+
+func (c *roleRequestQueryImplementation) HasEntityID() bool {
+	return c.hasProperty("entity_id")
+}
+
+func (c *roleRequestQueryImplementation) EntityID() string {
+	if !c.HasEntityID() {
+		return ""
+	}
+
+	return c.properties["entity_id"].(string)
+}
+
+func (c *roleRequestQueryImplementation) SetEntityID(entityID string) RoleRequestQueryInterface {
+	c.properties["entity_id"] = entityID
+
+	return c
+}
+
+func (c *roleRequestQueryImplementation) HasEntityType() bool {
+	return c.hasProperty("entity_type")
+}
+
+func (c *roleRequestQueryImplementation) EntityType() string {
+	if !c.HasEntityType() {
+		return ""
+	}
+
+	return c.properties["entity_type"].(string)
+}
+
+func (c *roleRequestQueryImplementation) SetEntityType(entityType string) RoleRequestQueryInterface {
+	c.properties["entity_type"] = entityType
+
+	return c
+}
+
+func (c *roleRequestQueryImplementation) HasID() bool {
+	return c.hasProperty("id")
+}
+
+func (c *roleRequestQueryImplementation) ID() string {
+	if !c.HasID() {
+		return ""
+	}
+
+	return c.properties["id"].(string)
+}
+
+func (c *roleRequestQueryImplementation) SetID(id string) RoleRequestQueryInterface {
+	c.properties["id"] = id
+
+	return c
+}
+
+func (c *roleRequestQueryImplementation) HasLimit() bool {
+	return c.hasProperty("limit")
+}
+
+func (c *roleRequestQueryImplementation) Limit() int {
+	if !c.HasLimit() {
+		return 0
+	}
+
+	return c.properties["limit"].(int)
+}
+
+func (c *roleRequestQueryImplementation) SetLimit(limit int) RoleRequestQueryInterface {
+	c.properties["limit"] = limit
+
+	return c
+}
+
+func (c *roleRequestQueryImplementation) HasOffset() bool {
+	return c.hasProperty("offset")
+}
+
+func (c *roleRequestQueryImplementation) Offset() int {
+	if !c.HasOffset() {
+		return 0
+	}
+
+	return c.properties["offset"].(int)
+}
+
+func (c *roleRequestQueryImplementation) SetOffset(offset int) RoleRequestQueryInterface {
+	c.properties["offset"] = offset
+
+	return c
+}
+
+func (c *roleRequestQueryImplementation) HasOrderBy() bool {
+	return c.hasProperty("order_by")
+}
+
+func (c *roleRequestQueryImplementation) OrderBy() string {
+	if !c.HasOrderBy() {
+		return ""
+	}
+
+	return c.properties["order_by"].(string)
+}
+
+func (c *roleRequestQueryImplementation) SetOrderBy(orderBy string) RoleRequestQueryInterface {
+	c.properties["order_by"] = orderBy
+
+	return c
+}
+
+func (c *roleRequestQueryImplementation) HasRoleID() bool {
+	return c.hasProperty("role_id")
+}
+
+func (c *roleRequestQueryImplementation) RoleID() string {
+	if !c.HasRoleID() {
+		return ""
+	}
+
+	return c.properties["role_id"].(string)
+}
+
+func (c *roleRequestQueryImplementation) SetRoleID(roleID string) RoleRequestQueryInterface {
+	c.properties["role_id"] = roleID
+
+	return c
+}
+
+func (c *roleRequestQueryImplementation) HasRoleIDIn() bool {
+	return c.hasProperty("role_id_in")
+}
+
+func (c *roleRequestQueryImplementation) RoleIDIn() []string {
+	if !c.HasRoleIDIn() {
+		return []string{}
+	}
+
+	return c.properties["role_id_in"].([]string)
+}
+
+func (c *roleRequestQueryImplementation) SetRoleIDIn(roleIDIn []string) RoleRequestQueryInterface {
+	c.properties["role_id_in"] = roleIDIn
+
+	return c
+}
+
+func (c *roleRequestQueryImplementation) HasSortDirection() bool {
+	return c.hasProperty("sort_direction")
+}
+
+func (c *roleRequestQueryImplementation) SortDirection() string {
+	if !c.HasSortDirection() {
+		return ""
+	}
+
+	return c.properties["sort_direction"].(string)
+}
+
+func (c *roleRequestQueryImplementation) SetSortDirection(sortDirection string) RoleRequestQueryInterface {
+	c.properties["sort_direction"] = sortDirection
+
+	return c
+}
+
+func (c *roleRequestQueryImplementation) HasStatus() bool {
+	return c.hasProperty("status")
+}
+
+func (c *roleRequestQueryImplementation) Status() string {
+	if !c.HasStatus() {
+		return ""
+	}
+
+	return c.properties["status"].(string)
+}
+
+func (c *roleRequestQueryImplementation) SetStatus(status string) RoleRequestQueryInterface {
+	c.properties["status"] = status
+
+	return c
+}
+
+func (c *roleRequestQueryImplementation) HasStatusIn() bool {
+	return c.hasProperty("status_in")
+}
+
+func (c *roleRequestQueryImplementation) StatusIn() []string {
+	if !c.HasStatusIn() {
+		return []string{}
+	}
+
+	return c.properties["status_in"].([]string)
+}
+
+func (c *roleRequestQueryImplementation) SetStatusIn(statusIn []string) RoleRequestQueryInterface {
+	c.properties["status_in"] = statusIn
+
+	return c
+}
+
+func (c *roleRequestQueryImplementation) hasProperty(name string) bool {
+	_, ok := c.properties[name]
+	return ok
+}
@@ -1,7 +1,10 @@
 package rolestore
 
 import (
+	"strings"
+
 	"github.com/gouniverse/sb"
+	"github.com/samber/lo"
 )
 
 // sqlRoleTableCreate returns a SQL string for creating the role table
@@ -37,6 +40,10 @@ func (st *store) sqlRoleTableCreate() string {
 			Name: COLUMN_MEMO,
 			Type: sb.COLUMN_TYPE_TEXT,
 		}).
+		Column(sb.Column{
+			Name: COLUMN_VERSION,
+			Type: sb.COLUMN_TYPE_INTEGER,
+		}).
 		Column(sb.Column{
 			Name:   COLUMN_CREATED_AT,
 			Type:   sb.COLUMN_TYPE_DATETIME,
@@ -90,6 +97,403 @@ func (st *store) sqlEntityRoleTableCreate() string {
 			Name: COLUMN_MEMO,
 			Type: sb.COLUMN_TYPE_TEXT,
 		}).
+		Column(sb.Column{
+			Name:   COLUMN_GRANTED_BY_TYPE,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 80,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_GRANTED_BY_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_REASON,
+			Type: sb.COLUMN_TYPE_TEXT,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_SOURCE,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_VERSION,
+			Type: sb.COLUMN_TYPE_INTEGER,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_CREATED_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_UPDATED_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_SOFT_DELETED_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		CreateIfNotExists()
+
+	return sql
+}
+
+// sqlSodConstraintTableCreate returns a SQL string for creating the separation of duties constraint table
+func (st *store) sqlSodConstraintTableCreate() string {
+	sql := sb.NewBuilder(sb.DatabaseDriverName(st.db)).
+		Table(st.sodConstraintTableName).
+		Column(sb.Column{
+			Name:       COLUMN_ID,
+			Type:       sb.COLUMN_TYPE_STRING,
+			PrimaryKey: true,
+			Length:     40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_KIND,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_TITLE,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 100,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_ROLE_IDS,
+			Type: sb.COLUMN_TYPE_TEXT,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_MAX_ROLES,
+			Type: sb.COLUMN_TYPE_INTEGER,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_MEMO,
+			Type: sb.COLUMN_TYPE_TEXT,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_CREATED_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_UPDATED_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_SOFT_DELETED_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		CreateIfNotExists()
+
+	return sql
+}
+
+// sqlSessionTableCreate returns a SQL string for creating the role activation session table
+func (st *store) sqlSessionTableCreate() string {
+	sql := sb.NewBuilder(sb.DatabaseDriverName(st.db)).
+		Table(st.sessionTableName).
+		Column(sb.Column{
+			Name:       COLUMN_ID,
+			Type:       sb.COLUMN_TYPE_STRING,
+			PrimaryKey: true,
+			Length:     40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_ENTITY_TYPE,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 80,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_ENTITY_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_ACTIVE_ROLE_IDS,
+			Type: sb.COLUMN_TYPE_TEXT,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_EXPIRES_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_CREATED_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_UPDATED_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		CreateIfNotExists()
+
+	return sql
+}
+
+// sqlRoleRequestTableCreate returns a SQL string for creating the role request table
+func (st *store) sqlRoleRequestTableCreate() string {
+	sql := sb.NewBuilder(sb.DatabaseDriverName(st.db)).
+		Table(st.roleRequestTableName).
+		Column(sb.Column{
+			Name:       COLUMN_ID,
+			Type:       sb.COLUMN_TYPE_STRING,
+			PrimaryKey: true,
+			Length:     40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_STATUS,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_ENTITY_TYPE,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 80,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_ENTITY_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_ROLE_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_JUSTIFICATION,
+			Type: sb.COLUMN_TYPE_TEXT,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_DECIDED_BY_TYPE,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 80,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_DECIDED_BY_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_DECIDED_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_DECISION_NOTE,
+			Type: sb.COLUMN_TYPE_TEXT,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_ENTITY_ROLE_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_EXPIRES_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_CREATED_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_UPDATED_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		CreateIfNotExists()
+
+	return sql
+}
+
+// sqlReviewCampaignTableCreate returns a SQL string for creating the access review campaign table
+func (st *store) sqlReviewCampaignTableCreate() string {
+	sql := sb.NewBuilder(sb.DatabaseDriverName(st.db)).
+		Table(st.reviewCampaignTableName).
+		Column(sb.Column{
+			Name:       COLUMN_ID,
+			Type:       sb.COLUMN_TYPE_STRING,
+			PrimaryKey: true,
+			Length:     40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_STATUS,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_TITLE,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 100,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_ROLE_IDS,
+			Type: sb.COLUMN_TYPE_TEXT,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_MEMO,
+			Type: sb.COLUMN_TYPE_TEXT,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_DUE_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_CLOSED_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_CREATED_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_UPDATED_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		CreateIfNotExists()
+
+	return sql
+}
+
+// sqlReviewItemTableCreate returns a SQL string for creating the access review item table
+func (st *store) sqlReviewItemTableCreate() string {
+	sql := sb.NewBuilder(sb.DatabaseDriverName(st.db)).
+		Table(st.reviewItemTableName).
+		Column(sb.Column{
+			Name:       COLUMN_ID,
+			Type:       sb.COLUMN_TYPE_STRING,
+			PrimaryKey: true,
+			Length:     40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_CAMPAIGN_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_ENTITY_ROLE_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_ENTITY_TYPE,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 80,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_ENTITY_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_ROLE_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_REVIEWER_TYPE,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 80,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_REVIEWER_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_DECISION,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_DECISION_NOTE,
+			Type: sb.COLUMN_TYPE_TEXT,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_DECIDED_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_CREATED_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_UPDATED_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		CreateIfNotExists()
+
+	return sql
+}
+
+// sqlDelegationTableCreate returns a SQL string for creating the role delegation table
+func (st *store) sqlDelegationTableCreate() string {
+	sql := sb.NewBuilder(sb.DatabaseDriverName(st.db)).
+		Table(st.delegationTableName).
+		Column(sb.Column{
+			Name:       COLUMN_ID,
+			Type:       sb.COLUMN_TYPE_STRING,
+			PrimaryKey: true,
+			Length:     40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_DELEGATOR_TYPE,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 80,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_DELEGATOR_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_DELEGATE_TYPE,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 80,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_DELEGATE_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_ROLE_ID,
+			Type:   sb.COLUMN_TYPE_STRING,
+			Length: 40,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_NO_REDELEGATION,
+			Type: sb.COLUMN_TYPE_INTEGER,
+		}).
+		Column(sb.Column{
+			Name: COLUMN_MEMO,
+			Type: sb.COLUMN_TYPE_TEXT,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_STARTS_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
+		Column(sb.Column{
+			Name:   COLUMN_ENDS_AT,
+			Type:   sb.COLUMN_TYPE_DATETIME,
+			Length: 0,
+		}).
 		Column(sb.Column{
 			Name:   COLUMN_CREATED_AT,
 			Type:   sb.COLUMN_TYPE_DATETIME,
@@ -109,3 +513,60 @@ func (st *store) sqlEntityRoleTableCreate() string {
 
 	return sql
 }
+
+// entityRoleLiveUniqueColumns are the columns of the partial unique index
+// on the live mappings, the conflict target of EntityRoleUpsert
+var entityRoleLiveUniqueColumns = []string{COLUMN_ENTITY_TYPE, COLUMN_ENTITY_ID, COLUMN_ROLE_ID}
+
+// entityRoleLiveUniqueIndex returns the name of the partial unique index
+// on the live mappings of the entity role table
+func (st *store) entityRoleLiveUniqueIndex() string {
+	return st.entityRoleTableName + "_live_assignment"
+}
+
+// liveUniqueIndexSupported returns whether the dialect supports partial
+// indexes, and using them as the target of ON CONFLICT
+func (st *store) liveUniqueIndexSupported() bool {
+	return st.dbDriverName == sb.DIALECT_SQLITE || st.dbDriverName == sb.DIALECT_POSTGRES
+}
+
+// sqlEntityRoleLiveUniqueIndexCreate returns a SQL string for creating the
+// partial unique index on the live mappings, those without a soft delete date.
+// For dialects without partial indexes an empty string is returned
+func (st *store) sqlEntityRoleLiveUniqueIndexCreate() string {
+	if !st.liveUniqueIndexSupported() {
+		return ""
+	}
+
+	return `CREATE UNIQUE INDEX IF NOT EXISTS "` + st.entityRoleLiveUniqueIndex() + `" ON "` + st.entityRoleTableName + `"` +
+		` (` + sqlQuoteColumns(entityRoleLiveUniqueColumns) + `) WHERE ` + sqlLivePredicate()
+}
+
+// sqlEntityRoleLiveOnConflict returns the ON CONFLICT clause of an insert
+// into the entity role table, which updates the columns of the conflicting
+// live mapping instead, and increments its version. goqu cannot express
+// the predicate of a partial index as the conflict target
+func (st *store) sqlEntityRoleLiveOnConflict(columns []string) string {
+	set := lo.Map(columns, func(column string, _ int) string {
+		return `"` + column + `" = excluded."` + column + `"`
+	})
+
+	set = append(set, `"`+COLUMN_VERSION+`" = "`+st.entityRoleTableName+`"."`+COLUMN_VERSION+`" + 1`)
+
+	return ` ON CONFLICT (` + sqlQuoteColumns(entityRoleLiveUniqueColumns) + `) WHERE ` + sqlLivePredicate() +
+		` DO UPDATE SET ` + strings.Join(set, ", ")
+}
+
+// sqlLivePredicate returns the condition matching the rows without a soft delete date
+func sqlLivePredicate() string {
+	return `"` + COLUMN_SOFT_DELETED_AT + `" = '` + sb.MAX_DATETIME + `'`
+}
+
+// sqlQuoteColumns returns the columns quoted and comma separated
+func sqlQuoteColumns(columns []string) string {
+	quoted := lo.Map(columns, func(column string, _ int) string {
+		return `"` + column + `"`
+	})
+
+	return strings.Join(quoted, ", ")
+}
@@ -6,7 +6,11 @@ import (
 	"errors"
 	"log/slog"
 
+	"github.com/doug-martin/goqu/v9"
 	"github.com/gouniverse/base/database"
+	"github.com/gouniverse/sb"
+	"github.com/samber/lo"
+	"github.com/spf13/cast"
 )
 
 // == TYPE ====================================================================
@@ -18,6 +22,30 @@ type store struct {
 	// entityRoleTableName is the name of the role entity relation table
 	entityRoleTableName string
 
+	// sodConstraintTableName is the name of the separation of duties constraint table, empty if disabled
+	sodConstraintTableName string
+
+	// sessionTableName is the name of the role activation session table, empty if disabled
+	sessionTableName string
+
+	// roleRequestTableName is the name of the role request table, empty if disabled
+	roleRequestTableName string
+
+	// roleRequestHook is called after a role request changes, nil if not set
+	roleRequestHook RoleRequestHook
+
+	// reviewCampaignTableName is the name of the access review campaign table, empty if disabled
+	reviewCampaignTableName string
+
+	// reviewItemTableName is the name of the access review item table, empty if disabled
+	reviewItemTableName string
+
+	// delegationTableName is the name of the role delegation table, empty if disabled
+	delegationTableName string
+
+	// auditHook is called with the audit entries of privileged changes, nil if not set
+	auditHook AuditHook
+
 	// db is the underlying database connection
 	db *sql.DB
 
@@ -32,6 +60,15 @@ type store struct {
 
 	// sqlLogger is the sql logger used when debug mode is enabled
 	sqlLogger *slog.Logger
+
+	// txIsolationLevel is the default isolation level for transactions started by WithTx
+	txIsolationLevel sql.IsolationLevel
+
+	// userRoleSyncMode is the direction the legacy user role field is synced in
+	userRoleSyncMode string
+
+	// entityTypes is the entity type registry, empty if any entity type is allowed
+	entityTypes map[string]EntityIDValidator
 }
 
 // == INTERFACE ===============================================================
@@ -70,9 +107,276 @@ func (store *store) AutoMigrate() error {
 		return err
 	}
 
+	// tables created before the version column was added
+	for _, table := range []string{store.roleTableName, store.entityRoleTableName} {
+		err := store.migrateColumnAdd(table, sb.Column{Name: COLUMN_VERSION, Type: sb.COLUMN_TYPE_INTEGER}, 1)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	// entity role tables created before the provenance columns were added
+	provenanceColumns := []struct {
+		column sb.Column
+		value  string
+	}{
+		{sb.Column{Name: COLUMN_GRANTED_BY_TYPE, Type: sb.COLUMN_TYPE_STRING, Length: 80}, ""},
+		{sb.Column{Name: COLUMN_GRANTED_BY_ID, Type: sb.COLUMN_TYPE_STRING, Length: 40}, ""},
+		{sb.Column{Name: COLUMN_REASON, Type: sb.COLUMN_TYPE_TEXT}, ""},
+		{sb.Column{Name: COLUMN_SOURCE, Type: sb.COLUMN_TYPE_STRING, Length: 40}, ENTITY_ROLE_SOURCE_MANUAL},
+	}
+
+	for _, provenance := range provenanceColumns {
+		if err := store.migrateColumnAdd(store.entityRoleTableName, provenance.column, provenance.value); err != nil {
+			return err
+		}
+	}
+
+	if err := store.migrateEntityRoleLiveUniqueIndex(); err != nil {
+		return err
+	}
+
+	if store.sodConstraintTableName != "" {
+		sqlStr = store.sqlSodConstraintTableCreate()
+
+		if sqlStr == "" {
+			return errors.New("rolestore: sod constraint table create sql is empty")
+		}
+
+		_, err = store.db.Exec(sqlStr)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if store.sessionTableName != "" {
+		sqlStr = store.sqlSessionTableCreate()
+
+		if sqlStr == "" {
+			return errors.New("rolestore: session table create sql is empty")
+		}
+
+		_, err = store.db.Exec(sqlStr)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if store.roleRequestTableName != "" {
+		sqlStr = store.sqlRoleRequestTableCreate()
+
+		if sqlStr == "" {
+			return errors.New("rolestore: role request table create sql is empty")
+		}
+
+		_, err = store.db.Exec(sqlStr)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if store.reviewCampaignTableName != "" {
+		sqlStr = store.sqlReviewCampaignTableCreate()
+
+		if sqlStr == "" {
+			return errors.New("rolestore: review campaign table create sql is empty")
+		}
+
+		_, err = store.db.Exec(sqlStr)
+
+		if err != nil {
+			return err
+		}
+
+		sqlStr = store.sqlReviewItemTableCreate()
+
+		if sqlStr == "" {
+			return errors.New("rolestore: review item table create sql is empty")
+		}
+
+		_, err = store.db.Exec(sqlStr)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if store.delegationTableName != "" {
+		sqlStr = store.sqlDelegationTableCreate()
+
+		if sqlStr == "" {
+			return errors.New("rolestore: delegation table create sql is empty")
+		}
+
+		_, err = store.db.Exec(sqlStr)
+
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// migrateColumnAdd adds a column missing from a table created by an earlier
+// version of the store, and sets it to the value on the existing rows.
+// The column is added as nullable, as not all the dialects allow adding a
+// not null column to a table with rows, the store always sets the value
+func (store *store) migrateColumnAdd(table string, column sb.Column, value any) error {
+	exists, err := store.columnExists(table, column.Name)
+
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return nil
+	}
+
+	column.Nullable = true
+
+	sqlStr, err := sb.NewBuilder(sb.DatabaseDriverName(store.db)).TableColumnAdd(table, column)
+
+	if err != nil {
+		return err
+	}
+
+	store.logSql("alter", sqlStr)
+
+	if _, err := store.db.Exec(sqlStr); err != nil {
+		return err
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Update(table).
+		Prepared(true).
+		Set(goqu.Record{column.Name: value}).
+		Where(goqu.C(column.Name).IsNull()).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	store.logSql("update", sqlStr, params...)
+
+	_, err = store.db.Exec(sqlStr, params...)
+
+	return err
+}
+
+// migrateEntityRoleLiveUniqueIndex adds the partial unique index on the live
+// mappings, the conflict target of EntityRoleUpsert, where the dialect
+// supports it. Tables of earlier versions may hold duplicate live mappings,
+// which the index would reject. Those are logged as warnings instead, and
+// the index is added by the first AutoMigrate after they are removed.
+// Until then EntityRoleUpsert finds and then writes, as on MySQL
+func (store *store) migrateEntityRoleLiveUniqueIndex() error {
+	sqlStr := store.sqlEntityRoleLiveUniqueIndexCreate()
+
+	if sqlStr == "" {
+		return nil // no partial indexes in the dialect
+	}
+
+	duplicates, err := store.entityRoleLiveDuplicates(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	if len(duplicates) > 0 {
+		for _, duplicate := range duplicates {
+			store.logWarn("rolestore: duplicate live entity role, unique index "+store.entityRoleLiveUniqueIndex()+" not added",
+				slog.String(COLUMN_ENTITY_TYPE, duplicate[COLUMN_ENTITY_TYPE]),
+				slog.String(COLUMN_ENTITY_ID, duplicate[COLUMN_ENTITY_ID]),
+				slog.String(COLUMN_ROLE_ID, duplicate[COLUMN_ROLE_ID]),
+				slog.String("count", duplicate["count"]))
+		}
+
+		return nil
+	}
+
+	store.logSql("create", sqlStr)
+
+	_, err = store.db.Exec(sqlStr)
+
+	return err
+}
+
+// entityRoleLiveDuplicates returns the entity type, entity ID and role ID of
+// the live mappings without a soft delete date, which are stored more than once
+func (store *store) entityRoleLiveDuplicates(ctx context.Context) ([]map[string]string, error) {
+	columns := lo.Map(entityRoleLiveUniqueColumns, func(column string, _ int) any {
+		return goqu.C(column)
+	})
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(store.entityRoleTableName).
+		Prepared(true).
+		Select(append(columns, goqu.COUNT(goqu.Star()).As("count"))...).
+		Where(goqu.C(COLUMN_SOFT_DELETED_AT).Eq(sb.MAX_DATETIME)).
+		GroupBy(columns...).
+		Having(goqu.COUNT(goqu.Star()).Gt(1)).
+		ToSQL()
+
+	if errSql != nil {
+		return nil, errSql
+	}
+
+	return store.selectToMaps(ctx, sqlStr, params...)
+}
+
+// indexExists returns whether the index exists, on the dialects with partial indexes
+func (store *store) indexExists(ctx context.Context, index string) (bool, error) {
+	var sqlStr string
+
+	switch store.dbDriverName {
+	case sb.DIALECT_SQLITE:
+		sqlStr = "SELECT COUNT(*) AS count FROM sqlite_master WHERE type = 'index' AND name = ?"
+	case sb.DIALECT_POSTGRES:
+		sqlStr = "SELECT COUNT(*) AS count FROM pg_indexes WHERE schemaname = current_schema() AND indexname = $1"
+	default:
+		return false, nil
+	}
+
+	rows, err := store.selectToMaps(ctx, sqlStr, index)
+
+	if err != nil {
+		return false, err
+	}
+
+	return len(rows) > 0 && cast.ToInt(rows[0]["count"]) > 0, nil
+}
+
+// columnExists returns whether the table has the column. On dialects,
+// which cannot be inspected, the column is assumed to exist
+func (store *store) columnExists(table string, column string) (bool, error) {
+	var sqlStr string
+
+	switch store.dbDriverName {
+	case sb.DIALECT_SQLITE:
+		sqlStr = "SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?"
+	case sb.DIALECT_MYSQL:
+		sqlStr = "SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ? AND column_name = ?"
+	case sb.DIALECT_POSTGRES:
+		sqlStr = "SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = $1 AND column_name = $2"
+	default:
+		return true, nil
+	}
+
+	count := 0
+
+	if err := store.db.QueryRow(sqlStr, table, column).Scan(&count); err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
 // DB returns the underlying database connection
 func (store *store) DB() *sql.DB {
 	return store.db
@@ -83,6 +387,13 @@ func (st *store) EnableDebug(debug bool) {
 	st.debugEnabled = debug
 }
 
+// logWarn logs a warning to the sql logger, regardless of the debug mode
+func (store *store) logWarn(message string, attrs ...any) {
+	if store.sqlLogger != nil {
+		store.sqlLogger.Warn(message, attrs...)
+	}
+}
+
 // logSql logs sql to the sql logger, if debug mode is enabled
 func (store *store) logSql(sqlOperationType string, sql string, params ...interface{}) {
 	if !store.debugEnabled {
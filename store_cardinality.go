@@ -0,0 +1,160 @@
+package rolestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/sb"
+	"github.com/spf13/cast"
+)
+
+// RoleSeats reports how many of the seats (maximum holders) of a role are used
+type RoleSeats struct {
+	// RoleID is the ID of the role
+	RoleID string
+
+	// MaxHolders is the maximum number of holders, zero if unlimited
+	MaxHolders int
+
+	// Used is the number of live assignments of the role
+	Used int64
+
+	// Available is the number of free seats, -1 if unlimited
+	Available int64
+}
+
+// IsFull returns whether no more entities may be assigned the role
+func (seats RoleSeats) IsFull() bool {
+	return seats.Available == 0
+}
+
+// RoleSeats returns how many seats of the role are used and available,
+// i.e. for showing "2 of 3 seats used"
+func (store *store) RoleSeats(ctx context.Context, roleID string) (RoleSeats, error) {
+	if roleID == "" {
+		return RoleSeats{}, errors.New("rolestore > RoleSeats. role id is empty")
+	}
+
+	role, err := store.RoleFindByID(ctx, roleID)
+
+	if err != nil {
+		return RoleSeats{}, err
+	}
+
+	if role == nil {
+		return RoleSeats{}, errors.New("rolestore > RoleSeats. role not found: " + roleID)
+	}
+
+	used, err := store.EntityRoleCount(ctx, NewEntityRoleQuery().SetRoleID(roleID))
+
+	if err != nil {
+		return RoleSeats{}, err
+	}
+
+	seats := RoleSeats{
+		RoleID:     roleID,
+		MaxHolders: role.MaxHolders(),
+		Used:       used,
+		Available:  -1,
+	}
+
+	if seats.MaxHolders > 0 {
+		seats.Available = max(int64(seats.MaxHolders)-used, 0)
+	}
+
+	return seats, nil
+}
+
+// roleHoldersCheck returns ErrRoleMaxHolders, if the role has a maximum
+// holders limit, which one more assignment would exceed. It must run in the
+// transaction of the assignment, after sodLockForUpdate, so that concurrent
+// assignments of the role are serialized
+func (store *store) roleHoldersCheck(ctx context.Context, roleID string) error {
+	role, err := store.RoleFindByID(ctx, roleID)
+
+	if err != nil {
+		return err
+	}
+
+	if role == nil || role.MaxHolders() <= 0 {
+		return nil
+	}
+
+	used, err := store.roleHoldersCount(ctx, roleID)
+
+	if err != nil {
+		return err
+	}
+
+	if used >= int64(role.MaxHolders()) {
+		return fmt.Errorf("%w: role %s allows %d holders", ErrRoleMaxHolders, role.Handle(), role.MaxHolders())
+	}
+
+	return nil
+}
+
+// roleHoldersCount counts the live assignments of the role. On MySQL the
+// count is a locking read, which sees the latest committed assignments,
+// rather than the snapshot of a REPEATABLE READ transaction
+func (store *store) roleHoldersCount(ctx context.Context, roleID string) (int64, error) {
+	q := goqu.Dialect(store.dbDriverName).
+		From(store.entityRoleTableName).
+		Prepared(true).
+		Select(goqu.COUNT(goqu.Star()).As("count")).
+		Where(goqu.C(COLUMN_ROLE_ID).Eq(roleID)).
+		Where(goqu.C(COLUMN_SOFT_DELETED_AT).Gt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)))
+
+	if store.dbDriverName == sb.DIALECT_MYSQL {
+		q = q.ForShare(exp.Wait)
+	}
+
+	sqlStr, params, errSql := q.ToSQL()
+
+	if errSql != nil {
+		return -1, errSql
+	}
+
+	rows, err := store.selectToMaps(ctx, sqlStr, params...)
+
+	if err != nil {
+		return -1, err
+	}
+
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	return cast.ToInt64(rows[0]["count"]), nil
+}
+
+// roleLockForUpdate locks the role rows until the end of the transaction,
+// in ID order, so that transactions locking overlapping roles do not deadlock.
+// It must be the first statement of the transaction: on MySQL the first
+// read fixes the REPEATABLE READ snapshot, which must not predate the lock.
+// SQLite does not support row locks, but it serializes write transactions
+func (store *store) roleLockForUpdate(ctx context.Context, roleIDs ...string) error {
+	if store.dbDriverName != sb.DIALECT_MYSQL && store.dbDriverName != sb.DIALECT_POSTGRES {
+		return nil
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(store.roleTableName).
+		Prepared(true).
+		Select(goqu.C(COLUMN_ID)).
+		Where(goqu.C(COLUMN_ID).In(roleIDs)).
+		Order(goqu.C(COLUMN_ID).Asc()).
+		ForUpdate(exp.Wait).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	_, err := store.selectToMaps(ctx, sqlStr, params...)
+
+	return err
+}
@@ -0,0 +1,274 @@
+package rolestore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/sb"
+)
+
+func TestStoreRoleMaxHolders(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	role := NewRole().SetStatus(ROLE_STATUS_ACTIVE).SetHandle("billing_contact").SetTitle("Billing Contact")
+
+	if err := role.SetMaxHolders(2); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.RoleCreate(context.Background(), role); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	seats, err := store.RoleSeats(context.Background(), role.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if seats.MaxHolders != 2 || seats.Used != 0 || seats.Available != 2 {
+		t.Fatal("unexpected seats:", seats)
+	}
+
+	for _, entityID := range []string{"USER_01", "USER_02"} {
+		err := store.EntityRoleCreate(context.Background(), NewEntityRole().
+			SetEntityType("user").
+			SetEntityID(entityID).
+			SetRoleID(role.ID()))
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	err = store.EntityRoleCreate(context.Background(), NewEntityRole().
+		SetEntityType("user").
+		SetEntityID("USER_03").
+		SetRoleID(role.ID()))
+
+	if !errors.Is(err, ErrRoleMaxHolders) {
+		t.Fatal("error MUST be ErrRoleMaxHolders, found:", err)
+	}
+
+	seats, err = store.RoleSeats(context.Background(), role.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if seats.Used != 2 || seats.Available != 0 || !seats.IsFull() {
+		t.Fatal("unexpected seats:", seats)
+	}
+
+	// a revoked assignment frees its seat
+	if _, err := store.EntityRevokeAll(context.Background(), "user", "USER_01", false); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.EntityRoleCreate(context.Background(), NewEntityRole().
+		SetEntityType("user").
+		SetEntityID("USER_03").
+		SetRoleID(role.ID()))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+}
+
+func TestStoreRoleMaxHolders_Restore(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	role := NewRole().SetStatus(ROLE_STATUS_ACTIVE).SetHandle("owner").SetTitle("Owner")
+
+	if err := role.SetMaxHolders(1); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.RoleCreate(context.Background(), role); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	previous := NewEntityRole().SetEntityType("user").SetEntityID("USER_01").SetRoleID(role.ID())
+
+	if err := store.EntityRoleCreate(context.Background(), previous); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.EntityRoleSoftDelete(context.Background(), previous); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.EntityRoleCreate(context.Background(), NewEntityRole().
+		SetEntityType("user").
+		SetEntityID("USER_02").
+		SetRoleID(role.ID()))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	previous.SetSoftDeletedAt(sb.MAX_DATETIME)
+
+	err = store.EntityRoleUpdate(context.Background(), previous)
+
+	if !errors.Is(err, ErrRoleMaxHolders) {
+		t.Fatal("restore error MUST be ErrRoleMaxHolders, found:", err)
+	}
+
+	// a live mapping is updated without counting itself
+	current, err := store.EntityRoleFindByEntityAndRole(context.Background(), "user", "USER_02", role.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	current.SetSoftDeletedAt(carbon.Now(carbon.UTC).AddYear().ToDateTimeString(carbon.UTC))
+
+	if err := store.EntityRoleUpdate(context.Background(), current); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+}
+
+func TestStoreRoleSeats_Unlimited(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	role := NewRole().SetStatus(ROLE_STATUS_ACTIVE).SetHandle("member").SetTitle("Member")
+
+	if err := store.RoleCreate(context.Background(), role); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.EntityRoleCreate(context.Background(), NewEntityRole().
+		SetEntityType("user").
+		SetEntityID("USER_01").
+		SetRoleID(role.ID()))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	seats, err := store.RoleSeats(context.Background(), role.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if seats.MaxHolders != 0 || seats.Used != 1 || seats.Available != -1 || seats.IsFull() {
+		t.Fatal("unexpected seats:", seats)
+	}
+}
+
+func TestStoreRoleMaxHolders_Concurrent(t *testing.T) {
+	// a file database, as each connection to :memory: is a database of its own.
+	// Immediate transactions wait for each other, instead of failing as busy
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "concurrent.db")+"?_pragma=busy_timeout(5000)&_txlock=immediate")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		DB:                  db,
+		RoleTableName:       "roles_role_table",
+		EntityRoleTableName: "roles_entity_role_table",
+		AutomigrateEnabled:  true,
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	role := NewRole().SetStatus(ROLE_STATUS_ACTIVE).SetHandle("owner").SetTitle("Owner")
+
+	if err := role.SetMaxHolders(1); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.RoleCreate(context.Background(), role); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	entityIDs := []string{"USER_01", "USER_02"}
+	errs := make([]error, len(entityIDs))
+	wg := sync.WaitGroup{}
+
+	for i, entityID := range entityIDs {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			errs[i] = store.EntityRoleCreate(context.Background(), NewEntityRole().
+				SetEntityType("user").
+				SetEntityID(entityID).
+				SetRoleID(role.ID()))
+		}()
+	}
+
+	wg.Wait()
+
+	succeeded := 0
+
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+		} else if !errors.Is(err, ErrRoleMaxHolders) {
+			t.Fatal("error MUST be ErrRoleMaxHolders, found:", err)
+		}
+	}
+
+	if succeeded != 1 {
+		t.Fatal("exactly one concurrent assignment MUST succeed, succeeded:", succeeded)
+	}
+
+	count, err := store.EntityRoleCount(context.Background(), NewEntityRoleQuery().SetRoleID(role.ID()))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 1 {
+		t.Fatal("unexpected holders count:", count)
+	}
+}
@@ -0,0 +1,522 @@
+package rolestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/base/database"
+	"github.com/samber/lo"
+)
+
+// DelegationListOptions are the options for listing the delegations.
+// Revoked delegations are never listed
+type DelegationListOptions struct {
+	// Delegator optionally restricts the delegations to those of the delegator
+	Delegator EntityRef
+
+	// Delegate optionally restricts the delegations to those to the delegate
+	Delegate EntityRef
+
+	// RoleID optionally restricts the delegations to those of the role
+	RoleID string
+
+	// ActiveOnly restricts the delegations to those, which time window includes now
+	ActiveOnly bool
+}
+
+// DelegationCreate delegates a role from the delegator to the delegate for
+// the time window of the delegation. The delegator must hold the role,
+// directly, or by a delegation allowing redelegation, otherwise
+// ErrDelegationNotAllowed is returned. The end of the delegation is moved
+// back to when the delegator stops holding the role, if earlier. A
+// SodViolationError is returned, if the delegate would hold the roles
+// of a static separation of duties constraint
+func (store *store) DelegationCreate(ctx context.Context, delegation DelegationInterface) error {
+	if err := store.delegationEnabled("DelegationCreate"); err != nil {
+		return err
+	}
+
+	if delegation == nil {
+		return errors.New("rolestore > DelegationCreate. delegation is nil")
+	}
+
+	if delegation.Delegator().IsEmpty() || delegation.Delegate().IsEmpty() {
+		return errors.New("rolestore > DelegationCreate. delegator and delegate entity type and ID are required")
+	}
+
+	if delegation.Delegator() == delegation.Delegate() {
+		return errors.New("rolestore > DelegationCreate. an entity cannot delegate to itself")
+	}
+
+	if delegation.RoleID() == "" {
+		return errors.New("rolestore > DelegationCreate. delegation roleID is empty")
+	}
+
+	if !delegation.EndsAtCarbon().Gt(delegation.StartsAtCarbon()) {
+		return errors.New("rolestore > DelegationCreate. delegation must end after it starts")
+	}
+
+	if !delegation.EndsAtCarbon().Gt(carbon.Now(carbon.UTC)) {
+		return errors.New("rolestore > DelegationCreate. delegation must end in the future")
+	}
+
+	return store.WithTx(ctx, func(txCtx context.Context) error {
+		delegate := delegation.Delegate()
+
+		if err := store.validateEntity(delegate.EntityType, delegate.EntityID); err != nil {
+			return err
+		}
+
+		role, err := store.RoleFindByID(txCtx, delegation.RoleID())
+
+		if err != nil {
+			return err
+		}
+
+		if role == nil || !role.IsActive() {
+			return errors.New("rolestore > DelegationCreate. role not found or not active: " + delegation.RoleID())
+		}
+
+		if !role.AllowsEntityType(delegate.EntityType) {
+			return fmt.Errorf("%w: role %s, entity type %s", ErrEntityTypeNotAllowed, role.Handle(), delegate.EntityType)
+		}
+
+		until, holds, err := store.entityHoldsRoleUntil(txCtx, delegation.Delegator(), delegation.RoleID(), true, map[EntityRef]bool{})
+
+		if err != nil {
+			return err
+		}
+
+		if !holds {
+			return fmt.Errorf("%w: %s %s does not hold role %s, or may not redelegate it", ErrDelegationNotAllowed, delegation.DelegatorType(), delegation.DelegatorID(), role.Handle())
+		}
+
+		// a delegation ends, at the latest, when the delegator stops holding
+		// the role (i.e. its elevation expires), so it cannot outlive it
+		if delegation.EndsAtCarbon().Gt(until) {
+			delegation.SetEndsAt(until.ToDateTimeString(carbon.UTC))
+		}
+
+		if !delegation.EndsAtCarbon().Gt(delegation.StartsAtCarbon()) {
+			return fmt.Errorf("%w: %s %s holds role %s only until %s", ErrDelegationNotAllowed, delegation.DelegatorType(), delegation.DelegatorID(), role.Handle(), until.ToDateTimeString(carbon.UTC))
+		}
+
+		if err := store.sodCheck(txCtx, delegate.EntityType, delegate.EntityID, delegation.RoleID(), ""); err != nil {
+			return err
+		}
+
+		delegation.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+		delegation.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+		sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+			Insert(store.delegationTableName).
+			Prepared(true).
+			Rows(delegation.Data()).
+			ToSQL()
+
+		if errSql != nil {
+			return errSql
+		}
+
+		store.logSql("insert", sqlStr, params...)
+
+		if _, err := database.Execute(store.toQuerableContext(txCtx), sqlStr, params...); err != nil {
+			return err
+		}
+
+		delegation.MarkAsNotDirty()
+
+		return nil
+	})
+}
+
+// entityDelegatedRoleIDs returns the IDs of the roles delegated to the entity
+// by the delegations, which have not ended, including those not yet started,
+// as their time windows will overlap the roles the entity holds
+func (store *store) entityDelegatedRoleIDs(ctx context.Context, entity EntityRef) ([]string, error) {
+	if store.delegationTableName == "" {
+		return []string{}, nil
+	}
+
+	now := carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+
+	delegations, err := store.delegationList(ctx,
+		goqu.C(COLUMN_SOFT_DELETED_AT).Gt(now),
+		goqu.C(COLUMN_DELEGATE_TYPE).Eq(entity.EntityType),
+		goqu.C(COLUMN_DELEGATE_ID).Eq(entity.EntityID),
+		goqu.C(COLUMN_ENDS_AT).Gt(now),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return lo.Map(delegations, func(delegation DelegationInterface, _ int) string {
+		return delegation.RoleID()
+	}), nil
+}
+
+// DelegationFindByID returns a delegation by its ID, including a revoked one,
+// or nil if not found
+func (store *store) DelegationFindByID(ctx context.Context, id string) (DelegationInterface, error) {
+	if id == "" {
+		return nil, errors.New("rolestore > DelegationFindByID. delegation id is empty")
+	}
+
+	list, err := store.delegationList(ctx, goqu.C(COLUMN_ID).Eq(id))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) > 0 {
+		return list[0], nil
+	}
+
+	return nil, nil
+}
+
+// DelegationList returns the delegations, which are not revoked,
+// matching the options, ordered by start date
+func (store *store) DelegationList(ctx context.Context, options DelegationListOptions) ([]DelegationInterface, error) {
+	now := carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+
+	conditions := []goqu.Expression{goqu.C(COLUMN_SOFT_DELETED_AT).Gt(now)}
+
+	if !options.Delegator.IsEmpty() {
+		conditions = append(conditions,
+			goqu.C(COLUMN_DELEGATOR_TYPE).Eq(options.Delegator.EntityType),
+			goqu.C(COLUMN_DELEGATOR_ID).Eq(options.Delegator.EntityID),
+		)
+	}
+
+	if !options.Delegate.IsEmpty() {
+		conditions = append(conditions,
+			goqu.C(COLUMN_DELEGATE_TYPE).Eq(options.Delegate.EntityType),
+			goqu.C(COLUMN_DELEGATE_ID).Eq(options.Delegate.EntityID),
+		)
+	}
+
+	if options.RoleID != "" {
+		conditions = append(conditions, goqu.C(COLUMN_ROLE_ID).Eq(options.RoleID))
+	}
+
+	if options.ActiveOnly {
+		conditions = append(conditions,
+			goqu.C(COLUMN_STARTS_AT).Lte(now),
+			goqu.C(COLUMN_ENDS_AT).Gt(now),
+		)
+	}
+
+	return store.delegationList(ctx, conditions...)
+}
+
+// DelegationRevoke revokes a delegation by its ID. If the delegate no
+// longer holds the role, its own delegations of the role are revoked too
+func (store *store) DelegationRevoke(ctx context.Context, id string) error {
+	if err := store.delegationEnabled("DelegationRevoke"); err != nil {
+		return err
+	}
+
+	if id == "" {
+		return errors.New("rolestore > DelegationRevoke. delegation id is empty")
+	}
+
+	return store.WithTx(ctx, func(txCtx context.Context) error {
+		delegation, err := store.DelegationFindByID(txCtx, id)
+
+		if err != nil {
+			return err
+		}
+
+		if delegation == nil || delegation.IsSoftDeleted() {
+			return nil
+		}
+
+		if err := store.delegationSoftDelete(txCtx, delegation); err != nil {
+			return err
+		}
+
+		return store.delegationRevokeByDelegator(txCtx, delegation.Delegate(), delegation.RoleID())
+	})
+}
+
+// EntityEffectiveRoles returns the active roles the entity holds directly,
+// and the active roles delegated to it by active delegations, from
+// delegators still holding them, ordered by handle
+func (store *store) EntityEffectiveRoles(ctx context.Context, entityType string, entityID string) ([]RoleInterface, error) {
+	entity := NewEntityRef(entityType, entityID)
+
+	if entity.IsEmpty() {
+		return []RoleInterface{}, errors.New("rolestore > EntityEffectiveRoles. entity type and ID are required")
+	}
+
+	rolesByEntity, err := store.EntitiesRoles(ctx, entityType, []string{entityID})
+
+	if err != nil {
+		return []RoleInterface{}, err
+	}
+
+	roles := rolesByEntity[entityID]
+
+	if store.delegationTableName == "" {
+		return roles, nil
+	}
+
+	delegations, err := store.DelegationList(ctx, DelegationListOptions{Delegate: entity, ActiveOnly: true})
+
+	if err != nil {
+		return []RoleInterface{}, err
+	}
+
+	for _, delegation := range delegations {
+		held := lo.ContainsBy(roles, func(role RoleInterface) bool {
+			return role.ID() == delegation.RoleID()
+		})
+
+		if held {
+			continue
+		}
+
+		holds, err := store.entityHoldsRole(ctx, delegation.Delegator(), delegation.RoleID(), true, map[EntityRef]bool{entity: true})
+
+		if err != nil {
+			return []RoleInterface{}, err
+		}
+
+		if !holds {
+			continue
+		}
+
+		role, err := store.RoleFindByID(ctx, delegation.RoleID())
+
+		if err != nil {
+			return []RoleInterface{}, err
+		}
+
+		if role != nil && role.IsActive() {
+			roles = append(roles, role)
+		}
+	}
+
+	slices.SortFunc(roles, func(a, b RoleInterface) int {
+		return strings.Compare(a.Handle(), b.Handle())
+	})
+
+	return roles, nil
+}
+
+// EntityHasRole returns whether the entity holds the active role,
+// directly, or by an active delegation from a delegator still holding it
+func (store *store) EntityHasRole(ctx context.Context, entityType string, entityID string, roleID string) (bool, error) {
+	entity := NewEntityRef(entityType, entityID)
+
+	if entity.IsEmpty() {
+		return false, errors.New("rolestore > EntityHasRole. entity type and ID are required")
+	}
+
+	if roleID == "" {
+		return false, errors.New("rolestore > EntityHasRole. role id is empty")
+	}
+
+	role, err := store.RoleFindByID(ctx, roleID)
+
+	if err != nil {
+		return false, err
+	}
+
+	if role == nil || !role.IsActive() {
+		return false, nil
+	}
+
+	return store.entityHoldsRole(ctx, entity, roleID, false, map[EntityRef]bool{})
+}
+
+// entityHoldsRole returns whether the entity holds the role, directly or by
+// an active delegation. If redelegating is true, delegations not allowing
+// redelegation are not counted, as the entity is delegating the role on.
+// The visited entities are skipped, so that delegation cycles end
+func (store *store) entityHoldsRole(ctx context.Context, entity EntityRef, roleID string, redelegating bool, visited map[EntityRef]bool) (bool, error) {
+	_, holds, err := store.entityHoldsRoleUntil(ctx, entity, roleID, redelegating, visited)
+
+	return holds, err
+}
+
+// entityHoldsRoleUntil is the same as entityHoldsRole, but also returns until
+// when the entity holds the role, i.e. the end of a just-in-time elevation,
+// or of the delegation, whichever ends last
+func (store *store) entityHoldsRoleUntil(ctx context.Context, entity EntityRef, roleID string, redelegating bool, visited map[EntityRef]bool) (carbon.Carbon, bool, error) {
+	until := carbon.Carbon{}
+	holds := false
+
+	entityRole, err := store.EntityRoleFindByEntityAndRole(ctx, entity.EntityType, entity.EntityID, roleID)
+
+	if err != nil {
+		return until, false, err
+	}
+
+	if entityRole != nil {
+		until, holds = entityRole.SoftDeletedAtCarbon(), true
+	}
+
+	if store.delegationTableName == "" {
+		return until, holds, nil
+	}
+
+	// only the entities on the current path are skipped, as an entity
+	// reached by another path may hold the role for longer
+	visited[entity] = true
+	defer delete(visited, entity)
+
+	delegations, err := store.DelegationList(ctx, DelegationListOptions{
+		Delegate:   entity,
+		RoleID:     roleID,
+		ActiveOnly: true,
+	})
+
+	if err != nil {
+		return until, false, err
+	}
+
+	for _, delegation := range delegations {
+		if redelegating && delegation.NoRedelegation() {
+			continue
+		}
+
+		if visited[delegation.Delegator()] {
+			continue
+		}
+
+		delegatorUntil, delegatorHolds, err := store.entityHoldsRoleUntil(ctx, delegation.Delegator(), roleID, true, visited)
+
+		if err != nil {
+			return until, false, err
+		}
+
+		if !delegatorHolds {
+			continue
+		}
+
+		delegatedUntil := delegation.EndsAtCarbon()
+
+		if delegatorUntil.Lt(delegatedUntil) {
+			delegatedUntil = delegatorUntil
+		}
+
+		if !holds || delegatedUntil.Gt(until) {
+			until, holds = delegatedUntil, true
+		}
+	}
+
+	return until, holds, nil
+}
+
+// delegationRevokeByDelegator revokes the delegations of the role by the
+// delegator, unless it still holds the role, i.e. after its assignment was
+// removed. The delegates losing the role have their delegations revoked too
+func (store *store) delegationRevokeByDelegator(ctx context.Context, delegator EntityRef, roleID string) error {
+	if store.delegationTableName == "" {
+		return nil
+	}
+
+	holds, err := store.entityHoldsRole(ctx, delegator, roleID, true, map[EntityRef]bool{})
+
+	if err != nil {
+		return err
+	}
+
+	if holds {
+		return nil
+	}
+
+	delegations, err := store.DelegationList(ctx, DelegationListOptions{Delegator: delegator, RoleID: roleID})
+
+	if err != nil {
+		return err
+	}
+
+	for _, delegation := range delegations {
+		if err := store.delegationSoftDelete(ctx, delegation); err != nil {
+			return err
+		}
+
+		if err := store.delegationRevokeByDelegator(ctx, delegation.Delegate(), roleID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// delegationSoftDelete marks the delegation as revoked
+func (store *store) delegationSoftDelete(ctx context.Context, delegation DelegationInterface) error {
+	delegation.SetSoftDeletedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	delegation.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	dataChanged := delegation.DataChanged()
+
+	delete(dataChanged, COLUMN_ID) // ID is not updateable
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Update(store.delegationTableName).
+		Prepared(true).
+		Set(dataChanged).
+		Where(goqu.C(COLUMN_ID).Eq(delegation.ID())).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	store.logSql("update", sqlStr, params...)
+
+	if _, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...); err != nil {
+		return err
+	}
+
+	delegation.MarkAsNotDirty()
+
+	return nil
+}
+
+// delegationList returns the delegations matching the conditions
+func (store *store) delegationList(ctx context.Context, conditions ...goqu.Expression) ([]DelegationInterface, error) {
+	if err := store.delegationEnabled("DelegationList"); err != nil {
+		return []DelegationInterface{}, err
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(store.delegationTableName).
+		Prepared(true).
+		Where(conditions...).
+		Order(goqu.C(COLUMN_STARTS_AT).Asc(), goqu.C(COLUMN_ID).Asc()).
+		ToSQL()
+
+	if errSql != nil {
+		return []DelegationInterface{}, errSql
+	}
+
+	rows, err := store.selectToMaps(ctx, sqlStr, params...)
+
+	if err != nil {
+		return []DelegationInterface{}, err
+	}
+
+	return lo.Map(rows, func(row map[string]string, _ int) DelegationInterface {
+		return NewDelegationFromExistingData(row)
+	}), nil
+}
+
+// delegationEnabled returns an error, if no delegation table is configured
+func (store *store) delegationEnabled(method string) error {
+	if store.delegationTableName == "" {
+		return errors.New("rolestore > " + method + ". delegations are disabled, DelegationTableName is not set")
+	}
+
+	return nil
+}
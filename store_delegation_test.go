@@ -0,0 +1,474 @@
+package rolestore
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dromara/carbon/v2"
+)
+
+func initDelegationStore(t *testing.T) StoreInterface {
+	return initStoreWithOptions(t, NewStoreOptions{DelegationTableName: "roles_delegation_table"})
+}
+
+func createDelegationRole(t *testing.T, store StoreInterface) RoleInterface {
+	role := createTestRole(t, store, "approver", nil)
+
+	err := store.EntityRoleCreate(context.Background(), NewEntityRole().
+		SetEntityType("user").
+		SetEntityID("MANAGER_01").
+		SetRoleID(role.ID()))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	return role
+}
+
+func newTestDelegation(delegatorID string, delegateID string, roleID string) DelegationInterface {
+	return NewDelegation().
+		SetDelegatorType("user").
+		SetDelegatorID(delegatorID).
+		SetDelegateType("user").
+		SetDelegateID(delegateID).
+		SetRoleID(roleID)
+}
+
+func TestStoreDelegationCreate(t *testing.T) {
+	store := initDelegationStore(t)
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	role := createDelegationRole(t, store)
+
+	hasRole, err := store.EntityHasRole(context.Background(), "user", "DEPUTY_01", role.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if hasRole {
+		t.Fatal("deputy MUST NOT hold the role before the delegation")
+	}
+
+	// the delegator must hold the role
+	err = store.DelegationCreate(context.Background(), newTestDelegation("USER_09", "DEPUTY_01", role.ID()))
+
+	if !errors.Is(err, ErrDelegationNotAllowed) {
+		t.Fatal("expected ErrDelegationNotAllowed, got:", err)
+	}
+
+	if err := store.DelegationCreate(context.Background(), newTestDelegation("MANAGER_01", "MANAGER_01", role.ID())); err == nil {
+		t.Fatal("error MUST NOT be nil for delegating to self")
+	}
+
+	delegation := newTestDelegation("MANAGER_01", "DEPUTY_01", role.ID()).SetMemo("holiday")
+
+	if err := store.DelegationCreate(context.Background(), delegation); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	found, err := store.DelegationFindByID(context.Background(), delegation.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if found == nil || found.Memo() != "holiday" || !found.IsActive() {
+		t.Fatal("delegation MUST be found and active")
+	}
+
+	hasRole, err = store.EntityHasRole(context.Background(), "user", "DEPUTY_01", role.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !hasRole {
+		t.Fatal("deputy MUST hold the delegated role")
+	}
+
+	roles, err := store.EntityEffectiveRoles(context.Background(), "user", "DEPUTY_01")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(roles) != 1 || roles[0].Handle() != "approver" {
+		t.Fatal("unexpected effective roles length:", len(roles))
+	}
+
+	userRoles, err := store.UserRoles(context.Background(), &testUser{id: "DEPUTY_01"})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(userRoles) != 1 {
+		t.Fatal("user roles MUST include the delegated role, got:", len(userRoles))
+	}
+}
+
+func TestStoreDelegationTimeWindow(t *testing.T) {
+	store := initDelegationStore(t)
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	role := createDelegationRole(t, store)
+
+	delegation := newTestDelegation("MANAGER_01", "DEPUTY_01", role.ID()).
+		SetStartsAt(carbon.Now(carbon.UTC).AddDay().ToDateTimeString(carbon.UTC)).
+		SetEndsAt(carbon.Now(carbon.UTC).AddDays(3).ToDateTimeString(carbon.UTC))
+
+	if err := store.DelegationCreate(context.Background(), delegation); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	hasRole, err := store.EntityHasRole(context.Background(), "user", "DEPUTY_01", role.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if hasRole {
+		t.Fatal("deputy MUST NOT hold the role before the delegation starts")
+	}
+
+	active, err := store.DelegationList(context.Background(), DelegationListOptions{
+		Delegate:   NewEntityRef("user", "DEPUTY_01"),
+		ActiveOnly: true,
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(active) != 0 {
+		t.Fatal("unexpected active delegations length:", len(active))
+	}
+
+	all, err := store.DelegationList(context.Background(), DelegationListOptions{Delegate: NewEntityRef("user", "DEPUTY_01")})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(all) != 1 {
+		t.Fatal("unexpected delegations length:", len(all))
+	}
+
+	ended := newTestDelegation("MANAGER_01", "DEPUTY_02", role.ID()).
+		SetStartsAt(carbon.Now(carbon.UTC).SubDays(3).ToDateTimeString(carbon.UTC)).
+		SetEndsAt(carbon.Now(carbon.UTC).SubDay().ToDateTimeString(carbon.UTC))
+
+	if err := store.DelegationCreate(context.Background(), ended); err == nil {
+		t.Fatal("error MUST NOT be nil for a delegation already ended")
+	}
+}
+
+func TestStoreDelegationNoRedelegation(t *testing.T) {
+	store := initDelegationStore(t)
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	role := createDelegationRole(t, store)
+
+	if err := store.DelegationCreate(context.Background(), newTestDelegation("MANAGER_01", "DEPUTY_01", role.ID())); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// redelegation is allowed by default
+	if err := store.DelegationCreate(context.Background(), newTestDelegation("DEPUTY_01", "DEPUTY_02", role.ID()).SetNoRedelegation(true)); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	hasRole, err := store.EntityHasRole(context.Background(), "user", "DEPUTY_02", role.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !hasRole {
+		t.Fatal("deputy of deputy MUST hold the redelegated role")
+	}
+
+	err = store.DelegationCreate(context.Background(), newTestDelegation("DEPUTY_02", "DEPUTY_03", role.ID()))
+
+	if !errors.Is(err, ErrDelegationNotAllowed) {
+		t.Fatal("expected ErrDelegationNotAllowed, got:", err)
+	}
+}
+
+func TestStoreDelegationRevokedWithRole(t *testing.T) {
+	store := initDelegationStore(t)
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	role := createDelegationRole(t, store)
+
+	first := newTestDelegation("MANAGER_01", "DEPUTY_01", role.ID())
+
+	if err := store.DelegationCreate(context.Background(), first); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	second := newTestDelegation("DEPUTY_01", "DEPUTY_02", role.ID())
+
+	if err := store.DelegationCreate(context.Background(), second); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	entityRole, err := store.EntityRoleFindByEntityAndRole(context.Background(), "user", "MANAGER_01", role.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.EntityRoleSoftDelete(context.Background(), entityRole); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	for _, id := range []string{first.ID(), second.ID()} {
+		delegation, err := store.DelegationFindByID(context.Background(), id)
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if !delegation.IsSoftDeleted() {
+			t.Fatal("delegation MUST be revoked with the role of the delegator:", id)
+		}
+	}
+
+	hasRole, err := store.EntityHasRole(context.Background(), "user", "DEPUTY_02", role.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if hasRole {
+		t.Fatal("deputy MUST NOT hold the role after the delegator lost it")
+	}
+}
+
+func TestStoreDelegationRevoke(t *testing.T) {
+	store := initDelegationStore(t)
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	role := createDelegationRole(t, store)
+
+	delegation := newTestDelegation("MANAGER_01", "DEPUTY_01", role.ID())
+
+	if err := store.DelegationCreate(context.Background(), delegation); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.DelegationRevoke(context.Background(), delegation.ID()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	list, err := store.DelegationList(context.Background(), DelegationListOptions{Delegator: NewEntityRef("user", "MANAGER_01")})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(list) != 0 {
+		t.Fatal("revoked delegations MUST NOT be listed, got:", len(list))
+	}
+
+	roles, err := store.EntityEffectiveRoles(context.Background(), "user", "DEPUTY_01")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(roles) != 0 {
+		t.Fatal("unexpected effective roles length:", len(roles))
+	}
+}
+
+func TestStoreDelegationRevokedWithTransferAndDelete(t *testing.T) {
+	store := initDelegationStore(t)
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	role := createDelegationRole(t, store)
+
+	transferred := newTestDelegation("MANAGER_01", "DEPUTY_01", role.ID())
+
+	if err := store.DelegationCreate(context.Background(), transferred); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	_, err := store.EntityTransferRoles(context.Background(), NewEntityRef("user", "MANAGER_01"), NewEntityRef("user", "MANAGER_02"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	deleted := newTestDelegation("MANAGER_02", "DEPUTY_02", role.ID())
+
+	if err := store.DelegationCreate(context.Background(), deleted); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	entityRole, err := store.EntityRoleFindByEntityAndRole(context.Background(), "user", "MANAGER_02", role.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.EntityRoleDelete(context.Background(), entityRole); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	for _, id := range []string{transferred.ID(), deleted.ID()} {
+		delegation, err := store.DelegationFindByID(context.Background(), id)
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if !delegation.IsSoftDeleted() {
+			t.Fatal("delegation MUST be revoked, when the delegator no longer holds the role:", id)
+		}
+	}
+}
+
+func TestStoreDelegationEndsWithElevation(t *testing.T) {
+	store := initDelegationStore(t)
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	role := createDelegationRole(t, store)
+
+	// held for an hour only, as by an elevation
+	elevatedUntil := carbon.Now(carbon.UTC).AddHour()
+
+	err := store.EntityRoleCreate(context.Background(), NewEntityRole().
+		SetEntityType("user").
+		SetEntityID("MANAGER_02").
+		SetRoleID(role.ID()).
+		SetSoftDeletedAt(elevatedUntil.ToDateTimeString(carbon.UTC)))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	delegation := newTestDelegation("MANAGER_02", "DEPUTY_01", role.ID())
+
+	if err := store.DelegationCreate(context.Background(), delegation); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if delegation.EndsAtCarbon().DiffAbsInMinutes(elevatedUntil) > 1 {
+		t.Fatal("delegation MUST end with the elevation, ends at:", delegation.EndsAt())
+	}
+
+	// starting after the elevation ends
+	late := newTestDelegation("MANAGER_02", "DEPUTY_02", role.ID()).
+		SetStartsAt(carbon.Now(carbon.UTC).AddHours(2).ToDateTimeString(carbon.UTC))
+
+	err = store.DelegationCreate(context.Background(), late)
+
+	if !errors.Is(err, ErrDelegationNotAllowed) {
+		t.Fatal("expected ErrDelegationNotAllowed, got:", err)
+	}
+}
+
+func TestStoreDelegationCreate_SodConstraint(t *testing.T) {
+	store := initStoreWithOptions(t, NewStoreOptions{
+		DelegationTableName:    "roles_delegation_table",
+		SodConstraintTableName: "roles_sod_constraint_table",
+	})
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	approver := createDelegationRole(t, store)
+	initiator := createTestRole(t, store, "initiator", nil)
+
+	constraint := NewSodConstraint().SetTitle("Payments").SetMaxRoles(1)
+
+	if err := constraint.SetRoleIDs([]string{initiator.ID(), approver.ID()}); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.SodConstraintCreate(context.Background(), constraint); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err := store.EntityRoleCreate(context.Background(), NewEntityRole().
+		SetEntityType("user").
+		SetEntityID("DEPUTY_01").
+		SetRoleID(initiator.ID()))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.DelegationCreate(context.Background(), newTestDelegation("MANAGER_01", "DEPUTY_01", approver.ID()))
+
+	if !errors.Is(err, ErrSodViolation) {
+		t.Fatal("delegating to the holder of a conflicting role MUST be a sod violation, found:", err)
+	}
+
+	hasRole, err := store.EntityHasRole(context.Background(), "user", "DEPUTY_01", approver.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if hasRole {
+		t.Fatal("deputy MUST NOT hold the conflicting role")
+	}
+
+	// a delegated role counts as held, when assigning a conflicting role
+	if err := store.DelegationCreate(context.Background(), newTestDelegation("MANAGER_01", "DEPUTY_02", approver.ID())); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.EntityRoleCreate(context.Background(), NewEntityRole().
+		SetEntityType("user").
+		SetEntityID("DEPUTY_02").
+		SetRoleID(initiator.ID()))
+
+	if !errors.Is(err, ErrSodViolation) {
+		t.Fatal("assigning a role conflicting with a delegated one MUST be a sod violation, found:", err)
+	}
+}
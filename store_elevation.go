@@ -0,0 +1,202 @@
+package rolestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/spf13/cast"
+)
+
+// ElevationOptions define a just-in-time elevation of an entity to a role
+type ElevationOptions struct {
+	// EntityType is the type of the entity to elevate
+	EntityType string
+
+	// EntityID is the ID of the entity to elevate
+	EntityID string
+
+	// RoleID is the ID of the role to grant
+	RoleID string
+
+	// Duration is how long the role is granted for, required
+	Duration time.Duration
+
+	// Reason is why the elevation is needed, required
+	Reason string
+
+	// ApprovedBy is the approver of the elevation, which must be one of the
+	// approvers of the role. Optional only for break-glass roles
+	ApprovedBy EntityRef
+}
+
+// EntityRoleElevate grants a role to an entity for a bounded duration.
+// The assignment expires by itself, as its soft_deleted_at is set to the
+// end of the elevation. The duration must not exceed the maximum elevation
+// duration of the role (if any). Unless approved by an approver of the role,
+// the role must be break-glass, and the assignment is marked for review.
+// An audit entry is emitted for each elevation
+func (store *store) EntityRoleElevate(ctx context.Context, options ElevationOptions) (EntityRoleInterface, error) {
+	entity := NewEntityRef(options.EntityType, options.EntityID)
+
+	if entity.IsEmpty() {
+		return nil, errors.New("rolestore > EntityRoleElevate. entity type and ID are required")
+	}
+
+	if options.RoleID == "" {
+		return nil, errors.New("rolestore > EntityRoleElevate. role id is empty")
+	}
+
+	if options.Duration <= 0 {
+		return nil, errors.New("rolestore > EntityRoleElevate. duration must be positive")
+	}
+
+	if options.Reason == "" {
+		return nil, errors.New("rolestore > EntityRoleElevate. reason is required")
+	}
+
+	role, err := store.RoleFindByID(ctx, options.RoleID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if role == nil || !role.IsActive() {
+		return nil, errors.New("rolestore > EntityRoleElevate. role not found or not active: " + options.RoleID)
+	}
+
+	if maxDuration := role.ElevationMaxDuration(); maxDuration > 0 && options.Duration > maxDuration {
+		return nil, fmt.Errorf("%w: role %s allows elevations of at most %s", ErrElevationNotAllowed, role.Handle(), maxDuration)
+	}
+
+	breakGlass := options.ApprovedBy.IsEmpty()
+
+	if breakGlass && !role.IsBreakGlass() {
+		return nil, fmt.Errorf("%w: role %s requires an approver", ErrElevationNotAllowed, role.Handle())
+	}
+
+	if !breakGlass && (options.ApprovedBy == entity || !role.IsApprover(options.ApprovedBy)) {
+		return nil, fmt.Errorf("%w: %s %s is not an approver of role %s", ErrElevationNotAllowed, options.ApprovedBy.EntityType, options.ApprovedBy.EntityID, role.Handle())
+	}
+
+	grantedBy := options.ApprovedBy
+
+	if breakGlass {
+		grantedBy = entity
+	}
+
+	entityRole := NewEntityRole().
+		SetEntityType(options.EntityType).
+		SetEntityID(options.EntityID).
+		SetRoleID(options.RoleID).
+		SetGrantedByType(grantedBy.EntityType).
+		SetGrantedByID(grantedBy.EntityID).
+		SetReason(options.Reason).
+		SetSource(ENTITY_ROLE_SOURCE_ELEVATION).
+		SetSoftDeletedAt(carbon.CreateFromStdTime(time.Now().Add(options.Duration)).ToDateTimeString(carbon.UTC))
+
+	if breakGlass {
+		if err := entityRole.SetMeta(ENTITY_ROLE_META_REVIEW_REQUIRED, "true"); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := store.EntityRoleCreate(ctx, entityRole); err != nil {
+		return nil, err
+	}
+
+	action := AUDIT_ACTION_ELEVATE
+
+	if breakGlass {
+		action = AUDIT_ACTION_BREAK_GLASS
+	}
+
+	store.audit(ctx, AuditEntry{
+		Action:       action,
+		Actor:        grantedBy,
+		Entity:       entity,
+		RoleID:       entityRole.RoleID(),
+		EntityRoleID: entityRole.ID(),
+		Reason:       entityRole.Reason(),
+		ExpiresAt:    entityRole.SoftDeletedAt(),
+		CreatedAt:    entityRole.CreatedAt(),
+	})
+
+	return entityRole, nil
+}
+
+// EntityRoleListReviewRequired returns the break-glass elevations, which
+// have not been reviewed yet, including the ones already expired
+func (store *store) EntityRoleListReviewRequired(ctx context.Context) ([]EntityRoleInterface, error) {
+	return store.EntityRoleList(ctx, NewEntityRoleQuery().
+		SetMetaEquals(ENTITY_ROLE_META_REVIEW_REQUIRED, "true").
+		SetSoftDeletedIncluded(true).
+		SetOrderBy(COLUMN_CREATED_AT).
+		SetSortDirection(ASC))
+}
+
+// EntityRoleMarkReviewed marks a break-glass elevation (live or expired)
+// as reviewed by the reviewer, who cannot be the elevated entity itself.
+// An audit entry is emitted for the review
+func (store *store) EntityRoleMarkReviewed(ctx context.Context, entityRoleID string, reviewer EntityRef) error {
+	if entityRoleID == "" {
+		return errors.New("rolestore > EntityRoleMarkReviewed. entity role id is empty")
+	}
+
+	if reviewer.IsEmpty() {
+		return errors.New("rolestore > EntityRoleMarkReviewed. reviewer entity type and ID are required")
+	}
+
+	list, err := store.EntityRoleList(ctx, NewEntityRoleQuery().
+		SetID(entityRoleID).
+		SetSoftDeletedIncluded(true).
+		SetLimit(1))
+
+	if err != nil {
+		return err
+	}
+
+	if len(list) == 0 {
+		return errors.New("rolestore > EntityRoleMarkReviewed. entity role not found: " + entityRoleID)
+	}
+
+	entityRole := list[0]
+
+	if !cast.ToBool(entityRole.Meta(ENTITY_ROLE_META_REVIEW_REQUIRED)) {
+		return errors.New("rolestore > EntityRoleMarkReviewed. entity role does not require a review: " + entityRoleID)
+	}
+
+	if reviewer == NewEntityRef(entityRole.EntityType(), entityRole.EntityID()) {
+		return errors.New("rolestore > EntityRoleMarkReviewed. entities cannot review their own elevations")
+	}
+
+	metas := map[string]string{
+		ENTITY_ROLE_META_REVIEW_REQUIRED: "",
+		ENTITY_ROLE_META_REVIEWED_AT:     carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC),
+		ENTITY_ROLE_META_REVIEWED_BY:     reviewer.EntityType + ":" + reviewer.EntityID,
+	}
+
+	for name, value := range metas {
+		if err := entityRole.SetMeta(name, value); err != nil {
+			return err
+		}
+	}
+
+	if err := store.EntityRoleUpdate(ctx, entityRole); err != nil {
+		return err
+	}
+
+	store.audit(ctx, AuditEntry{
+		Action:       AUDIT_ACTION_BREAK_GLASS_REVIEWED,
+		Actor:        reviewer,
+		Entity:       NewEntityRef(entityRole.EntityType(), entityRole.EntityID()),
+		RoleID:       entityRole.RoleID(),
+		EntityRoleID: entityRole.ID(),
+		Reason:       entityRole.Reason(),
+		CreatedAt:    carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC),
+	})
+
+	return nil
+}
@@ -0,0 +1,202 @@
+package rolestore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dromara/carbon/v2"
+)
+
+func initElevationStore(t *testing.T, hook AuditHook) StoreInterface {
+	return initStoreWithOptions(t, NewStoreOptions{AuditHook: hook})
+}
+
+func createElevationRole(t *testing.T, store StoreInterface, breakGlass bool) RoleInterface {
+	return createTestRole(t, store, "prod_admin", func(role RoleInterface) error {
+		if err := role.SetElevationMaxDuration(2 * time.Hour); err != nil {
+			return err
+		}
+
+		if err := role.SetApprovers([]EntityRef{NewEntityRef("user", "MANAGER_01")}); err != nil {
+			return err
+		}
+
+		return role.SetBreakGlass(breakGlass)
+	})
+}
+
+func TestStoreEntityRoleElevate(t *testing.T) {
+	entries := []AuditEntry{}
+
+	store := initElevationStore(t, func(_ context.Context, entry AuditEntry) {
+		entries = append(entries, entry)
+	})
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	role := createElevationRole(t, store, false)
+
+	options := ElevationOptions{
+		EntityType: "user",
+		EntityID:   "USER_01",
+		RoleID:     role.ID(),
+		Duration:   time.Hour,
+		Reason:     "incident INC-42",
+	}
+
+	// not break-glass, an approver is required
+	_, err := store.EntityRoleElevate(context.Background(), options)
+
+	if !errors.Is(err, ErrElevationNotAllowed) {
+		t.Fatal("expected ErrElevationNotAllowed, got:", err)
+	}
+
+	options.ApprovedBy = NewEntityRef("user", "MANAGER_01")
+	options.Duration = 3 * time.Hour
+
+	_, err = store.EntityRoleElevate(context.Background(), options)
+
+	if !errors.Is(err, ErrElevationNotAllowed) {
+		t.Fatal("expected ErrElevationNotAllowed for a too long duration, got:", err)
+	}
+
+	options.Duration = time.Hour
+	options.Reason = ""
+
+	if _, err := store.EntityRoleElevate(context.Background(), options); err == nil {
+		t.Fatal("error MUST NOT be nil without a reason")
+	}
+
+	options.Reason = "incident INC-42"
+
+	entityRole, err := store.EntityRoleElevate(context.Background(), options)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if entityRole.Source() != ENTITY_ROLE_SOURCE_ELEVATION || entityRole.GrantedByID() != "MANAGER_01" {
+		t.Fatal("unexpected provenance:", entityRole.Source(), entityRole.GrantedByID())
+	}
+
+	expiresAt := carbon.Parse(entityRole.SoftDeletedAt(), carbon.UTC)
+
+	if expiresAt.DiffAbsInMinutes(carbon.Now(carbon.UTC).AddHour()) > 1 {
+		t.Fatal("unexpected expiry:", entityRole.SoftDeletedAt())
+	}
+
+	hasRole, err := store.EntityRoleFindByEntityAndRole(context.Background(), "user", "USER_01", role.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if hasRole == nil {
+		t.Fatal("elevated role MUST be held until it expires")
+	}
+
+	if len(entries) != 1 || entries[0].Action != AUDIT_ACTION_ELEVATE || entries[0].Actor.EntityID != "MANAGER_01" {
+		t.Fatal("unexpected audit entries:", entries)
+	}
+
+	// an expired elevation is no longer held
+	hasRole.SetSoftDeletedAt(carbon.Now(carbon.UTC).SubMinute().ToDateTimeString(carbon.UTC))
+
+	if err := store.EntityRoleUpdate(context.Background(), hasRole); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	hasRole, err = store.EntityRoleFindByEntityAndRole(context.Background(), "user", "USER_01", role.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if hasRole != nil {
+		t.Fatal("expired elevation MUST NOT be held")
+	}
+}
+
+func TestStoreEntityRoleElevateBreakGlass(t *testing.T) {
+	entries := []AuditEntry{}
+
+	store := initElevationStore(t, func(_ context.Context, entry AuditEntry) {
+		entries = append(entries, entry)
+	})
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	role := createElevationRole(t, store, true)
+
+	entityRole, err := store.EntityRoleElevate(context.Background(), ElevationOptions{
+		EntityType: "user",
+		EntityID:   "USER_01",
+		RoleID:     role.ID(),
+		Duration:   30 * time.Minute,
+		Reason:     "database down, on call",
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if entityRole.GrantedByID() != "USER_01" {
+		t.Fatal("break-glass elevation MUST be granted by the entity itself, got:", entityRole.GrantedByID())
+	}
+
+	if entries[0].Action != AUDIT_ACTION_BREAK_GLASS {
+		t.Fatal("unexpected audit action:", entries[0].Action)
+	}
+
+	pending, err := store.EntityRoleListReviewRequired(context.Background())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(pending) != 1 || pending[0].ID() != entityRole.ID() {
+		t.Fatal("unexpected review required length:", len(pending))
+	}
+
+	err = store.EntityRoleMarkReviewed(context.Background(), entityRole.ID(), NewEntityRef("user", "USER_01"))
+
+	if err == nil {
+		t.Fatal("error MUST NOT be nil for reviewing an own elevation")
+	}
+
+	err = store.EntityRoleMarkReviewed(context.Background(), entityRole.ID(), NewEntityRef("user", "MANAGER_01"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	pending, err = store.EntityRoleListReviewRequired(context.Background())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(pending) != 0 {
+		t.Fatal("unexpected review required length after review:", len(pending))
+	}
+
+	if len(entries) != 2 || entries[1].Action != AUDIT_ACTION_BREAK_GLASS_REVIEWED {
+		t.Fatal("unexpected audit entries:", entries)
+	}
+
+	err = store.EntityRoleMarkReviewed(context.Background(), entityRole.ID(), NewEntityRef("user", "MANAGER_01"))
+
+	if err == nil {
+		t.Fatal("error MUST NOT be nil for reviewing twice")
+	}
+}
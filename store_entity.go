@@ -3,13 +3,14 @@ package rolestore
 import (
 	"context"
 	"errors"
+	"maps"
+	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/doug-martin/goqu/v9"
 	"github.com/dromara/carbon/v2"
 	"github.com/gouniverse/base/database"
-	"github.com/gouniverse/sb"
 	"github.com/samber/lo"
 	"github.com/spf13/cast"
 )
@@ -17,7 +18,11 @@ import (
 func (store *store) EntityRoleCount(ctx context.Context, options EntityRoleQueryInterface) (int64, error) {
 	options.SetCountOnly(true)
 
-	q, _, err := store.entityRoleSelectQuery(options)
+	q, _, err := store.entityRoleSelectQuery(options, false)
+
+	if err != nil {
+		return -1, err
+	}
 
 	sqlStr, params, errSql := q.Prepared(true).
 		Limit(1).
@@ -68,6 +73,32 @@ func (store *store) EntityRoleCreate(ctx context.Context, entityRole EntityRoleI
 		return errors.New("rolestore > EntityRoleCreate. entityRole entityType is empty")
 	}
 
+	if err := validateEntityRoleProvenance("EntityRoleCreate", entityRole); err != nil {
+		return err
+	}
+
+	// the checks and the insert run in one transaction. The role, and the
+	// roles sharing a separation of duties constraint with it, are locked,
+	// so concurrent assignments which could conflict are serialized
+	return store.WithTx(ctx, func(txCtx context.Context) error {
+		return store.entityRoleCreate(txCtx, entityRole, false)
+	})
+}
+
+// entityRoleCreate checks and inserts a role entity mapping, it is run within
+// the transaction started by EntityRoleCreate or EntityRoleUpsert. With
+// onConflict, a live mapping of the same entity and role, inserted since
+// the checks, is updated instead, and its ID and version are copied back
+func (store *store) entityRoleCreate(ctx context.Context, entityRole EntityRoleInterface, onConflict bool) error {
+	// locked before any read, see sodLockForUpdate
+	if err := store.sodLockForUpdate(ctx, entityRole.RoleID()); err != nil {
+		return err
+	}
+
+	if err := store.validateEntityRole(ctx, entityRole); err != nil {
+		return err
+	}
+
 	entityRoleExists, err := store.EntityRoleFindByEntityAndRole(
 		ctx,
 		entityRole.EntityType(),
@@ -83,6 +114,14 @@ func (store *store) EntityRoleCreate(ctx context.Context, entityRole EntityRoleI
 		return errors.New("rolestore > EntityRoleCreate. entityRole with the same entityType-entityID-roleID combination already exists")
 	}
 
+	if err := store.sodCheck(ctx, entityRole.EntityType(), entityRole.EntityID(), entityRole.RoleID(), ""); err != nil {
+		return err
+	}
+
+	if err := store.roleHoldersCheck(ctx, entityRole.RoleID()); err != nil {
+		return err
+	}
+
 	entityRole.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
 	entityRole.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
 
@@ -98,6 +137,14 @@ func (store *store) EntityRoleCreate(ctx context.Context, entityRole EntityRoleI
 		return errSql
 	}
 
+	if onConflict {
+		columns := lo.Filter(slices.Sorted(maps.Keys(data)), func(column string, _ int) bool {
+			return column != COLUMN_ID && column != COLUMN_CREATED_AT && column != COLUMN_VERSION
+		})
+
+		sqlStr += store.sqlEntityRoleLiveOnConflict(columns)
+	}
+
 	store.logSql("insert", sqlStr, params...)
 
 	if store.db == nil {
@@ -110,6 +157,20 @@ func (store *store) EntityRoleCreate(ctx context.Context, entityRole EntityRoleI
 		return err
 	}
 
+	if onConflict {
+		stored, err := store.EntityRoleFindByEntityAndRole(ctx, entityRole.EntityType(), entityRole.EntityID(), entityRole.RoleID())
+
+		if err != nil {
+			return err
+		}
+
+		if stored != nil {
+			entityRole.SetID(stored.ID())
+			entityRole.SetCreatedAt(stored.CreatedAtCarbon().ToDateTimeString(carbon.UTC))
+			entityRole.SetVersion(stored.Version())
+		}
+	}
+
 	entityRole.MarkAsNotDirty()
 
 	return nil
@@ -128,21 +189,43 @@ func (store *store) EntityRoleDeleteByID(ctx context.Context, id string) error {
 		return errors.New("entityRole id is empty")
 	}
 
-	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
-		Delete(store.entityRoleTableName).
-		Prepared(true).
-		Where(goqu.C(COLUMN_ID).Eq(id)).
-		ToSQL()
+	// the delete and the revoke of the delegations run in one transaction
+	return store.WithTx(ctx, func(txCtx context.Context) error {
+		var entityRole EntityRoleInterface
 
-	if errSql != nil {
-		return errSql
-	}
+		if store.delegationTableName != "" {
+			var err error
+			entityRole, err = store.entityRoleFindByIDSoftDeletedIncluded(txCtx, id)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+			Delete(store.entityRoleTableName).
+			Prepared(true).
+			Where(goqu.C(COLUMN_ID).Eq(id)).
+			ToSQL()
 
-	store.logSql("delete", sqlStr, params...)
+		if errSql != nil {
+			return errSql
+		}
+
+		store.logSql("delete", sqlStr, params...)
+
+		if _, err := database.Execute(store.toQuerableContext(txCtx), sqlStr, params...); err != nil {
+			return err
+		}
 
-	_, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+		if entityRole == nil {
+			return nil
+		}
 
-	return err
+		// the delegations of a removed role are revoked with it
+		delegator := NewEntityRef(entityRole.EntityType(), entityRole.EntityID())
+		return store.delegationRevokeByDelegator(txCtx, delegator, entityRole.RoleID())
+	})
 }
 
 func (store *store) EntityRoleFindByEntityAndRole(
@@ -207,7 +290,11 @@ func (store *store) EntityRoleList(ctx context.Context, query EntityRoleQueryInt
 		return []EntityRoleInterface{}, errors.New("at entityRole list > entityRole query is nil")
 	}
 
-	q, columns, err := store.entityRoleSelectQuery(query)
+	q, columns, err := store.entityRoleSelectQuery(query, false)
+
+	if err != nil {
+		return []EntityRoleInterface{}, err
+	}
 
 	sqlStr, sqlParams, errSql := q.Prepared(true).Select(columns...).ToSQL()
 
@@ -237,6 +324,169 @@ func (store *store) EntityRoleList(ctx context.Context, query EntityRoleQueryInt
 	return list, nil
 }
 
+// EntityRoleListByCursor returns a page of role entity mappings using keyset (cursor) pagination.
+// The query must have a limit, which is the page size. The next page is
+// requested by setting the returned NextCursor on the same query.
+func (store *store) EntityRoleListByCursor(ctx context.Context, query EntityRoleQueryInterface) (CursorPage[EntityRoleInterface], error) {
+	page := CursorPage[EntityRoleInterface]{Items: []EntityRoleInterface{}}
+
+	if query == nil {
+		return page, errors.New("at entityRole list by cursor > entityRole query is nil")
+	}
+
+	if !query.HasLimit() {
+		return page, errors.New("at entityRole list by cursor > limit is required")
+	}
+
+	orderBys := queryOrderBys(query.OrderBys(), query.OrderBy(), query.SortDirection())
+	keys := cursorKeys(orderBys, query.SortDirection())
+
+	if len(query.Columns()) > 0 {
+		query = query.Clone().SetColumns(cursorEnsureColumns(query.Columns(), keys))
+	}
+
+	q, columns, err := store.entityRoleSelectQuery(query, false)
+
+	if err != nil {
+		return page, err
+	}
+
+	sqlStr, sqlParams, errSql := q.Prepared(true).
+		ClearOrder().
+		Order(cursorOrder(keys, store.entityRoleTableName)...).
+		Limit(cast.ToUint(query.Limit() + 1)). // one more, to find if there is a next page
+		Select(columns...).
+		ToSQL()
+
+	if errSql != nil {
+		return page, errSql
+	}
+
+	store.logSql("select", sqlStr, sqlParams...)
+
+	if store.db == nil {
+		return page, errors.New("entityRolestore: database is nil")
+	}
+
+	modelMaps, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, sqlParams...)
+
+	if err != nil {
+		return page, err
+	}
+
+	if len(modelMaps) > query.Limit() {
+		page.HasMore = true
+		modelMaps = modelMaps[:query.Limit()]
+	}
+
+	for _, modelMap := range modelMaps {
+		page.Items = append(page.Items, NewEntityRoleFromExistingData(modelMap))
+	}
+
+	if page.HasMore {
+		page.NextCursor, err = cursorEncode(keys, modelMaps[len(modelMaps)-1])
+
+		if err != nil {
+			return page, err
+		}
+	}
+
+	return page, nil
+}
+
+// EntityRoleListPaged returns a page of role entity mappings together with the total count
+// and the pagination details, based on the offset and limit of the query.
+// The query is cloned, so the caller's query is not modified.
+func (store *store) EntityRoleListPaged(ctx context.Context, query EntityRoleQueryInterface) (PagedResult[EntityRoleInterface], error) {
+	if query == nil {
+		return PagedResult[EntityRoleInterface]{}, errors.New("at entityRole list paged > entityRole query is nil")
+	}
+
+	if err := query.Validate(); err != nil {
+		return PagedResult[EntityRoleInterface]{}, err
+	}
+
+	list, err := store.EntityRoleList(ctx, query.Clone())
+
+	if err != nil {
+		return PagedResult[EntityRoleInterface]{}, err
+	}
+
+	total, err := store.EntityRoleCount(ctx, query.Clone())
+
+	if err != nil {
+		return PagedResult[EntityRoleInterface]{}, err
+	}
+
+	return newPagedResult(list, total, query.Offset(), query.Limit()), nil
+}
+
+// EntityRoleWithRole is a role entity mapping together with its role
+type EntityRoleWithRole struct {
+	EntityRole EntityRoleInterface
+	Role       RoleInterface
+}
+
+// roleColumnAlias is the alias prefix of the role columns in joined entity role queries
+const roleColumnAlias = "rolestore_role__"
+
+// EntityRoleListWithRole returns a list of role entity mappings, each together
+// with its role, loaded with a single join query. Mappings of missing (or soft
+// deleted, unless requested) roles are skipped.
+func (store *store) EntityRoleListWithRole(ctx context.Context, query EntityRoleQueryInterface) ([]EntityRoleWithRole, error) {
+	if query == nil {
+		return []EntityRoleWithRole{}, errors.New("at entityRole list with role > entityRole query is nil")
+	}
+
+	q, columns, err := store.entityRoleSelectQuery(query, true)
+
+	if err != nil {
+		return []EntityRoleWithRole{}, err
+	}
+
+	for _, column := range roleColumns {
+		columns = append(columns, goqu.T(store.roleTableName).Col(column).As(roleColumnAlias+column))
+	}
+
+	sqlStr, sqlParams, errSql := q.Prepared(true).Select(columns...).ToSQL()
+
+	if errSql != nil {
+		return []EntityRoleWithRole{}, errSql
+	}
+
+	store.logSql("select", sqlStr, sqlParams...)
+
+	if store.db == nil {
+		return []EntityRoleWithRole{}, errors.New("entityRolestore: database is nil")
+	}
+
+	modelMaps, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, sqlParams...)
+
+	if err != nil {
+		return []EntityRoleWithRole{}, err
+	}
+
+	list := []EntityRoleWithRole{}
+
+	for _, modelMap := range modelMaps {
+		roleMap := map[string]string{}
+
+		for key, value := range modelMap {
+			if column, found := strings.CutPrefix(key, roleColumnAlias); found {
+				roleMap[column] = value
+				delete(modelMap, key)
+			}
+		}
+
+		list = append(list, EntityRoleWithRole{
+			EntityRole: NewEntityRoleFromExistingData(modelMap),
+			Role:       NewRoleFromExistingData(roleMap),
+		})
+	}
+
+	return list, nil
+}
+
 func (store *store) EntityRoleSoftDelete(ctx context.Context, entityRole EntityRoleInterface) error {
 	if entityRole == nil {
 		return errors.New("at entityRole soft delete > entityRole is nil")
@@ -266,17 +516,93 @@ func (store *store) EntityRoleUpdate(ctx context.Context, entityRole EntityRoleI
 
 	dataChanged := entityRole.DataChanged()
 
-	delete(dataChanged, COLUMN_ID) // ID is not updateable
+	delete(dataChanged, COLUMN_ID)      // ID is not updateable
+	delete(dataChanged, COLUMN_VERSION) // version is managed by the store
 
 	if len(dataChanged) < 1 {
 		return nil
 	}
 
+	provenanceChanged := lo.SomeBy([]string{COLUMN_SOURCE, COLUMN_GRANTED_BY_TYPE, COLUMN_GRANTED_BY_ID}, func(column string) bool {
+		_, changed := dataChanged[column]
+		return changed
+	})
+
+	if provenanceChanged {
+		if err := validateEntityRoleProvenance("EntityRoleUpdate", entityRole); err != nil {
+			return err
+		}
+	}
+
+	// the checks and the update run in one transaction, as on create
+	return store.WithTx(ctx, func(txCtx context.Context) error {
+		return store.entityRoleUpdate(txCtx, entityRole, dataChanged)
+	})
+}
+
+// entityRoleUpdate checks and updates the changed columns of a role entity
+// mapping, it is run within the transaction started by EntityRoleUpdate.
+// The constraints are checked, when the mapping is moved to another entity
+// or role, or restored from soft deleted
+func (store *store) entityRoleUpdate(ctx context.Context, entityRole EntityRoleInterface, dataChanged map[string]string) error {
+	_, entityTypeChanged := dataChanged[COLUMN_ENTITY_TYPE]
+	_, entityIDChanged := dataChanged[COLUMN_ENTITY_ID]
+	_, roleIDChanged := dataChanged[COLUMN_ROLE_ID]
+	_, softDeletedAtChanged := dataChanged[COLUMN_SOFT_DELETED_AT]
+
+	moved := entityTypeChanged || entityIDChanged || roleIDChanged
+
+	// the mapping as stored, before it is moved, soft deleted or restored
+	var previous EntityRoleInterface
+
+	if moved || softDeletedAtChanged {
+		// locked before any read, see sodLockForUpdate
+		if err := store.sodLockForUpdate(ctx, entityRole.RoleID()); err != nil {
+			return err
+		}
+
+		var err error
+		previous, err = store.entityRoleFindByIDSoftDeletedIncluded(ctx, entityRole.ID())
+
+		if err != nil {
+			return err
+		}
+	}
+
+	restored := softDeletedAtChanged &&
+		!entityRole.IsSoftDeleted() &&
+		previous != nil &&
+		previous.IsSoftDeleted()
+
+	if moved {
+		if err := store.validateEntityRole(ctx, entityRole); err != nil {
+			return err
+		}
+	}
+
+	if (moved || restored) && !entityRole.IsSoftDeleted() {
+		err := store.sodCheck(ctx, entityRole.EntityType(), entityRole.EntityID(), entityRole.RoleID(), entityRole.ID())
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if (roleIDChanged || restored) && !entityRole.IsSoftDeleted() {
+		if err := store.roleHoldersCheck(ctx, entityRole.RoleID()); err != nil {
+			return err
+		}
+	}
+
+	version := entityRole.Version()
+	dataChanged[COLUMN_VERSION] = cast.ToString(version + 1)
+
 	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
 		Update(store.entityRoleTableName).
 		Prepared(true).
 		Set(dataChanged).
 		Where(goqu.C(COLUMN_ID).Eq(entityRole.ID())).
+		Where(goqu.C(COLUMN_VERSION).Eq(version)).
 		ToSQL()
 
 	if errSql != nil {
@@ -289,14 +615,58 @@ func (store *store) EntityRoleUpdate(ctx context.Context, entityRole EntityRoleI
 		return errors.New("entityRolestore: database is nil")
 	}
 
-	_, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+	result, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
 
+	if err != nil {
+		return err
+	}
+
+	if affected < 1 {
+		return ErrConflict
+	}
+
+	entityRole.SetVersion(version + 1)
 	entityRole.MarkAsNotDirty()
 
-	return err
+	if previous != nil && (moved || entityRole.IsSoftDeleted()) {
+		// the delegations of a role the previous holder lost are revoked with it
+		delegator := NewEntityRef(previous.EntityType(), previous.EntityID())
+		return store.delegationRevokeByDelegator(ctx, delegator, previous.RoleID())
+	}
+
+	return nil
+}
+
+// entityRoleFindByIDSoftDeletedIncluded returns a role entity mapping by ID,
+// also if soft deleted, or nil if not found
+func (store *store) entityRoleFindByIDSoftDeletedIncluded(ctx context.Context, id string) (EntityRoleInterface, error) {
+	list, err := store.EntityRoleList(ctx, NewEntityRoleQuery().
+		SetID(id).
+		SetSoftDeletedIncluded(true).
+		SetLimit(1))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) > 0 {
+		return list[0], nil
+	}
+
+	return nil, nil
 }
 
-func (store *store) entityRoleSelectQuery(options EntityRoleQueryInterface) (selectDataset *goqu.SelectDataset, columns []any, err error) {
+// entityRoleSelectQuery returns the select dataset and the columns for the query.
+// The role table is joined, if the query filters by role properties, or if
+// joinRole is true (i.e. to select the role columns too). All the columns are
+// qualified by the table name, so that they are not ambiguous with a join
+func (store *store) entityRoleSelectQuery(options EntityRoleQueryInterface, joinRole bool) (selectDataset *goqu.SelectDataset, columns []any, err error) {
 	if options == nil {
 		return nil, nil, errors.New("entityRole options is nil")
 	}
@@ -305,37 +675,155 @@ func (store *store) entityRoleSelectQuery(options EntityRoleQueryInterface) (sel
 		return nil, nil, err
 	}
 
-	q := goqu.Dialect(store.dbDriverName).From(store.entityRoleTableName)
+	if err := store.validateEntityRoleQuery(options); err != nil {
+		return nil, nil, err
+	}
+
+	entityRoleTable := goqu.T(store.entityRoleTableName)
+	roleTable := goqu.T(store.roleTableName)
+
+	q := goqu.Dialect(store.dbDriverName).From(entityRoleTable)
+
+	joinRole = joinRole || options.HasRoleHandle() || options.HasRoleStatus()
+
+	if joinRole {
+		q = q.InnerJoin(roleTable, goqu.On(roleTable.Col(COLUMN_ID).Eq(entityRoleTable.Col(COLUMN_ROLE_ID))))
+
+		if !options.SoftDeletedIncluded() {
+			q = q.Where(roleTable.Col(COLUMN_SOFT_DELETED_AT).Gt(carbon.Now(carbon.UTC).ToDateTimeString()))
+		}
+	}
+
+	if options.HasRoleHandle() {
+		q = q.Where(roleTable.Col(COLUMN_HANDLE).Eq(options.RoleHandle()))
+	}
+
+	if options.HasRoleStatus() {
+		q = q.Where(roleTable.Col(COLUMN_STATUS).Eq(options.RoleStatus()))
+	}
 
 	if options.HasEntityID() {
-		q = q.Where(goqu.C(COLUMN_ENTITY_ID).Eq(options.EntityID()))
+		q = q.Where(entityRoleTable.Col(COLUMN_ENTITY_ID).Eq(options.EntityID()))
+	}
+
+	if options.HasEntityIDIn() {
+		q = q.Where(entityRoleTable.Col(COLUMN_ENTITY_ID).In(options.EntityIDIn()))
+	}
+
+	if options.HasEntityIDNotIn() {
+		q = q.Where(entityRoleTable.Col(COLUMN_ENTITY_ID).NotIn(options.EntityIDNotIn()))
 	}
 
 	if options.HasEntityType() {
-		q = q.Where(goqu.C(COLUMN_ENTITY_TYPE).Eq(options.EntityType()))
+		q = q.Where(entityRoleTable.Col(COLUMN_ENTITY_TYPE).Eq(options.EntityType()))
+	}
+
+	if options.HasEntityTypeIn() {
+		q = q.Where(entityRoleTable.Col(COLUMN_ENTITY_TYPE).In(options.EntityTypeIn()))
+	}
+
+	if options.HasEntityTypeNotIn() {
+		q = q.Where(entityRoleTable.Col(COLUMN_ENTITY_TYPE).NotIn(options.EntityTypeNotIn()))
+	}
+
+	if options.HasGrantedByID() {
+		q = q.Where(entityRoleTable.Col(COLUMN_GRANTED_BY_ID).Eq(options.GrantedByID()))
+	}
+
+	if options.HasGrantedByType() {
+		q = q.Where(entityRoleTable.Col(COLUMN_GRANTED_BY_TYPE).Eq(options.GrantedByType()))
 	}
 
 	if options.HasID() {
-		q = q.Where(goqu.C(COLUMN_ID).Eq(options.ID()))
+		q = q.Where(entityRoleTable.Col(COLUMN_ID).Eq(options.ID()))
 	}
 
 	if options.HasIDIn() {
-		q = q.Where(goqu.C(COLUMN_ID).In(options.IDIn()))
+		q = q.Where(entityRoleTable.Col(COLUMN_ID).In(options.IDIn()))
 	}
 
 	if options.HasRoleID() {
-		q = q.Where(goqu.C(COLUMN_ROLE_ID).Eq(options.RoleID()))
+		q = q.Where(entityRoleTable.Col(COLUMN_ROLE_ID).Eq(options.RoleID()))
+	}
+
+	if options.HasRoleIDIn() {
+		q = q.Where(entityRoleTable.Col(COLUMN_ROLE_ID).In(options.RoleIDIn()))
+	}
+
+	if options.HasRoleIDNotIn() {
+		q = q.Where(entityRoleTable.Col(COLUMN_ROLE_ID).NotIn(options.RoleIDNotIn()))
+	}
+
+	if options.HasSource() {
+		q = q.Where(entityRoleTable.Col(COLUMN_SOURCE).Eq(options.Source()))
+	}
+
+	if options.HasSourceIn() {
+		q = q.Where(entityRoleTable.Col(COLUMN_SOURCE).In(options.SourceIn()))
 	}
 
 	if options.HasCreatedAtGte() && options.HasCreatedAtLte() {
 		q = q.Where(
-			goqu.C(COLUMN_CREATED_AT).Gte(options.CreatedAtGte()),
-			goqu.C(COLUMN_CREATED_AT).Lte(options.CreatedAtLte()),
+			entityRoleTable.Col(COLUMN_CREATED_AT).Gte(options.CreatedAtGte()),
+			entityRoleTable.Col(COLUMN_CREATED_AT).Lte(options.CreatedAtLte()),
 		)
 	} else if options.HasCreatedAtGte() {
-		q = q.Where(goqu.C(COLUMN_CREATED_AT).Gte(options.CreatedAtGte()))
+		q = q.Where(entityRoleTable.Col(COLUMN_CREATED_AT).Gte(options.CreatedAtGte()))
 	} else if options.HasCreatedAtLte() {
-		q = q.Where(goqu.C(COLUMN_CREATED_AT).Lte(options.CreatedAtLte()))
+		q = q.Where(entityRoleTable.Col(COLUMN_CREATED_AT).Lte(options.CreatedAtLte()))
+	}
+
+	if options.HasUpdatedAtGte() && options.HasUpdatedAtLte() {
+		q = q.Where(
+			entityRoleTable.Col(COLUMN_UPDATED_AT).Gte(options.UpdatedAtGte()),
+			entityRoleTable.Col(COLUMN_UPDATED_AT).Lte(options.UpdatedAtLte()),
+		)
+	} else if options.HasUpdatedAtGte() {
+		q = q.Where(entityRoleTable.Col(COLUMN_UPDATED_AT).Gte(options.UpdatedAtGte()))
+	} else if options.HasUpdatedAtLte() {
+		q = q.Where(entityRoleTable.Col(COLUMN_UPDATED_AT).Lte(options.UpdatedAtLte()))
+	}
+
+	// soft deleted at ranges are mostly useful together with SetSoftDeletedIncluded(true),
+	// as otherwise only the not yet soft deleted entity roles are considered
+	if options.HasSoftDeletedAtGte() && options.HasSoftDeletedAtLte() {
+		q = q.Where(
+			entityRoleTable.Col(COLUMN_SOFT_DELETED_AT).Gte(options.SoftDeletedAtGte()),
+			entityRoleTable.Col(COLUMN_SOFT_DELETED_AT).Lte(options.SoftDeletedAtLte()),
+		)
+	} else if options.HasSoftDeletedAtGte() {
+		q = q.Where(entityRoleTable.Col(COLUMN_SOFT_DELETED_AT).Gte(options.SoftDeletedAtGte()))
+	} else if options.HasSoftDeletedAtLte() {
+		q = q.Where(entityRoleTable.Col(COLUMN_SOFT_DELETED_AT).Lte(options.SoftDeletedAtLte()))
+	}
+
+	metaKeys := slices.Sorted(maps.Keys(options.MetaEquals())) // sorted, for a stable SQL
+
+	for _, key := range metaKeys {
+		q = q.Where(store.metaExpression(entityRoleTable.Col(COLUMN_METAS), key).Eq(options.MetaEquals()[key]))
+	}
+
+	for _, key := range options.MetaExists() {
+		q = q.Where(store.metaExpression(entityRoleTable.Col(COLUMN_METAS), key).IsNotNull())
+	}
+
+	orderBys := queryOrderBys(options.OrderBys(), options.OrderBy(), options.SortDirection())
+	keys := cursorKeys(orderBys, options.SortDirection())
+
+	if options.HasCursor() {
+		cursor, err := cursorDecode(options.Cursor())
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		condition, err := cursorCondition(keys, cursor, store.entityRoleTableName)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		q = q.Where(condition)
 	}
 
 	if !options.IsCountOnly() {
@@ -348,26 +836,27 @@ func (store *store) entityRoleSelectQuery(options EntityRoleQueryInterface) (sel
 		}
 	}
 
-	if options.HasOrderBy() {
-		sort := lo.Ternary(options.HasSortDirection(), options.SortDirection(), sb.DESC)
-		if strings.EqualFold(sort, sb.ASC) {
-			q = q.Order(goqu.I(options.OrderBy()).Asc())
-		} else {
-			q = q.Order(goqu.I(options.OrderBy()).Desc())
-		}
+	if options.HasCursor() {
+		q = q.Order(cursorOrder(keys, store.entityRoleTableName)...)
+	} else if len(orderBys) > 0 {
+		q = q.Order(orderExpressions(orderBys, store.entityRoleTableName)...)
 	}
 
 	columns = []any{}
 
 	for _, column := range options.Columns() {
-		columns = append(columns, column)
+		columns = append(columns, entityRoleTable.Col(column))
+	}
+
+	if joinRole && len(columns) == 0 {
+		columns = append(columns, entityRoleTable.All()) // only the entity role columns
 	}
 
 	if options.SoftDeletedIncluded() {
 		return q, columns, nil // soft deleted entityRoles requested specifically
 	}
 
-	softDeleted := goqu.C(COLUMN_SOFT_DELETED_AT).
+	softDeleted := entityRoleTable.Col(COLUMN_SOFT_DELETED_AT).
 		Gt(carbon.Now(carbon.UTC).ToDateTimeString())
 
 	return q.Where(softDeleted), columns, nil
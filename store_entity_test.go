@@ -2,6 +2,7 @@ package rolestore
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
 
@@ -570,3 +571,598 @@ func TestStoreEntityRoleSoftDeleteByID(t *testing.T) {
 		t.Fatal("EntityRole MUST be soft deleted")
 	}
 }
+
+func TestStoreEntityRoleUpdate_Conflict(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	entityRole := NewEntityRole().
+		SetEntityType("USER").
+		SetEntityID("USER_01").
+		SetRoleID("ROLE_01")
+
+	err = store.EntityRoleCreate(context.Background(), entityRole)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	entityRole1, err := store.EntityRoleFindByID(context.Background(), entityRole.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	entityRole2, err := store.EntityRoleFindByID(context.Background(), entityRole.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.EntityRoleUpdate(context.Background(), entityRole1.SetMemo("MEMO_1"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if entityRole1.Version() != 2 {
+		t.Fatal("Version MUST be 2, found:", entityRole1.Version())
+	}
+
+	err = store.EntityRoleUpdate(context.Background(), entityRole2.SetMemo("MEMO_2"))
+
+	if !errors.Is(err, ErrConflict) {
+		t.Fatal("must return ErrConflict as entity role was modified concurrently, found:", err)
+	}
+}
+
+func TestStoreEntityRoleListByCursor(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	entityIDs := []string{"USER_01", "USER_02", "USER_03", "USER_04", "USER_05"}
+
+	for _, entityID := range entityIDs {
+		err = store.EntityRoleCreate(context.Background(), NewEntityRole().
+			SetEntityType("USER").
+			SetEntityID(entityID).
+			SetRoleID("ROLE_01"))
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	query := NewEntityRoleQuery().
+		SetRoleID("ROLE_01").
+		SetOrderBy(COLUMN_ENTITY_ID).
+		SetSortDirection(sb.DESC).
+		SetLimit(3)
+
+	found := []string{}
+
+	for {
+		page, err := store.EntityRoleListByCursor(context.Background(), query)
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		for _, entityRole := range page.Items {
+			found = append(found, entityRole.EntityID())
+		}
+
+		if !page.HasMore {
+			break
+		}
+
+		query.SetCursor(page.NextCursor)
+	}
+
+	if strings.Join(found, ",") != "USER_05,USER_04,USER_03,USER_02,USER_01" {
+		t.Fatal("unexpected entity IDs:", found)
+	}
+}
+
+func TestStoreEntityRoleListPaged(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	for _, entityID := range []string{"USER_01", "USER_02", "USER_03"} {
+		err = store.EntityRoleCreate(context.Background(), NewEntityRole().
+			SetEntityType("USER").
+			SetEntityID(entityID).
+			SetRoleID("ROLE_01"))
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	query := NewEntityRoleQuery().SetRoleID("ROLE_01")
+
+	result, err := store.EntityRoleListPaged(context.Background(), query)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(result.Items) != 3 || result.Total != 3 {
+		t.Fatal("unexpected result:", len(result.Items), result.Total)
+	}
+
+	if result.Page != 1 || result.PerPage != 3 || result.TotalPages != 1 {
+		t.Fatal("unexpected pagination:", result.Page, result.PerPage, result.TotalPages)
+	}
+
+	if query.HasCountOnly() {
+		t.Fatal("query MUST NOT be modified")
+	}
+}
+
+func TestStoreEntityRoleList_InFilters(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	entityRoles := []EntityRoleInterface{
+		NewEntityRole().SetEntityType("USER").SetEntityID("USER_01").SetRoleID("ROLE_01"),
+		NewEntityRole().SetEntityType("USER").SetEntityID("USER_02").SetRoleID("ROLE_02"),
+		NewEntityRole().SetEntityType("USER").SetEntityID("USER_03").SetRoleID("ROLE_03"),
+		NewEntityRole().SetEntityType("GROUP").SetEntityID("GROUP_01").SetRoleID("ROLE_01"),
+	}
+
+	for _, entityRole := range entityRoles {
+		err = store.EntityRoleCreate(context.Background(), entityRole)
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query EntityRoleQueryInterface
+		count int
+	}{
+		{"entity_id_in", NewEntityRoleQuery().SetEntityIDIn([]string{"USER_01", "USER_02"}), 2},
+		{"entity_id_not_in", NewEntityRoleQuery().SetEntityIDNotIn([]string{"USER_01", "USER_02"}), 2},
+		{"entity_type_in", NewEntityRoleQuery().SetEntityTypeIn([]string{"GROUP"}), 1},
+		{"entity_type_not_in", NewEntityRoleQuery().SetEntityTypeNotIn([]string{"GROUP"}), 3},
+		{"role_id_in", NewEntityRoleQuery().SetRoleIDIn([]string{"ROLE_01", "ROLE_03"}), 3},
+		{"role_id_not_in", NewEntityRoleQuery().SetRoleIDNotIn([]string{"ROLE_01"}), 2},
+		{"updated_at_lte", NewEntityRoleQuery().SetUpdatedAtLte(sb.MAX_DATETIME), 4},
+		{"updated_at_gte", NewEntityRoleQuery().SetUpdatedAtGte(sb.MAX_DATETIME), 0},
+	}
+
+	for _, test := range tests {
+		list, err := store.EntityRoleList(context.Background(), test.query)
+
+		if err != nil {
+			t.Fatal(test.name, "unexpected error:", err)
+		}
+
+		if len(list) != test.count {
+			t.Fatal(test.name, "unexpected list length:", len(list))
+		}
+	}
+
+	if err := NewEntityRoleQuery().SetRoleIDIn([]string{}).Validate(); err == nil {
+		t.Fatal("must return error as role_id_in is empty")
+	}
+}
+
+func TestStoreEntityRoleList_OrderBys(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	entityRoles := []EntityRoleInterface{
+		NewEntityRole().SetEntityType("USER").SetEntityID("USER_01").SetRoleID("ROLE_02"),
+		NewEntityRole().SetEntityType("USER").SetEntityID("USER_01").SetRoleID("ROLE_01"),
+		NewEntityRole().SetEntityType("GROUP").SetEntityID("GROUP_01").SetRoleID("ROLE_01"),
+	}
+
+	for _, entityRole := range entityRoles {
+		err = store.EntityRoleCreate(context.Background(), entityRole)
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	list, err := store.EntityRoleList(context.Background(), NewEntityRoleQuery().SetOrderBys([]OrderBy{
+		{COLUMN_ENTITY_TYPE, DESC},
+		{COLUMN_ROLE_ID, ASC},
+	}))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	found := []string{}
+
+	for _, entityRole := range list {
+		found = append(found, entityRole.EntityID()+":"+entityRole.RoleID())
+	}
+
+	if strings.Join(found, ",") != "USER_01:ROLE_01,USER_01:ROLE_02,GROUP_01:ROLE_01" {
+		t.Fatal("unexpected order:", found)
+	}
+
+	_, err = store.EntityRoleList(context.Background(), NewEntityRoleQuery().
+		SetOrderBy(COLUMN_ENTITY_ID).
+		SetSortDirection("up"))
+
+	if err == nil {
+		t.Fatal("must return error as sort_direction is not valid")
+	}
+}
+
+func TestStoreEntityRoleList_Metas(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	metas := map[string]map[string]string{
+		"USER_01": {"granted_via": "sso"},
+		"USER_02": {"granted_via": "manual"},
+		"USER_03": {"granted_via": "sso"},
+		"USER_04": {},
+	}
+
+	for entityID, meta := range metas {
+		entityRole := NewEntityRole().
+			SetEntityType("USER").
+			SetEntityID(entityID).
+			SetRoleID("ROLE_01")
+
+		if err := entityRole.SetMetas(meta); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if err := store.EntityRoleCreate(context.Background(), entityRole); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	list, err := store.EntityRoleList(context.Background(), NewEntityRoleQuery().
+		SetMetaEquals("granted_via", "manual"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(list) != 1 || list[0].EntityID() != "USER_02" {
+		t.Fatal("unexpected list:", list)
+	}
+
+	count, err := store.EntityRoleCount(context.Background(), NewEntityRoleQuery().
+		SetMetaEquals("granted_via", "sso"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 2 {
+		t.Fatal("unexpected count:", count)
+	}
+
+	count, err = store.EntityRoleCount(context.Background(), NewEntityRoleQuery().
+		SetMetaExists("granted_via"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 3 {
+		t.Fatal("unexpected count:", count)
+	}
+}
+
+func TestStoreEntityRoleListWithRole(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	roleAdmin := NewRole().SetStatus(ROLE_STATUS_ACTIVE).SetHandle("admin").SetTitle("Admin")
+	roleGuest := NewRole().SetStatus(ROLE_STATUS_INACTIVE).SetHandle("guest").SetTitle("Guest")
+
+	for _, role := range []RoleInterface{roleAdmin, roleGuest} {
+		if err := store.RoleCreate(context.Background(), role); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	entityRoles := []EntityRoleInterface{
+		NewEntityRole().SetEntityType("USER").SetEntityID("USER_01").SetRoleID(roleAdmin.ID()),
+		NewEntityRole().SetEntityType("USER").SetEntityID("USER_02").SetRoleID(roleAdmin.ID()),
+		NewEntityRole().SetEntityType("USER").SetEntityID("USER_02").SetRoleID(roleGuest.ID()),
+		NewEntityRole().SetEntityType("USER").SetEntityID("USER_03").SetRoleID("ROLE_MISSING"),
+	}
+
+	for _, entityRole := range entityRoles {
+		if err := store.EntityRoleCreate(context.Background(), entityRole); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	list, err := store.EntityRoleListWithRole(context.Background(), NewEntityRoleQuery().
+		SetOrderBys([]OrderBy{{COLUMN_ENTITY_ID, ASC}, {COLUMN_CREATED_AT, ASC}}))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(list) != 3 {
+		t.Fatal("unexpected list length, the missing role MUST be skipped:", len(list))
+	}
+
+	if list[0].EntityRole.ID() != entityRoles[0].ID() || list[0].Role.Handle() != "admin" {
+		t.Fatal("unexpected first item:", list[0].EntityRole.Data(), list[0].Role.Data())
+	}
+
+	if list[0].EntityRole.RoleID() != list[0].Role.ID() {
+		t.Fatal("role MUST match the entity role")
+	}
+
+	list, err = store.EntityRoleListWithRole(context.Background(), NewEntityRoleQuery().
+		SetEntityID("USER_02").
+		SetRoleHandle("guest"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(list) != 1 || list[0].Role.ID() != roleGuest.ID() {
+		t.Fatal("unexpected list:", list)
+	}
+
+	count, err := store.EntityRoleCount(context.Background(), NewEntityRoleQuery().
+		SetRoleStatus(ROLE_STATUS_ACTIVE))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 2 {
+		t.Fatal("unexpected count:", count)
+	}
+
+	page, err := store.EntityRoleListByCursor(context.Background(), NewEntityRoleQuery().
+		SetRoleHandle("admin").
+		SetColumns([]string{COLUMN_ENTITY_ID}).
+		SetLimit(1))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(page.Items) != 1 || !page.HasMore {
+		t.Fatal("unexpected page:", page)
+	}
+}
+
+func TestStoreEntityRoleCreate_EntityTypeRegistry(t *testing.T) {
+	db, err := initDB(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		DB:                  db,
+		RoleTableName:       "roles_role_table",
+		EntityRoleTableName: "roles_entity_role_table",
+		AutomigrateEnabled:  true,
+		EntityTypes: map[string]EntityIDValidator{
+			"user": func(entityID string) error {
+				if !strings.HasPrefix(entityID, "USER_") {
+					return errors.New("must start with USER_")
+				}
+				return nil
+			},
+			"group": nil,
+		},
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	err = store.EntityRoleCreate(context.Background(), NewEntityRole().
+		SetEntityType("user").
+		SetEntityID("USER_01").
+		SetRoleID("ROLE_01"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.EntityRoleCreate(context.Background(), NewEntityRole().
+		SetEntityType("group").
+		SetEntityID("anything").
+		SetRoleID("ROLE_01"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.EntityRoleCreate(context.Background(), NewEntityRole().
+		SetEntityType("usr").
+		SetEntityID("USER_01").
+		SetRoleID("ROLE_01"))
+
+	if !errors.Is(err, ErrEntityTypeUnknown) {
+		t.Fatal("error MUST be ErrEntityTypeUnknown, found:", err)
+	}
+
+	err = store.EntityRoleCreate(context.Background(), NewEntityRole().
+		SetEntityType("user").
+		SetEntityID("01").
+		SetRoleID("ROLE_01"))
+
+	if err == nil {
+		t.Fatal("error MUST NOT be nil for an invalid entity ID")
+	}
+
+	_, err = store.EntityRoleList(context.Background(), NewEntityRoleQuery().SetEntityType("usr"))
+
+	if !errors.Is(err, ErrEntityTypeUnknown) {
+		t.Fatal("list error MUST be ErrEntityTypeUnknown, found:", err)
+	}
+
+	_, err = store.EntityRoleList(context.Background(), NewEntityRoleQuery().SetEntityTypeIn([]string{"user", "usr"}))
+
+	if !errors.Is(err, ErrEntityTypeUnknown) {
+		t.Fatal("list error MUST be ErrEntityTypeUnknown, found:", err)
+	}
+
+	list, err := store.EntityRoleList(context.Background(), NewEntityRoleQuery().SetEntityTypeIn([]string{"user", "group"}))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(list) != 2 {
+		t.Fatal("unexpected entity roles length:", len(list))
+	}
+
+	_, err = store.EntitiesRoles(context.Background(), "usr", []string{"USER_01"})
+
+	if !errors.Is(err, ErrEntityTypeUnknown) {
+		t.Fatal("lookup error MUST be ErrEntityTypeUnknown, found:", err)
+	}
+
+	if _, err = store.EntitiesRoles(context.Background(), "user", []string{"USER_01", "01"}); err == nil {
+		t.Fatal("lookup error MUST NOT be nil for an invalid entity ID")
+	}
+}
+
+func TestStoreEntityRoleCreate_RoleEntityTypes(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	role := NewRole().SetStatus(ROLE_STATUS_ACTIVE).SetHandle("admin").SetTitle("Admin")
+
+	if err := role.SetEntityTypes([]string{"user"}); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := role.SetEntityTypes([]string{"user,group"}); err == nil {
+		t.Fatal("error MUST NOT be nil for an entity type with a comma")
+	}
+
+	if err := role.SetEntityTypes([]string{""}); err == nil {
+		t.Fatal("error MUST NOT be nil for an empty entity type")
+	}
+
+	if types := role.EntityTypes(); len(types) != 1 || types[0] != "user" {
+		t.Fatal("rejected entity types MUST NOT be stored, found:", types)
+	}
+
+	if err := store.RoleCreate(context.Background(), role); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.EntityRoleCreate(context.Background(), NewEntityRole().
+		SetEntityType("user").
+		SetEntityID("USER_01").
+		SetRoleID(role.ID()))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	entityRole := NewEntityRole().
+		SetEntityType("group").
+		SetEntityID("GROUP_01").
+		SetRoleID(role.ID())
+
+	err = store.EntityRoleCreate(context.Background(), entityRole)
+
+	if !errors.Is(err, ErrEntityTypeNotAllowed) {
+		t.Fatal("error MUST be ErrEntityTypeNotAllowed, found:", err)
+	}
+
+	// moving an assignment to a disallowed entity type is rejected too
+	_, err = store.EntityTransferRoles(context.Background(), NewEntityRef("user", "USER_01"), NewEntityRef("group", "GROUP_01"))
+
+	if !errors.Is(err, ErrEntityTypeNotAllowed) {
+		t.Fatal("transfer error MUST be ErrEntityTypeNotAllowed, found:", err)
+	}
+}
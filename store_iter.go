@@ -0,0 +1,166 @@
+package rolestore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"iter"
+
+	"github.com/gouniverse/base/database"
+	"github.com/gouniverse/maputils"
+)
+
+// RoleIter streams the roles matching the query, one row at a time,
+// so memory stays bounded regardless of the number of roles.
+//
+// The rows are read from an open cursor, which holds a database connection
+// until the loop ends. The first error stops the iteration and is yielded
+// with a nil role. Breaking out of the loop early closes the cursor.
+//
+// Example:
+//
+//	for role, err := range store.RoleIter(ctx, NewRoleQuery()) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func (store *store) RoleIter(ctx context.Context, query RoleQueryInterface) iter.Seq2[RoleInterface, error] {
+	return func(yield func(RoleInterface, error) bool) {
+		if query == nil {
+			yield(nil, errors.New("at role iter > role query is nil"))
+			return
+		}
+
+		q, columns, err := store.roleSelectQuery(query)
+
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		sqlStr, sqlParams, errSql := q.Prepared(true).Select(columns...).ToSQL()
+
+		if errSql != nil {
+			yield(nil, errSql)
+			return
+		}
+
+		for row, err := range store.iterRows(ctx, sqlStr, sqlParams...) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			if !yield(NewRoleFromExistingData(row), nil) {
+				return
+			}
+		}
+	}
+}
+
+// EntityRoleIter streams the role entity mappings matching the query,
+// one row at a time, so memory stays bounded regardless of the number
+// of assignments. It behaves like RoleIter.
+func (store *store) EntityRoleIter(ctx context.Context, query EntityRoleQueryInterface) iter.Seq2[EntityRoleInterface, error] {
+	return func(yield func(EntityRoleInterface, error) bool) {
+		if query == nil {
+			yield(nil, errors.New("at entityRole iter > entityRole query is nil"))
+			return
+		}
+
+		q, columns, err := store.entityRoleSelectQuery(query, false)
+
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		sqlStr, sqlParams, errSql := q.Prepared(true).Select(columns...).ToSQL()
+
+		if errSql != nil {
+			yield(nil, errSql)
+			return
+		}
+
+		for row, err := range store.iterRows(ctx, sqlStr, sqlParams...) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			if !yield(NewEntityRoleFromExistingData(row), nil) {
+				return
+			}
+		}
+	}
+}
+
+// iterRows runs the select and yields each row as a string map,
+// converted the same way as database.SelectToMapString does.
+// The rows are closed when the iteration ends.
+func (store *store) iterRows(ctx context.Context, sqlStr string, sqlParams ...any) iter.Seq2[map[string]string, error] {
+	return func(yield func(map[string]string, error) bool) {
+		store.logSql("select", sqlStr, sqlParams...)
+
+		if store.db == nil {
+			yield(nil, errors.New("rolestore: database is nil"))
+			return
+		}
+
+		rows, err := database.Query(store.toQuerableContext(ctx), sqlStr, sqlParams...)
+
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		defer rows.Close()
+
+		columns, err := rows.Columns()
+
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		for rows.Next() {
+			row, err := scanRowToMap(rows, columns)
+
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			if !yield(row, nil) {
+				return
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// scanRowToMap scans the current row into a string map keyed by column name
+func scanRowToMap(rows *sql.Rows, columns []string) (map[string]string, error) {
+	values := make([]any, len(columns))
+	pointers := make([]any, len(columns))
+
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+
+	if err := rows.Scan(pointers...); err != nil {
+		return nil, err
+	}
+
+	row := make(map[string]any, len(columns))
+
+	for i, column := range columns {
+		row[column] = values[i]
+	}
+
+	return maputils.MapStringAnyToMapStringString(row), nil
+}
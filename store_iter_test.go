@@ -0,0 +1,154 @@
+package rolestore
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/gouniverse/sb"
+)
+
+func TestStoreRoleIter(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	for i := 1; i <= 5; i++ {
+		handle := "ROLE_" + strconv.Itoa(i)
+
+		err = store.RoleCreate(context.Background(), NewRole().
+			SetStatus(ROLE_STATUS_ACTIVE).
+			SetHandle(handle).
+			SetTitle(handle))
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	query := NewRoleQuery().
+		SetOrderBy(COLUMN_HANDLE).
+		SetSortDirection(sb.ASC)
+
+	handles := []string{}
+
+	for role, err := range store.RoleIter(context.Background(), query) {
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		handles = append(handles, role.Handle())
+	}
+
+	if len(handles) != 5 {
+		t.Fatal("unexpected roles length:", len(handles))
+	}
+
+	if handles[0] != "ROLE_1" || handles[4] != "ROLE_5" {
+		t.Fatal("unexpected order:", handles)
+	}
+
+	// breaking early must release the connection
+	count := 0
+
+	for _, err := range store.RoleIter(context.Background(), query) {
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		count++
+
+		if count == 2 {
+			break
+		}
+	}
+
+	if count != 2 {
+		t.Fatal("unexpected count:", count)
+	}
+
+	roleCount, err := store.RoleCount(context.Background(), NewRoleQuery())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if roleCount != 5 {
+		t.Fatal("unexpected role count:", roleCount)
+	}
+
+	// errors are yielded
+	errCount := 0
+
+	for role, err := range store.RoleIter(context.Background(), NewRoleQuery().SetOrderBy("unknown")) {
+		if err == nil {
+			t.Fatal("error MUST NOT be nil")
+		}
+
+		if role != nil {
+			t.Fatal("role MUST be nil")
+		}
+
+		errCount++
+	}
+
+	if errCount != 1 {
+		t.Fatal("unexpected error count:", errCount)
+	}
+}
+
+func TestStoreEntityRoleIter(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	for i := 1; i <= 4; i++ {
+		err = store.EntityRoleCreate(context.Background(), NewEntityRole().
+			SetEntityType("USER").
+			SetEntityID("USER_"+strconv.Itoa(i)).
+			SetRoleID("ROLE_01"))
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	query := NewEntityRoleQuery().
+		SetEntityType("USER").
+		SetOrderBy(COLUMN_ENTITY_ID).
+		SetSortDirection(sb.DESC)
+
+	entityIDs := []string{}
+
+	for entityRole, err := range store.EntityRoleIter(context.Background(), query) {
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		entityIDs = append(entityIDs, entityRole.EntityID())
+	}
+
+	if len(entityIDs) != 4 {
+		t.Fatal("unexpected entity roles length:", len(entityIDs))
+	}
+
+	if entityIDs[0] != "USER_4" || entityIDs[3] != "USER_1" {
+		t.Fatal("unexpected order:", entityIDs)
+	}
+}
@@ -0,0 +1,256 @@
+package rolestore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/base/database"
+	"github.com/samber/lo"
+	"github.com/spf13/cast"
+)
+
+// entityIDsChunkSize is the maximum number of entity IDs used in one query,
+// to stay well below the parameter limits of the supported databases
+const entityIDsChunkSize = 500
+
+// entityIDAlias is the alias of the entity ID column in joined role queries
+const entityIDAlias = "rolestore_entity_id"
+
+// RoleEntitiesOptions are the options for listing the entities holding a role
+type RoleEntitiesOptions struct {
+	// EntityType optionally restricts the entities to the given type
+	EntityType string
+
+	// Limit is the maximum number of entities returned, 0 means no limit
+	Limit int
+
+	// Offset is the number of entities skipped
+	Offset int
+}
+
+// EntitiesRoles returns the active roles of each of the given entities,
+// keyed by entity ID. Every requested entity ID is present in the result,
+// with an empty list if the entity has no roles.
+//
+// The roles are loaded with a single join query (per chunk of IDs), skipping
+// soft deleted assignments, soft deleted roles and roles which are not active.
+// The entity type and IDs are checked against the entity type registry.
+func (store *store) EntitiesRoles(ctx context.Context, entityType string, entityIDs []string) (map[string][]RoleInterface, error) {
+	if entityType == "" {
+		return nil, errors.New("rolestore > EntitiesRoles. entityType is empty")
+	}
+
+	entityIDs = lo.Uniq(lo.Compact(entityIDs))
+
+	if err := store.validateEntity(entityType, ""); err != nil {
+		return nil, err
+	}
+
+	for _, entityID := range entityIDs {
+		if err := store.validateEntity(entityType, entityID); err != nil {
+			return nil, err
+		}
+	}
+
+	result := make(map[string][]RoleInterface, len(entityIDs))
+
+	for _, entityID := range entityIDs {
+		result[entityID] = []RoleInterface{}
+	}
+
+	for _, chunk := range lo.Chunk(entityIDs, entityIDsChunkSize) {
+		modelMaps, err := store.entitiesRolesSelect(ctx, entityType, chunk)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, modelMap := range modelMaps {
+			entityID := modelMap[entityIDAlias]
+			delete(modelMap, entityIDAlias)
+			result[entityID] = append(result[entityID], NewRoleFromExistingData(modelMap))
+		}
+	}
+
+	return result, nil
+}
+
+// entitiesRolesSelect selects the active roles of the entities, joined with
+// the entity role table, including the entity ID under the entityIDAlias column
+func (store *store) entitiesRolesSelect(ctx context.Context, entityType string, entityIDs []string) ([]map[string]string, error) {
+	now := carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+
+	roleTable := goqu.T(store.roleTableName)
+	entityRoleTable := goqu.T(store.entityRoleTableName)
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(entityRoleTable).
+		InnerJoin(roleTable, goqu.On(roleTable.Col(COLUMN_ID).Eq(entityRoleTable.Col(COLUMN_ROLE_ID)))).
+		Prepared(true).
+		Select(roleTable.All(), entityRoleTable.Col(COLUMN_ENTITY_ID).As(entityIDAlias)).
+		Where(
+			entityRoleTable.Col(COLUMN_ENTITY_TYPE).Eq(entityType),
+			entityRoleTable.Col(COLUMN_ENTITY_ID).In(entityIDs),
+			entityRoleTable.Col(COLUMN_SOFT_DELETED_AT).Gt(now),
+			roleTable.Col(COLUMN_STATUS).Eq(ROLE_STATUS_ACTIVE),
+			roleTable.Col(COLUMN_SOFT_DELETED_AT).Gt(now),
+		).
+		Order(entityRoleTable.Col(COLUMN_ENTITY_ID).Asc(), roleTable.Col(COLUMN_HANDLE).Asc()).
+		ToSQL()
+
+	if errSql != nil {
+		return nil, errSql
+	}
+
+	store.logSql("select", sqlStr, params...)
+
+	if store.db == nil {
+		return nil, errors.New("rolestore: database is nil")
+	}
+
+	return database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, params...)
+}
+
+// RoleEntities returns the entities holding the role with the given handle,
+// ordered by entity type and entity ID. Soft deleted assignments are skipped.
+// If no role with the handle exists, an empty list is returned. An offset
+// requires a limit, as not all databases support an offset on its own
+func (store *store) RoleEntities(ctx context.Context, handle string, options RoleEntitiesOptions) ([]EntityRef, error) {
+	if options.Offset > 0 && options.Limit == 0 {
+		return []EntityRef{}, errors.New("rolestore > RoleEntities. offset requires a limit")
+	}
+
+	query, err := store.roleEntitiesQuery(ctx, handle, options)
+
+	if err != nil {
+		return []EntityRef{}, err
+	}
+
+	if query == nil {
+		return []EntityRef{}, nil // no such role
+	}
+
+	query.SetOrderBys([]OrderBy{{COLUMN_ENTITY_TYPE, ASC}, {COLUMN_ENTITY_ID, ASC}})
+
+	if options.Limit > 0 {
+		query.SetLimit(options.Limit)
+	}
+
+	if options.Offset > 0 {
+		query.SetOffset(options.Offset)
+	}
+
+	q, err := store.roleEntitiesSelect(query)
+
+	if err != nil {
+		return []EntityRef{}, err
+	}
+
+	sqlStr, params, errSql := q.Prepared(true).ToSQL()
+
+	if errSql != nil {
+		return []EntityRef{}, errSql
+	}
+
+	modelMaps, err := store.selectToMaps(ctx, sqlStr, params...)
+
+	if err != nil {
+		return []EntityRef{}, err
+	}
+
+	return lo.Map(modelMaps, func(modelMap map[string]string, _ int) EntityRef {
+		return NewEntityRef(modelMap[COLUMN_ENTITY_TYPE], modelMap[COLUMN_ENTITY_ID])
+	}), nil
+}
+
+// RoleEntitiesCount returns the number of distinct entities holding the role
+// with the given handle, using the same filters as RoleEntities (limit and
+// offset are ignored)
+func (store *store) RoleEntitiesCount(ctx context.Context, handle string, options RoleEntitiesOptions) (int64, error) {
+	query, err := store.roleEntitiesQuery(ctx, handle, options)
+
+	if err != nil {
+		return -1, err
+	}
+
+	if query == nil {
+		return 0, nil // no such role
+	}
+
+	q, err := store.roleEntitiesSelect(query.SetCountOnly(true))
+
+	if err != nil {
+		return -1, err
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(q.ClearOrder().As("entities")).
+		Prepared(true).
+		Select(goqu.COUNT(goqu.Star()).As("count")).
+		ToSQL()
+
+	if errSql != nil {
+		return -1, errSql
+	}
+
+	modelMaps, err := store.selectToMaps(ctx, sqlStr, params...)
+
+	if err != nil {
+		return -1, err
+	}
+
+	if len(modelMaps) == 0 {
+		return 0, nil
+	}
+
+	return cast.ToInt64(modelMaps[0]["count"]), nil
+}
+
+// roleEntitiesSelect selects the distinct entities of the entity role query,
+// as an entity may hold the role by more than one live assignment
+func (store *store) roleEntitiesSelect(query EntityRoleQueryInterface) (*goqu.SelectDataset, error) {
+	q, _, err := store.entityRoleSelectQuery(query, false)
+
+	if err != nil {
+		return nil, err
+	}
+
+	entityRoleTable := goqu.T(store.entityRoleTableName)
+
+	return q.SelectDistinct(
+		entityRoleTable.Col(COLUMN_ENTITY_TYPE).As(COLUMN_ENTITY_TYPE),
+		entityRoleTable.Col(COLUMN_ENTITY_ID).As(COLUMN_ENTITY_ID),
+	), nil
+}
+
+// roleEntitiesQuery returns the entity role query for the entities holding
+// the role with the given handle, or nil if there is no such role
+func (store *store) roleEntitiesQuery(ctx context.Context, handle string, options RoleEntitiesOptions) (EntityRoleQueryInterface, error) {
+	if handle == "" {
+		return nil, errors.New("rolestore > RoleEntities. handle is empty")
+	}
+
+	if options.Limit < 0 || options.Offset < 0 {
+		return nil, errors.New("rolestore > RoleEntities. " + ERROR_NEGATIVE_NUMBER)
+	}
+
+	role, err := store.RoleFindByHandle(ctx, handle)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if role == nil {
+		return nil, nil
+	}
+
+	query := NewEntityRoleQuery().SetRoleID(role.ID())
+
+	if options.EntityType != "" {
+		query.SetEntityType(options.EntityType)
+	}
+
+	return query, nil
+}
@@ -0,0 +1,223 @@
+package rolestore
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/sb"
+)
+
+func TestStoreEntitiesRoles(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	roleAdmin := NewRole().SetStatus(ROLE_STATUS_ACTIVE).SetHandle("admin").SetTitle("Admin")
+	roleManager := NewRole().SetStatus(ROLE_STATUS_ACTIVE).SetHandle("manager").SetTitle("Manager")
+	roleGuest := NewRole().SetStatus(ROLE_STATUS_INACTIVE).SetHandle("guest").SetTitle("Guest")
+
+	for _, role := range []RoleInterface{roleAdmin, roleManager, roleGuest} {
+		if err := store.RoleCreate(context.Background(), role); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	entityRoles := []EntityRoleInterface{
+		NewEntityRole().SetEntityType("USER").SetEntityID("USER_01").SetRoleID(roleAdmin.ID()),
+		NewEntityRole().SetEntityType("USER").SetEntityID("USER_01").SetRoleID(roleManager.ID()),
+		NewEntityRole().SetEntityType("USER").SetEntityID("USER_02").SetRoleID(roleManager.ID()),
+		NewEntityRole().SetEntityType("USER").SetEntityID("USER_02").SetRoleID(roleGuest.ID()),
+		NewEntityRole().SetEntityType("USER").SetEntityID("USER_03").SetRoleID(roleAdmin.ID()),
+		NewEntityRole().SetEntityType("GROUP").SetEntityID("USER_01").SetRoleID(roleGuest.ID()),
+	}
+
+	for _, entityRole := range entityRoles {
+		if err := store.EntityRoleCreate(context.Background(), entityRole); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	if err := store.EntityRoleSoftDelete(context.Background(), entityRoles[4]); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// more IDs than fit into a single chunk
+	entityIDs := []string{"USER_01", "USER_02", "USER_03"}
+
+	for i := 0; i < entityIDsChunkSize*2; i++ {
+		entityIDs = append(entityIDs, "USER_X"+strconv.Itoa(i))
+	}
+
+	entitiesRoles, err := store.EntitiesRoles(context.Background(), "USER", entityIDs)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(entitiesRoles) != len(entityIDs) {
+		t.Fatal("unexpected result length:", len(entitiesRoles))
+	}
+
+	if len(entitiesRoles["USER_01"]) != 2 {
+		t.Fatal("USER_01 MUST have 2 roles, found:", len(entitiesRoles["USER_01"]))
+	}
+
+	if entitiesRoles["USER_01"][0].Handle() != "admin" || entitiesRoles["USER_01"][1].Handle() != "manager" {
+		t.Fatal("unexpected USER_01 roles")
+	}
+
+	if len(entitiesRoles["USER_02"]) != 1 || entitiesRoles["USER_02"][0].ID() != roleManager.ID() {
+		t.Fatal("USER_02 MUST have only the active manager role")
+	}
+
+	if len(entitiesRoles["USER_03"]) != 0 {
+		t.Fatal("USER_03 MUST have no roles, as the assignment is soft deleted")
+	}
+
+	if len(entitiesRoles["USER_X0"]) != 0 {
+		t.Fatal("USER_X0 MUST have no roles")
+	}
+}
+
+func TestStoreRoleEntities(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	role := NewRole().SetStatus(ROLE_STATUS_ACTIVE).SetHandle("admin").SetTitle("Admin")
+
+	if err := store.RoleCreate(context.Background(), role); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	entities := []EntityRef{
+		NewEntityRef("USER", "USER_03"),
+		NewEntityRef("USER", "USER_01"),
+		NewEntityRef("GROUP", "GROUP_01"),
+		NewEntityRef("USER", "USER_02"),
+	}
+
+	for _, entity := range entities {
+		err := store.EntityRoleCreate(context.Background(), NewEntityRole().
+			SetEntityType(entity.EntityType).
+			SetEntityID(entity.EntityID).
+			SetRoleID(role.ID()))
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	list, err := store.RoleEntities(context.Background(), "admin", RoleEntitiesOptions{})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(list) != 4 || list[0] != NewEntityRef("GROUP", "GROUP_01") || list[1] != NewEntityRef("USER", "USER_01") {
+		t.Fatal("unexpected entities:", list)
+	}
+
+	options := RoleEntitiesOptions{EntityType: "USER", Limit: 2, Offset: 2}
+
+	list, err = store.RoleEntities(context.Background(), "admin", options)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(list) != 1 || list[0] != NewEntityRef("USER", "USER_03") {
+		t.Fatal("unexpected entities:", list)
+	}
+
+	count, err := store.RoleEntitiesCount(context.Background(), "admin", options)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 3 {
+		t.Fatal("unexpected count:", count)
+	}
+
+	// an offset on its own is rejected, as not all databases support it
+	_, err = store.RoleEntities(context.Background(), "admin", RoleEntitiesOptions{Offset: 2})
+
+	if err == nil {
+		t.Fatal("error MUST NOT be nil for an offset without a limit")
+	}
+
+	// an entity holding the role twice, by an assignment restored next to
+	// a newer one ending in a year, is listed and counted once
+	previous, err := store.EntityRoleFindByEntityAndRole(context.Background(), "GROUP", "GROUP_01", role.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.EntityRoleSoftDelete(context.Background(), previous); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.EntityRoleCreate(context.Background(), NewEntityRole().
+		SetEntityType("GROUP").
+		SetEntityID("GROUP_01").
+		SetRoleID(role.ID()).
+		SetSoftDeletedAt(carbon.Now(carbon.UTC).AddYear().ToDateTimeString(carbon.UTC)))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.EntityRoleUpdate(context.Background(), previous.SetSoftDeletedAt(sb.MAX_DATETIME)); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	list, err = store.RoleEntities(context.Background(), "admin", RoleEntitiesOptions{Limit: 10})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(list) != 4 {
+		t.Fatal("unexpected entities:", list)
+	}
+
+	count, err = store.RoleEntitiesCount(context.Background(), "admin", RoleEntitiesOptions{})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 4 {
+		t.Fatal("unexpected count:", count)
+	}
+
+	list, err = store.RoleEntities(context.Background(), "unknown", RoleEntitiesOptions{})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(list) != 0 {
+		t.Fatal("unexpected entities for unknown role:", list)
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"database/sql"
 	"errors"
 	"log/slog"
+	"maps"
 
 	"github.com/gouniverse/sb"
+	"github.com/samber/lo"
 )
 
 // NewStoreOptions define the options for creating a new block store
@@ -16,6 +18,38 @@ type NewStoreOptions struct {
 	// EntityRoleTableName is the name of the entity to role relation table
 	EntityRoleTableName string
 
+	// SodConstraintTableName is the name of the separation of duties constraint table,
+	// optional, if empty separation of duties constraints are disabled
+	SodConstraintTableName string
+
+	// SessionTableName is the name of the role activation session table,
+	// optional, if empty sessions are disabled
+	SessionTableName string
+
+	// RoleRequestTableName is the name of the role request table,
+	// optional, if empty role requests are disabled
+	RoleRequestTableName string
+
+	// RoleRequestHook is called after a role request is created, approved,
+	// rejected, cancelled or expired, i.e. to notify the approvers or the requester
+	RoleRequestHook RoleRequestHook
+
+	// ReviewCampaignTableName is the name of the access review campaign table,
+	// optional, if empty access reviews are disabled. Requires ReviewItemTableName
+	ReviewCampaignTableName string
+
+	// ReviewItemTableName is the name of the access review item table,
+	// required if ReviewCampaignTableName is set
+	ReviewItemTableName string
+
+	// DelegationTableName is the name of the role delegation table,
+	// optional, if empty delegations are disabled
+	DelegationTableName string
+
+	// AuditHook is called with an audit entry for each just-in-time elevation,
+	// break-glass elevation and break-glass review, i.e. to write an audit log
+	AuditHook AuditHook
+
 	// DB is the underlying database connection
 	DB *sql.DB
 
@@ -28,8 +62,22 @@ type NewStoreOptions struct {
 	// DebugEnabled enables or disables the debug mode
 	DebugEnabled bool
 
-	// SqlLogger is the sql statement logger when debug mode is enabled, defaults to the default logger
+	// SqlLogger is the sql statement logger when debug mode is enabled, and the logger
+	// of the migration warnings, defaults to the default logger
 	SqlLogger *slog.Logger
+
+	// TxIsolationLevel is the isolation level for transactions started by WithTx,
+	// defaults to the default level of the database driver
+	TxIsolationLevel sql.IsolationLevel
+
+	// UserRoleSyncMode keeps the legacy UserInterface.Role() field consistent
+	// with the role entity mappings, one of the USER_ROLE_SYNC_* constants,
+	// defaults to USER_ROLE_SYNC_NONE
+	UserRoleSyncMode string
+
+	// EntityTypes is the entity type registry, the allowed entity types with
+	// an optional (may be nil) ID validator each. If empty, any entity type is allowed
+	EntityTypes map[string]EntityIDValidator
 }
 
 // NewStore creates a new block store
@@ -50,18 +98,37 @@ func NewStore(opts NewStoreOptions) (StoreInterface, error) {
 		opts.DbDriverName = sb.DatabaseDriverName(opts.DB)
 	}
 
+	if !lo.Contains([]string{USER_ROLE_SYNC_NONE, USER_ROLE_SYNC_TO_ENTITY_ROLES, USER_ROLE_SYNC_FROM_ENTITY_ROLES}, opts.UserRoleSyncMode) {
+		return nil, errors.New("role store: UserRoleSyncMode is invalid")
+	}
+
+	if (opts.ReviewCampaignTableName == "") != (opts.ReviewItemTableName == "") {
+		return nil, errors.New("role store: ReviewCampaignTableName and ReviewItemTableName must be set together")
+	}
+
 	if opts.SqlLogger == nil {
 		opts.SqlLogger = slog.Default()
 	}
 
 	store := &store{
-		roleTableName:       opts.RoleTableName,
-		entityRoleTableName: opts.EntityRoleTableName,
-		automigrateEnabled:  opts.AutomigrateEnabled,
-		db:                  opts.DB,
-		dbDriverName:        opts.DbDriverName,
-		debugEnabled:        opts.DebugEnabled,
-		sqlLogger:           opts.SqlLogger,
+		roleTableName:           opts.RoleTableName,
+		entityRoleTableName:     opts.EntityRoleTableName,
+		sodConstraintTableName:  opts.SodConstraintTableName,
+		sessionTableName:        opts.SessionTableName,
+		roleRequestTableName:    opts.RoleRequestTableName,
+		roleRequestHook:         opts.RoleRequestHook,
+		reviewCampaignTableName: opts.ReviewCampaignTableName,
+		reviewItemTableName:     opts.ReviewItemTableName,
+		delegationTableName:     opts.DelegationTableName,
+		auditHook:               opts.AuditHook,
+		automigrateEnabled:      opts.AutomigrateEnabled,
+		db:                      opts.DB,
+		dbDriverName:            opts.DbDriverName,
+		debugEnabled:            opts.DebugEnabled,
+		sqlLogger:               opts.SqlLogger,
+		txIsolationLevel:        opts.TxIsolationLevel,
+		userRoleSyncMode:        opts.UserRoleSyncMode,
+		entityTypes:             maps.Clone(opts.EntityTypes),
 	}
 
 	if store.automigrateEnabled {
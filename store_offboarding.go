@@ -0,0 +1,238 @@
+package rolestore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dromara/carbon/v2"
+)
+
+// EntityRolesChange reports what an offboarding operation changed,
+// as the IDs of the roles affected
+type EntityRolesChange struct {
+	// Revoked are the roles removed from the entity
+	Revoked []string
+
+	// Copied are the roles newly assigned to the target entity
+	Copied []string
+
+	// Moved are the roles moved from the source to the target entity
+	Moved []string
+
+	// Skipped are the roles not copied or moved, as the target entity already
+	// held them, or, when copying, as they were elevations of the source entity
+	Skipped []string
+}
+
+// newEntityRolesChange returns an empty change report
+func newEntityRolesChange() EntityRolesChange {
+	return EntityRolesChange{
+		Revoked: []string{},
+		Copied:  []string{},
+		Moved:   []string{},
+		Skipped: []string{},
+	}
+}
+
+// EntityRevokeAll revokes all live roles of the entity in one transaction.
+// The role entity mappings are soft deleted, or deleted permanently if
+// hardDelete is true
+func (store *store) EntityRevokeAll(ctx context.Context, entityType string, entityID string, hardDelete bool) (EntityRolesChange, error) {
+	change := newEntityRolesChange()
+
+	entity := NewEntityRef(entityType, entityID)
+
+	if entity.IsEmpty() {
+		return change, errors.New("rolestore > EntityRevokeAll. entity type and ID are required")
+	}
+
+	err := store.WithTx(ctx, func(txCtx context.Context) error {
+		entityRoles, err := store.entityLiveRoles(txCtx, entity)
+
+		if err != nil {
+			return err
+		}
+
+		for _, entityRole := range entityRoles {
+			if hardDelete {
+				err = store.EntityRoleDelete(txCtx, entityRole)
+			} else {
+				err = store.EntityRoleSoftDelete(txCtx, entityRole)
+			}
+
+			if err != nil {
+				return err
+			}
+
+			change.Revoked = append(change.Revoked, entityRole.RoleID())
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return newEntityRolesChange(), err
+	}
+
+	return change, nil
+}
+
+// EntityCopyRoles assigns all live roles of the from entity to the to entity
+// in one transaction, including their metas, memo, provenance and expiry.
+// Roles the to entity already holds are skipped, and so are elevations,
+// as those were granted to the from entity personally
+func (store *store) EntityCopyRoles(ctx context.Context, from EntityRef, to EntityRef) (EntityRolesChange, error) {
+	change := newEntityRolesChange()
+
+	if err := validateEntityRefPair("EntityCopyRoles", from, to); err != nil {
+		return change, err
+	}
+
+	err := store.WithTx(ctx, func(txCtx context.Context) error {
+		entityRoles, err := store.entityLiveRoles(txCtx, from)
+
+		if err != nil {
+			return err
+		}
+
+		for _, entityRole := range entityRoles {
+			if entityRole.Source() == ENTITY_ROLE_SOURCE_ELEVATION {
+				change.Skipped = append(change.Skipped, entityRole.RoleID())
+				continue
+			}
+
+			existing, err := store.EntityRoleFindByEntityAndRole(txCtx, to.EntityType, to.EntityID, entityRole.RoleID())
+
+			if err != nil {
+				return err
+			}
+
+			if existing != nil {
+				change.Skipped = append(change.Skipped, entityRole.RoleID())
+				continue
+			}
+
+			metas, err := entityRole.Metas()
+
+			if err != nil {
+				return err
+			}
+
+			entityRoleCopy := NewEntityRole().
+				SetEntityType(to.EntityType).
+				SetEntityID(to.EntityID).
+				SetRoleID(entityRole.RoleID()).
+				SetMemo(entityRole.Memo()).
+				SetGrantedByType(entityRole.GrantedByType()).
+				SetGrantedByID(entityRole.GrantedByID()).
+				SetReason(entityRole.Reason()).
+				SetSource(entityRole.Source()).
+				SetSoftDeletedAt(entityRole.SoftDeletedAtCarbon().ToDateTimeString(carbon.UTC))
+
+			if err := entityRoleCopy.SetMetas(metas); err != nil {
+				return err
+			}
+
+			if err := store.EntityRoleCreate(txCtx, entityRoleCopy); err != nil {
+				return err
+			}
+
+			change.Copied = append(change.Copied, entityRole.RoleID())
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return newEntityRolesChange(), err
+	}
+
+	return change, nil
+}
+
+// EntityTransferRoles moves all live roles of the from entity to the to entity
+// in one transaction, by reassigning the existing rows. Roles the to entity
+// already holds are skipped, and their rows on the from entity are soft deleted,
+// so that the from entity holds no roles afterwards
+func (store *store) EntityTransferRoles(ctx context.Context, from EntityRef, to EntityRef) (EntityRolesChange, error) {
+	change := newEntityRolesChange()
+
+	if err := validateEntityRefPair("EntityTransferRoles", from, to); err != nil {
+		return change, err
+	}
+
+	err := store.WithTx(ctx, func(txCtx context.Context) error {
+		entityRoles, err := store.entityLiveRoles(txCtx, from)
+
+		if err != nil {
+			return err
+		}
+
+		for _, entityRole := range entityRoles {
+			if entityRole.Source() == ENTITY_ROLE_SOURCE_ELEVATION {
+				change.Skipped = append(change.Skipped, entityRole.RoleID())
+				continue
+			}
+
+			existing, err := store.EntityRoleFindByEntityAndRole(txCtx, to.EntityType, to.EntityID, entityRole.RoleID())
+
+			if err != nil {
+				return err
+			}
+
+			if existing != nil {
+				if err := store.EntityRoleSoftDelete(txCtx, entityRole); err != nil {
+					return err
+				}
+
+				change.Skipped = append(change.Skipped, entityRole.RoleID())
+				continue
+			}
+
+			entityRole.SetEntityType(to.EntityType)
+			entityRole.SetEntityID(to.EntityID)
+
+			if err := store.EntityRoleUpdate(txCtx, entityRole); err != nil {
+				return err
+			}
+
+			change.Moved = append(change.Moved, entityRole.RoleID())
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return newEntityRolesChange(), err
+	}
+
+	return change, nil
+}
+
+// entityLiveRoles returns the live (not soft deleted) role entity mappings of the entity, ordered by role ID
+func (store *store) entityLiveRoles(ctx context.Context, entity EntityRef) ([]EntityRoleInterface, error) {
+	query := NewEntityRoleQuery().
+		SetEntityType(entity.EntityType).
+		SetEntityID(entity.EntityID).
+		SetOrderBy(COLUMN_ROLE_ID).
+		SetSortDirection(ASC)
+
+	return store.EntityRoleList(ctx, query)
+}
+
+// validateEntityRefPair checks the source and target entities of a copy or transfer
+func validateEntityRefPair(method string, from EntityRef, to EntityRef) error {
+	if from.IsEmpty() {
+		return errors.New("rolestore > " + method + ". from entity type and ID are required")
+	}
+
+	if to.IsEmpty() {
+		return errors.New("rolestore > " + method + ". to entity type and ID are required")
+	}
+
+	if from == to {
+		return errors.New("rolestore > " + method + ". from and to entities must differ")
+	}
+
+	return nil
+}
@@ -0,0 +1,316 @@
+package rolestore
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/dromara/carbon/v2"
+)
+
+func TestStoreEntityRevokeAll(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	for _, roleID := range []string{"ROLE_01", "ROLE_02"} {
+		for _, entityID := range []string{"USER_01", "USER_02"} {
+			err := store.EntityRoleCreate(context.Background(), NewEntityRole().
+				SetEntityType("USER").
+				SetEntityID(entityID).
+				SetRoleID(roleID))
+
+			if err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+		}
+	}
+
+	change, err := store.EntityRevokeAll(context.Background(), "USER", "USER_01", false)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !slices.Equal(change.Revoked, []string{"ROLE_01", "ROLE_02"}) {
+		t.Fatal("unexpected revoked roles:", change.Revoked)
+	}
+
+	count, err := store.EntityRoleCount(context.Background(), NewEntityRoleQuery().
+		SetEntityType("USER").
+		SetEntityID("USER_01").
+		SetSoftDeletedIncluded(true))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 2 {
+		t.Fatal("soft deleted entity roles MUST be kept, found:", count)
+	}
+
+	// hard delete
+	change, err = store.EntityRevokeAll(context.Background(), "USER", "USER_02", true)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(change.Revoked) != 2 {
+		t.Fatal("unexpected revoked roles:", change.Revoked)
+	}
+
+	count, err = store.EntityRoleCount(context.Background(), NewEntityRoleQuery().
+		SetEntityType("USER").
+		SetEntityID("USER_02").
+		SetSoftDeletedIncluded(true))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 0 {
+		t.Fatal("entity roles MUST be deleted, found:", count)
+	}
+
+	if _, err := store.EntityRevokeAll(context.Background(), "USER", "", false); err == nil {
+		t.Fatal("error MUST NOT be nil for an empty entity ID")
+	}
+}
+
+func TestStoreEntityCopyRoles(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	source := NewEntityRole().SetEntityType("USER").SetEntityID("USER_01").SetRoleID("ROLE_01").SetMemo("memo")
+
+	if err := source.SetMeta("granted_for", "project"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	entityRoles := []EntityRoleInterface{
+		source,
+		NewEntityRole().SetEntityType("USER").SetEntityID("USER_01").SetRoleID("ROLE_02"),
+		NewEntityRole().SetEntityType("USER").SetEntityID("USER_02").SetRoleID("ROLE_02"),
+	}
+
+	for _, entityRole := range entityRoles {
+		if err := store.EntityRoleCreate(context.Background(), entityRole); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	change, err := store.EntityCopyRoles(context.Background(), NewEntityRef("USER", "USER_01"), NewEntityRef("USER", "USER_02"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !slices.Equal(change.Copied, []string{"ROLE_01"}) {
+		t.Fatal("unexpected copied roles:", change.Copied)
+	}
+
+	if !slices.Equal(change.Skipped, []string{"ROLE_02"}) {
+		t.Fatal("unexpected skipped roles:", change.Skipped)
+	}
+
+	copied, err := store.EntityRoleFindByEntityAndRole(context.Background(), "USER", "USER_02", "ROLE_01")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if copied == nil {
+		t.Fatal("entity role MUST be copied")
+	}
+
+	if copied.Memo() != "memo" || copied.Meta("granted_for") != "project" {
+		t.Fatal("memo and metas MUST be copied, found:", copied.Memo(), copied.Meta("granted_for"))
+	}
+
+	count, err := store.EntityRoleCount(context.Background(), NewEntityRoleQuery().SetEntityType("USER").SetEntityID("USER_01"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 2 {
+		t.Fatal("source roles MUST be kept, found:", count)
+	}
+
+	if _, err := store.EntityCopyRoles(context.Background(), NewEntityRef("USER", "USER_01"), NewEntityRef("USER", "USER_01")); err == nil {
+		t.Fatal("error MUST NOT be nil when copying to the same entity")
+	}
+}
+
+func TestStoreEntityCopyRoles_ProvenanceAndElevations(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	expiresAt := carbon.Now(carbon.UTC).AddMonth().ToDateTimeString(carbon.UTC)
+
+	err = store.EntityRoleCreate(context.Background(), NewEntityRole().
+		SetEntityType("USER").
+		SetEntityID("USER_01").
+		SetRoleID("ROLE_01").
+		SetGrantedByType("USER").
+		SetGrantedByID("ADMIN_01").
+		SetReason("project").
+		SetSource(ENTITY_ROLE_SOURCE_API).
+		SetSoftDeletedAt(expiresAt))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	role := createTestRole(t, store, "prod_admin", func(role RoleInterface) error {
+		return role.SetBreakGlass(true)
+	})
+
+	_, err = store.EntityRoleElevate(context.Background(), ElevationOptions{
+		EntityType: "USER",
+		EntityID:   "USER_01",
+		RoleID:     role.ID(),
+		Duration:   time.Hour,
+		Reason:     "incident",
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	change, err := store.EntityCopyRoles(context.Background(), NewEntityRef("USER", "USER_01"), NewEntityRef("USER", "USER_02"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !slices.Equal(change.Copied, []string{"ROLE_01"}) || !slices.Equal(change.Skipped, []string{role.ID()}) {
+		t.Fatal("unexpected change:", change)
+	}
+
+	copied, err := store.EntityRoleFindByEntityAndRole(context.Background(), "USER", "USER_02", "ROLE_01")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if copied == nil {
+		t.Fatal("entity role MUST be copied")
+	}
+
+	if copied.GrantedByType() != "USER" || copied.GrantedByID() != "ADMIN_01" || copied.Reason() != "project" || copied.Source() != ENTITY_ROLE_SOURCE_API {
+		t.Fatal("provenance MUST be copied, found:", copied.GrantedByID(), copied.Reason(), copied.Source())
+	}
+
+	if copied.SoftDeletedAtCarbon().ToDateTimeString(carbon.UTC) != expiresAt {
+		t.Fatal("expiry MUST be copied, found:", copied.SoftDeletedAt())
+	}
+
+	elevated, err := store.EntityHasRole(context.Background(), "USER", "USER_02", role.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if elevated {
+		t.Fatal("an elevation MUST NOT be copied")
+	}
+}
+
+func TestStoreEntityTransferRoles(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	entityRoles := []EntityRoleInterface{
+		NewEntityRole().SetEntityType("USER").SetEntityID("USER_01").SetRoleID("ROLE_01"),
+		NewEntityRole().SetEntityType("USER").SetEntityID("USER_01").SetRoleID("ROLE_02"),
+		NewEntityRole().SetEntityType("USER").SetEntityID("USER_02").SetRoleID("ROLE_02"),
+	}
+
+	for _, entityRole := range entityRoles {
+		if err := store.EntityRoleCreate(context.Background(), entityRole); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	change, err := store.EntityTransferRoles(context.Background(), NewEntityRef("USER", "USER_01"), NewEntityRef("USER", "USER_02"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !slices.Equal(change.Moved, []string{"ROLE_01"}) {
+		t.Fatal("unexpected moved roles:", change.Moved)
+	}
+
+	if !slices.Equal(change.Skipped, []string{"ROLE_02"}) {
+		t.Fatal("unexpected skipped roles:", change.Skipped)
+	}
+
+	moved, err := store.EntityRoleFindByID(context.Background(), entityRoles[0].ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if moved.EntityID() != "USER_02" {
+		t.Fatal("entity role MUST be moved, found:", moved.EntityID())
+	}
+
+	count, err := store.EntityRoleCount(context.Background(), NewEntityRoleQuery().SetEntityType("USER").SetEntityID("USER_01"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 0 {
+		t.Fatal("source entity MUST NOT hold roles, found:", count)
+	}
+
+	count, err = store.EntityRoleCount(context.Background(), NewEntityRoleQuery().SetEntityType("USER").SetEntityID("USER_02"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 2 {
+		t.Fatal("unexpected target role count:", count)
+	}
+}
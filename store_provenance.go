@@ -0,0 +1,73 @@
+package rolestore
+
+import (
+	"context"
+	"errors"
+	"slices"
+)
+
+// entityRoleSources are the valid sources of an assignment
+var entityRoleSources = []string{
+	ENTITY_ROLE_SOURCE_MANUAL,
+	ENTITY_ROLE_SOURCE_SSO,
+	ENTITY_ROLE_SOURCE_SYNC,
+	ENTITY_ROLE_SOURCE_API,
+	ENTITY_ROLE_SOURCE_RULE,
+	ENTITY_ROLE_SOURCE_REQUEST,
+	ENTITY_ROLE_SOURCE_ELEVATION,
+}
+
+// EntityRoleRevokeBySource soft deletes, in one transaction, all live
+// assignments with the given source. If grantedBy is not empty, only the
+// assignments granted by that entity are revoked, i.e. revoking everything
+// a sync connector granted. Returns the number of revoked assignments
+func (store *store) EntityRoleRevokeBySource(ctx context.Context, source string, grantedBy EntityRef) (int64, error) {
+	if !slices.Contains(entityRoleSources, source) {
+		return 0, errors.New("rolestore > EntityRoleRevokeBySource. source is invalid: " + source)
+	}
+
+	query := NewEntityRoleQuery().SetSource(source)
+
+	if !grantedBy.IsEmpty() {
+		query.SetGrantedByType(grantedBy.EntityType).SetGrantedByID(grantedBy.EntityID)
+	}
+
+	revoked := int64(0)
+
+	err := store.WithTx(ctx, func(txCtx context.Context) error {
+		entityRoles, err := store.EntityRoleList(txCtx, query)
+
+		if err != nil {
+			return err
+		}
+
+		for _, entityRole := range entityRoles {
+			if err := store.EntityRoleSoftDelete(txCtx, entityRole); err != nil {
+				return err
+			}
+
+			revoked++
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return revoked, nil
+}
+
+// validateEntityRoleProvenance checks the source and the granting entity of an assignment
+func validateEntityRoleProvenance(method string, entityRole EntityRoleInterface) error {
+	if !slices.Contains(entityRoleSources, entityRole.Source()) {
+		return errors.New("rolestore > " + method + ". entityRole source is invalid: " + entityRole.Source())
+	}
+
+	if (entityRole.GrantedByType() == "") != (entityRole.GrantedByID() == "") {
+		return errors.New("rolestore > " + method + ". entityRole granted by type and ID must be set together")
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+package rolestore
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStoreEntityRoleProvenance(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	entityRole := NewEntityRole().
+		SetEntityType("user").
+		SetEntityID("USER_01").
+		SetRoleID("ROLE_01").
+		SetGrantedByType("user").
+		SetGrantedByID("ADMIN_01").
+		SetReason("on call rotation").
+		SetSource(ENTITY_ROLE_SOURCE_API)
+
+	if err := store.EntityRoleCreate(context.Background(), entityRole); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	found, err := store.EntityRoleFindByID(context.Background(), entityRole.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if found.GrantedByType() != "user" || found.GrantedByID() != "ADMIN_01" {
+		t.Fatal("unexpected granted by:", found.GrantedByType(), found.GrantedByID())
+	}
+
+	if found.Reason() != "on call rotation" || found.Source() != ENTITY_ROLE_SOURCE_API {
+		t.Fatal("unexpected reason or source:", found.Reason(), found.Source())
+	}
+
+	if NewEntityRole().Source() != ENTITY_ROLE_SOURCE_MANUAL {
+		t.Fatal("default source MUST be manual")
+	}
+
+	err = store.EntityRoleCreate(context.Background(), NewEntityRole().
+		SetEntityType("user").
+		SetEntityID("USER_02").
+		SetRoleID("ROLE_01").
+		SetSource("import"))
+
+	if err == nil {
+		t.Fatal("error MUST NOT be nil for an invalid source")
+	}
+
+	err = store.EntityRoleCreate(context.Background(), NewEntityRole().
+		SetEntityType("user").
+		SetEntityID("USER_02").
+		SetRoleID("ROLE_01").
+		SetGrantedByType("user"))
+
+	if err == nil {
+		t.Fatal("error MUST NOT be nil for a granted by type without ID")
+	}
+
+	// the provenance is validated on update too
+	if err := store.EntityRoleUpdate(context.Background(), found.SetSource("import")); err == nil {
+		t.Fatal("error MUST NOT be nil for an invalid source on update")
+	}
+
+	found, err = store.EntityRoleFindByID(context.Background(), entityRole.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.EntityRoleUpdate(context.Background(), found.SetGrantedByID("")); err == nil {
+		t.Fatal("error MUST NOT be nil for a granted by type without ID on update")
+	}
+
+	found, err = store.EntityRoleFindByID(context.Background(), entityRole.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if found.Source() != ENTITY_ROLE_SOURCE_API || found.GrantedByID() != "ADMIN_01" {
+		t.Fatal("unexpected provenance after rejected updates:", found.Source(), found.GrantedByID())
+	}
+
+	if err := store.EntityRoleUpdate(context.Background(), found.SetSource(ENTITY_ROLE_SOURCE_SYNC)); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+}
+
+func TestStoreEntityRoleRevokeBySource(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	entityRoles := []EntityRoleInterface{
+		NewEntityRole().SetEntityType("user").SetEntityID("USER_01").SetRoleID("ROLE_01").
+			SetSource(ENTITY_ROLE_SOURCE_SYNC).SetGrantedByType("connector").SetGrantedByID("LDAP"),
+		NewEntityRole().SetEntityType("user").SetEntityID("USER_02").SetRoleID("ROLE_01").
+			SetSource(ENTITY_ROLE_SOURCE_SYNC).SetGrantedByType("connector").SetGrantedByID("LDAP"),
+		NewEntityRole().SetEntityType("user").SetEntityID("USER_03").SetRoleID("ROLE_01").
+			SetSource(ENTITY_ROLE_SOURCE_SYNC).SetGrantedByType("connector").SetGrantedByID("SCIM"),
+		NewEntityRole().SetEntityType("user").SetEntityID("USER_04").SetRoleID("ROLE_01"),
+	}
+
+	for _, entityRole := range entityRoles {
+		if err := store.EntityRoleCreate(context.Background(), entityRole); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	list, err := store.EntityRoleList(context.Background(), NewEntityRoleQuery().
+		SetSourceIn([]string{ENTITY_ROLE_SOURCE_SYNC}).
+		SetGrantedByID("LDAP"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(list) != 2 {
+		t.Fatal("unexpected entity roles length:", len(list))
+	}
+
+	revoked, err := store.EntityRoleRevokeBySource(context.Background(), ENTITY_ROLE_SOURCE_SYNC, NewEntityRef("connector", "LDAP"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if revoked != 2 {
+		t.Fatal("unexpected revoked count:", revoked)
+	}
+
+	count, err := store.EntityRoleCount(context.Background(), NewEntityRoleQuery().SetRoleID("ROLE_01"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 2 {
+		t.Fatal("unexpected remaining count:", count)
+	}
+
+	// without granted by, all assignments of the source are revoked
+	revoked, err = store.EntityRoleRevokeBySource(context.Background(), ENTITY_ROLE_SOURCE_SYNC, EntityRef{})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if revoked != 1 {
+		t.Fatal("unexpected revoked count:", revoked)
+	}
+}
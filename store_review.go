@@ -0,0 +1,602 @@
+package rolestore
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/base/database"
+	"github.com/samber/lo"
+	"github.com/spf13/cast"
+)
+
+// ReviewerResolver returns the entity, which is to review the assignment,
+// i.e. the manager of the user holding the role. An empty reference, or the
+// holder of the assignment itself, leaves the item unassigned, to be
+// assigned later by ReviewItemAssignReviewer
+type ReviewerResolver func(ctx context.Context, entityRole EntityRoleInterface) (EntityRef, error)
+
+// ReviewProgress reports how many items of a review campaign are decided
+type ReviewProgress struct {
+	// CampaignID is the ID of the campaign
+	CampaignID string
+
+	// Total is the number of items in the campaign
+	Total int64
+
+	// Pending is the number of items not decided yet
+	Pending int64
+
+	// Kept is the number of items decided to be kept
+	Kept int64
+
+	// Revoked is the number of items decided to be revoked
+	Revoked int64
+}
+
+// Percent returns the percentage of the items decided,
+// a campaign without items is complete
+func (progress ReviewProgress) Percent() float64 {
+	if progress.Total == 0 {
+		return 100
+	}
+
+	return float64(progress.Total-progress.Pending) * 100 / float64(progress.Total)
+}
+
+// ReviewCampaignCreate creates an open review campaign, and in the same
+// transaction snapshots the live assignments of the roles of the campaign
+// as review items, with the reviewers returned by reviewer (may be nil)
+func (store *store) ReviewCampaignCreate(ctx context.Context, campaign ReviewCampaignInterface, reviewer ReviewerResolver) error {
+	if err := store.reviewEnabled("ReviewCampaignCreate"); err != nil {
+		return err
+	}
+
+	if campaign == nil {
+		return errors.New("rolestore > ReviewCampaignCreate. campaign is nil")
+	}
+
+	roleIDs, err := campaign.RoleIDs()
+
+	if err != nil {
+		return err
+	}
+
+	if len(roleIDs) == 0 {
+		return errors.New("rolestore > ReviewCampaignCreate. campaign must have at least one role")
+	}
+
+	err = store.WithTx(ctx, func(txCtx context.Context) error {
+		campaign.SetStatus(REVIEW_CAMPAIGN_STATUS_OPEN)
+		campaign.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+		campaign.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+		if err := store.reviewInsert(txCtx, store.reviewCampaignTableName, campaign.Data()); err != nil {
+			return err
+		}
+
+		entityRoles, err := store.EntityRoleList(txCtx, NewEntityRoleQuery().
+			SetRoleIDIn(roleIDs).
+			SetOrderBy(COLUMN_ROLE_ID).
+			SetSortDirection(ASC))
+
+		if err != nil {
+			return err
+		}
+
+		for _, entityRole := range entityRoles {
+			item := NewReviewItem().
+				SetCampaignID(campaign.ID()).
+				SetEntityRoleID(entityRole.ID()).
+				SetEntityType(entityRole.EntityType()).
+				SetEntityID(entityRole.EntityID()).
+				SetRoleID(entityRole.RoleID())
+
+			if reviewer != nil {
+				ref, err := reviewer(txCtx, entityRole)
+
+				if err != nil {
+					return err
+				}
+
+				// entities cannot review their own assignments,
+				// such items are left unassigned
+				if ref != NewEntityRef(entityRole.EntityType(), entityRole.EntityID()) {
+					item.SetReviewerType(ref.EntityType)
+					item.SetReviewerID(ref.EntityID)
+				}
+			}
+
+			if err := store.reviewInsert(txCtx, store.reviewItemTableName, item.Data()); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	campaign.MarkAsNotDirty()
+
+	return nil
+}
+
+// ReviewCampaignClose closes an open campaign, and soft deletes the
+// assignments decided to be revoked, returning how many were revoked.
+// Undecided items are kept, and assignments already removed, or moved to
+// another entity or role since the review, are skipped
+func (store *store) ReviewCampaignClose(ctx context.Context, campaignID string) (int64, error) {
+	if err := store.reviewEnabled("ReviewCampaignClose"); err != nil {
+		return 0, err
+	}
+
+	revoked := int64(0)
+
+	err := store.WithTx(ctx, func(txCtx context.Context) error {
+		campaign, err := store.reviewOpenCampaign(txCtx, campaignID)
+
+		if err != nil {
+			return err
+		}
+
+		items, err := store.reviewItemList(txCtx,
+			goqu.C(COLUMN_CAMPAIGN_ID).Eq(campaign.ID()),
+			goqu.C(COLUMN_DECISION).Eq(REVIEW_DECISION_REVOKE),
+		)
+
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			entityRole, err := store.EntityRoleFindByID(txCtx, item.EntityRoleID())
+
+			if err != nil {
+				return err
+			}
+
+			if entityRole == nil {
+				continue
+			}
+
+			// the assignment was moved (i.e. transferred) since the
+			// review, so the decision is not about its current holder
+			moved := entityRole.EntityType() != item.EntityType() ||
+				entityRole.EntityID() != item.EntityID() ||
+				entityRole.RoleID() != item.RoleID()
+
+			if moved {
+				continue
+			}
+
+			if err := store.EntityRoleSoftDelete(txCtx, entityRole); err != nil {
+				return err
+			}
+
+			revoked++
+		}
+
+		campaign.SetStatus(REVIEW_CAMPAIGN_STATUS_CLOSED)
+		campaign.SetClosedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+		campaign.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+		return store.reviewUpdate(txCtx, store.reviewCampaignTableName, campaign.ID(), campaign)
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return revoked, nil
+}
+
+// ReviewCampaignFindByID returns a review campaign by its ID, or nil if not found
+func (store *store) ReviewCampaignFindByID(ctx context.Context, id string) (ReviewCampaignInterface, error) {
+	if id == "" {
+		return nil, errors.New("rolestore > ReviewCampaignFindByID. campaign id is empty")
+	}
+
+	list, err := store.reviewCampaignList(ctx, goqu.C(COLUMN_ID).Eq(id))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) > 0 {
+		return list[0], nil
+	}
+
+	return nil, nil
+}
+
+// ReviewCampaignList returns the review campaigns with the given status,
+// one of the REVIEW_CAMPAIGN_STATUS_* constants, or all if empty,
+// newest first
+func (store *store) ReviewCampaignList(ctx context.Context, status string) ([]ReviewCampaignInterface, error) {
+	if status == "" {
+		return store.reviewCampaignList(ctx)
+	}
+
+	return store.reviewCampaignList(ctx, goqu.C(COLUMN_STATUS).Eq(status))
+}
+
+// ReviewCampaignProgress returns how many items of the campaign
+// are pending, and how many are decided to be kept or revoked
+func (store *store) ReviewCampaignProgress(ctx context.Context, campaignID string) (ReviewProgress, error) {
+	if err := store.reviewEnabled("ReviewCampaignProgress"); err != nil {
+		return ReviewProgress{}, err
+	}
+
+	if campaignID == "" {
+		return ReviewProgress{}, errors.New("rolestore > ReviewCampaignProgress. campaign id is empty")
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(store.reviewItemTableName).
+		Prepared(true).
+		Select(goqu.C(COLUMN_DECISION), goqu.COUNT(goqu.Star()).As("count")).
+		Where(goqu.C(COLUMN_CAMPAIGN_ID).Eq(campaignID)).
+		GroupBy(goqu.C(COLUMN_DECISION)).
+		ToSQL()
+
+	if errSql != nil {
+		return ReviewProgress{}, errSql
+	}
+
+	rows, err := store.selectToMaps(ctx, sqlStr, params...)
+
+	if err != nil {
+		return ReviewProgress{}, err
+	}
+
+	progress := ReviewProgress{CampaignID: campaignID}
+
+	for _, row := range rows {
+		count := cast.ToInt64(row["count"])
+
+		progress.Total += count
+
+		switch row[COLUMN_DECISION] {
+		case REVIEW_DECISION_PENDING:
+			progress.Pending += count
+		case REVIEW_DECISION_KEEP:
+			progress.Kept += count
+		case REVIEW_DECISION_REVOKE:
+			progress.Revoked += count
+		}
+	}
+
+	return progress, nil
+}
+
+// ReviewCampaignReport writes the items of the campaign with their
+// reviewers and decisions as CSV, with a header row, i.e. as evidence
+// for an audit
+func (store *store) ReviewCampaignReport(ctx context.Context, campaignID string, w io.Writer) error {
+	campaign, err := store.ReviewCampaignFindByID(ctx, campaignID)
+
+	if err != nil {
+		return err
+	}
+
+	if campaign == nil {
+		return ErrReviewCampaignNotFound
+	}
+
+	items, err := store.reviewItemList(ctx, goqu.C(COLUMN_CAMPAIGN_ID).Eq(campaign.ID()))
+
+	if err != nil {
+		return err
+	}
+
+	roleIDs, err := campaign.RoleIDs()
+
+	if err != nil {
+		return err
+	}
+
+	roles, err := store.RoleList(ctx, NewRoleQuery().SetIDIn(roleIDs).SetSoftDeletedIncluded(true))
+
+	if err != nil {
+		return err
+	}
+
+	handles := lo.SliceToMap(roles, func(role RoleInterface) (string, string) {
+		return role.ID(), role.Handle()
+	})
+
+	writer := csv.NewWriter(w)
+
+	err = writer.Write([]string{
+		COLUMN_CAMPAIGN_ID,
+		COLUMN_ID,
+		COLUMN_ENTITY_TYPE,
+		COLUMN_ENTITY_ID,
+		COLUMN_ROLE_ID,
+		COLUMN_HANDLE,
+		COLUMN_REVIEWER_TYPE,
+		COLUMN_REVIEWER_ID,
+		COLUMN_DECISION,
+		COLUMN_DECISION_NOTE,
+		COLUMN_DECIDED_AT,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		decidedAt := ""
+
+		if !item.IsPending() {
+			decidedAt = item.DecidedAt()
+		}
+
+		err := writer.Write([]string{
+			campaign.ID(),
+			item.ID(),
+			item.EntityType(),
+			item.EntityID(),
+			item.RoleID(),
+			handles[item.RoleID()],
+			item.ReviewerType(),
+			item.ReviewerID(),
+			item.Decision(),
+			item.DecisionNote(),
+			decidedAt,
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// ReviewItemAssignReviewer assigns (or reassigns) the reviewer of an item
+// of an open campaign. Entities cannot review their own assignments
+func (store *store) ReviewItemAssignReviewer(ctx context.Context, itemID string, reviewer EntityRef) error {
+	if reviewer.IsEmpty() {
+		return errors.New("rolestore > ReviewItemAssignReviewer. reviewer entity type and ID are required")
+	}
+
+	return store.reviewItemChange(ctx, itemID, func(item ReviewItemInterface) error {
+		if reviewer == NewEntityRef(item.EntityType(), item.EntityID()) {
+			return fmt.Errorf("%w: entities cannot review their own assignments", ErrReviewerNotAllowed)
+		}
+
+		item.SetReviewerType(reviewer.EntityType)
+		item.SetReviewerID(reviewer.EntityID)
+
+		return nil
+	})
+}
+
+// ReviewItemDecide records the decision, REVIEW_DECISION_KEEP or
+// REVIEW_DECISION_REVOKE, of the reviewer assigned to an item of an open
+// campaign. A decision can be changed until the campaign is closed
+func (store *store) ReviewItemDecide(ctx context.Context, itemID string, reviewer EntityRef, decision string, note string) error {
+	if decision != REVIEW_DECISION_KEEP && decision != REVIEW_DECISION_REVOKE {
+		return errors.New("rolestore > ReviewItemDecide. decision must be keep or revoke: " + decision)
+	}
+
+	return store.reviewItemChange(ctx, itemID, func(item ReviewItemInterface) error {
+		if item.Reviewer().IsEmpty() || item.Reviewer() != reviewer {
+			return fmt.Errorf("%w: %s %s", ErrReviewerNotAllowed, reviewer.EntityType, reviewer.EntityID)
+		}
+
+		item.SetDecision(decision)
+		item.SetDecisionNote(note)
+		item.SetDecidedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+		return nil
+	})
+}
+
+// ReviewItemList returns the items of the campaign, only those assigned
+// to the reviewer if it is not empty, i.e. for a reviewer's work list
+func (store *store) ReviewItemList(ctx context.Context, campaignID string, reviewer EntityRef) ([]ReviewItemInterface, error) {
+	if campaignID == "" {
+		return []ReviewItemInterface{}, errors.New("rolestore > ReviewItemList. campaign id is empty")
+	}
+
+	conditions := []goqu.Expression{goqu.C(COLUMN_CAMPAIGN_ID).Eq(campaignID)}
+
+	if !reviewer.IsEmpty() {
+		conditions = append(conditions,
+			goqu.C(COLUMN_REVIEWER_TYPE).Eq(reviewer.EntityType),
+			goqu.C(COLUMN_REVIEWER_ID).Eq(reviewer.EntityID),
+		)
+	}
+
+	return store.reviewItemList(ctx, conditions...)
+}
+
+// reviewItemChange loads an item of an open campaign in a transaction,
+// lets change apply to it, and saves it
+func (store *store) reviewItemChange(ctx context.Context, itemID string, change func(item ReviewItemInterface) error) error {
+	if err := store.reviewEnabled("ReviewItem"); err != nil {
+		return err
+	}
+
+	if itemID == "" {
+		return errors.New("rolestore > ReviewItem. item id is empty")
+	}
+
+	return store.WithTx(ctx, func(txCtx context.Context) error {
+		items, err := store.reviewItemList(txCtx, goqu.C(COLUMN_ID).Eq(itemID))
+
+		if err != nil {
+			return err
+		}
+
+		if len(items) == 0 {
+			return errors.New("rolestore > ReviewItem. item not found: " + itemID)
+		}
+
+		item := items[0]
+
+		if _, err := store.reviewOpenCampaign(txCtx, item.CampaignID()); err != nil {
+			return err
+		}
+
+		if err := change(item); err != nil {
+			return err
+		}
+
+		item.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+		return store.reviewUpdate(txCtx, store.reviewItemTableName, item.ID(), item)
+	})
+}
+
+// reviewOpenCampaign returns the campaign, or ErrReviewCampaignNotFound
+// or ErrReviewCampaignClosed if it does not exist or is closed
+func (store *store) reviewOpenCampaign(ctx context.Context, campaignID string) (ReviewCampaignInterface, error) {
+	campaign, err := store.ReviewCampaignFindByID(ctx, campaignID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if campaign == nil {
+		return nil, ErrReviewCampaignNotFound
+	}
+
+	if !campaign.IsOpen() {
+		return nil, ErrReviewCampaignClosed
+	}
+
+	return campaign, nil
+}
+
+// reviewCampaignList returns the campaigns matching the conditions, newest first
+func (store *store) reviewCampaignList(ctx context.Context, conditions ...goqu.Expression) ([]ReviewCampaignInterface, error) {
+	rows, err := store.reviewSelect(ctx, store.reviewCampaignTableName, "ReviewCampaignList", conditions, goqu.C(COLUMN_CREATED_AT).Desc())
+
+	if err != nil {
+		return []ReviewCampaignInterface{}, err
+	}
+
+	return lo.Map(rows, func(row map[string]string, _ int) ReviewCampaignInterface {
+		return NewReviewCampaignFromExistingData(row)
+	}), nil
+}
+
+// reviewItemList returns the items matching the conditions,
+// ordered by role and entity
+func (store *store) reviewItemList(ctx context.Context, conditions ...goqu.Expression) ([]ReviewItemInterface, error) {
+	rows, err := store.reviewSelect(ctx, store.reviewItemTableName, "ReviewItemList", conditions,
+		goqu.C(COLUMN_ROLE_ID).Asc(),
+		goqu.C(COLUMN_ENTITY_TYPE).Asc(),
+		goqu.C(COLUMN_ENTITY_ID).Asc(),
+	)
+
+	if err != nil {
+		return []ReviewItemInterface{}, err
+	}
+
+	return lo.Map(rows, func(row map[string]string, _ int) ReviewItemInterface {
+		return NewReviewItemFromExistingData(row)
+	}), nil
+}
+
+// reviewSelect returns the rows of a review table matching the conditions
+func (store *store) reviewSelect(ctx context.Context, table string, method string, conditions []goqu.Expression, order ...exp.OrderedExpression) ([]map[string]string, error) {
+	if err := store.reviewEnabled(method); err != nil {
+		return nil, err
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(table).
+		Prepared(true).
+		Where(conditions...).
+		Order(append(slices.Clone(order), goqu.C(COLUMN_ID).Asc())...).
+		ToSQL()
+
+	if errSql != nil {
+		return nil, errSql
+	}
+
+	return store.selectToMaps(ctx, sqlStr, params...)
+}
+
+// reviewInsert inserts a campaign or an item into its table
+func (store *store) reviewInsert(ctx context.Context, table string, data map[string]string) error {
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Insert(table).
+		Prepared(true).
+		Rows(data).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	store.logSql("insert", sqlStr, params...)
+
+	if store.db == nil {
+		return errors.New("rolestore: database is nil")
+	}
+
+	_, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	return err
+}
+
+// reviewObject is the part of a campaign or an item needed to save it
+type reviewObject interface {
+	DataChanged() map[string]string
+	MarkAsNotDirty()
+}
+
+// reviewUpdate saves the changed fields of a campaign or an item
+func (store *store) reviewUpdate(ctx context.Context, table string, id string, object reviewObject) error {
+	dataChanged := object.DataChanged()
+
+	delete(dataChanged, COLUMN_ID) // ID is not updateable
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Update(table).
+		Prepared(true).
+		Set(dataChanged).
+		Where(goqu.C(COLUMN_ID).Eq(id)).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	store.logSql("update", sqlStr, params...)
+
+	_, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return err
+	}
+
+	object.MarkAsNotDirty()
+
+	return nil
+}
+
+// reviewEnabled returns an error, if no access review tables are configured
+func (store *store) reviewEnabled(method string) error {
+	if store.reviewCampaignTableName == "" {
+		return errors.New("rolestore > " + method + ". access reviews are disabled, ReviewCampaignTableName is not set")
+	}
+
+	return nil
+}
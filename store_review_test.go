@@ -0,0 +1,365 @@
+package rolestore
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"testing"
+
+	"github.com/samber/lo"
+)
+
+func initReviewStore(t *testing.T) StoreInterface {
+	return initStoreWithOptions(t, NewStoreOptions{
+		ReviewCampaignTableName: "roles_review_campaign_table",
+		ReviewItemTableName:     "roles_review_item_table",
+	})
+}
+
+func createReviewCampaign(t *testing.T, store StoreInterface) ReviewCampaignInterface {
+	for _, entityID := range []string{"USER_01", "USER_02", "USER_03"} {
+		err := store.EntityRoleCreate(context.Background(), NewEntityRole().
+			SetEntityType("user").
+			SetEntityID(entityID).
+			SetRoleID("ROLE_ADMIN"))
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	// not part of the campaign
+	err := store.EntityRoleCreate(context.Background(), NewEntityRole().
+		SetEntityType("user").
+		SetEntityID("USER_01").
+		SetRoleID("ROLE_VIEWER"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	campaign := NewReviewCampaign().SetTitle("Q3 admin review")
+
+	if err := campaign.SetRoleIDs([]string{"ROLE_ADMIN"}); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.ReviewCampaignCreate(context.Background(), campaign, func(_ context.Context, entityRole EntityRoleInterface) (EntityRef, error) {
+		if entityRole.EntityID() == "USER_03" {
+			return EntityRef{}, nil
+		}
+
+		return NewEntityRef("user", "MANAGER_01"), nil
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	return campaign
+}
+
+func TestStoreReviewCampaignCreate(t *testing.T) {
+	store := initReviewStore(t)
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	campaign := createReviewCampaign(t, store)
+
+	items, err := store.ReviewItemList(context.Background(), campaign.ID(), EntityRef{})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(items) != 3 {
+		t.Fatal("unexpected items length:", len(items))
+	}
+
+	managerItems, err := store.ReviewItemList(context.Background(), campaign.ID(), NewEntityRef("user", "MANAGER_01"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(managerItems) != 2 {
+		t.Fatal("unexpected manager items length:", len(managerItems))
+	}
+
+	if !items[2].Reviewer().IsEmpty() || !items[2].IsPending() {
+		t.Fatal("third item MUST be pending and unassigned")
+	}
+
+	open, err := store.ReviewCampaignList(context.Background(), REVIEW_CAMPAIGN_STATUS_OPEN)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(open) != 1 || open[0].Title() != "Q3 admin review" {
+		t.Fatal("unexpected open campaigns:", len(open))
+	}
+}
+
+func TestStoreReviewCampaignCreate_SelfReview(t *testing.T) {
+	store := initReviewStore(t)
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	err := store.EntityRoleCreate(context.Background(), NewEntityRole().
+		SetEntityType("user").
+		SetEntityID("USER_01").
+		SetRoleID("ROLE_ADMIN"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	campaign := NewReviewCampaign().SetTitle("Self review")
+
+	if err := campaign.SetRoleIDs([]string{"ROLE_ADMIN"}); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// the holder is returned as the reviewer
+	err = store.ReviewCampaignCreate(context.Background(), campaign, func(_ context.Context, entityRole EntityRoleInterface) (EntityRef, error) {
+		return NewEntityRef(entityRole.EntityType(), entityRole.EntityID()), nil
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	items, err := store.ReviewItemList(context.Background(), campaign.ID(), EntityRef{})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(items) != 1 || !items[0].Reviewer().IsEmpty() {
+		t.Fatal("item MUST be left unassigned, when the holder would review itself")
+	}
+
+	err = store.ReviewItemDecide(context.Background(), items[0].ID(), NewEntityRef("user", "USER_01"), REVIEW_DECISION_KEEP, "")
+
+	if !errors.Is(err, ErrReviewerNotAllowed) {
+		t.Fatal("expected ErrReviewerNotAllowed, got:", err)
+	}
+}
+
+func TestStoreReviewCampaignDecideAndClose(t *testing.T) {
+	store := initReviewStore(t)
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	campaign := createReviewCampaign(t, store)
+
+	items, err := store.ReviewItemList(context.Background(), campaign.ID(), EntityRef{})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	manager := NewEntityRef("user", "MANAGER_01")
+
+	err = store.ReviewItemDecide(context.Background(), items[0].ID(), NewEntityRef("user", "USER_09"), REVIEW_DECISION_KEEP, "")
+
+	if !errors.Is(err, ErrReviewerNotAllowed) {
+		t.Fatal("expected ErrReviewerNotAllowed, got:", err)
+	}
+
+	if err := store.ReviewItemDecide(context.Background(), items[0].ID(), manager, REVIEW_DECISION_KEEP, "still needed"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.ReviewItemDecide(context.Background(), items[1].ID(), manager, REVIEW_DECISION_REVOKE, "left the team"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// entities cannot review their own assignments
+	err = store.ReviewItemAssignReviewer(context.Background(), items[2].ID(), NewEntityRef("user", "USER_03"))
+
+	if !errors.Is(err, ErrReviewerNotAllowed) {
+		t.Fatal("expected ErrReviewerNotAllowed, got:", err)
+	}
+
+	if err := store.ReviewItemAssignReviewer(context.Background(), items[2].ID(), manager); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	progress, err := store.ReviewCampaignProgress(context.Background(), campaign.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if progress.Total != 3 || progress.Pending != 1 || progress.Kept != 1 || progress.Revoked != 1 {
+		t.Fatal("unexpected progress:", progress)
+	}
+
+	revoked, err := store.ReviewCampaignClose(context.Background(), campaign.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if revoked != 1 {
+		t.Fatal("unexpected revoked count:", revoked)
+	}
+
+	entityRole, err := store.EntityRoleFindByEntityAndRole(context.Background(), "user", "USER_02", "ROLE_ADMIN")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if entityRole != nil {
+		t.Fatal("revoked assignment MUST be removed")
+	}
+
+	count, err := store.EntityRoleCount(context.Background(), NewEntityRoleQuery().SetRoleID("ROLE_ADMIN"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 2 {
+		t.Fatal("unexpected remaining assignments:", count)
+	}
+
+	err = store.ReviewItemDecide(context.Background(), items[2].ID(), manager, REVIEW_DECISION_KEEP, "")
+
+	if !errors.Is(err, ErrReviewCampaignClosed) {
+		t.Fatal("expected ErrReviewCampaignClosed, got:", err)
+	}
+
+	if _, err := store.ReviewCampaignClose(context.Background(), campaign.ID()); !errors.Is(err, ErrReviewCampaignClosed) {
+		t.Fatal("expected ErrReviewCampaignClosed, got:", err)
+	}
+}
+
+func TestStoreReviewCampaignClose_TransferredAssignment(t *testing.T) {
+	store := initReviewStore(t)
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	campaign := createReviewCampaign(t, store)
+
+	items, err := store.ReviewItemList(context.Background(), campaign.ID(), EntityRef{})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	item, found := lo.Find(items, func(item ReviewItemInterface) bool {
+		return item.EntityID() == "USER_02"
+	})
+
+	if !found {
+		t.Fatal("review item of USER_02 MUST be found")
+	}
+
+	if err := store.ReviewItemDecide(context.Background(), item.ID(), NewEntityRef("user", "MANAGER_01"), REVIEW_DECISION_REVOKE, "left the team"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// the assignment is moved in place, keeping its ID
+	if _, err := store.EntityTransferRoles(context.Background(), NewEntityRef("user", "USER_02"), NewEntityRef("user", "USER_05")); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	revoked, err := store.ReviewCampaignClose(context.Background(), campaign.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if revoked != 0 {
+		t.Fatal("unexpected revoked count:", revoked)
+	}
+
+	entityRole, err := store.EntityRoleFindByEntityAndRole(context.Background(), "user", "USER_05", "ROLE_ADMIN")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if entityRole == nil || entityRole.ID() != item.EntityRoleID() {
+		t.Fatal("the transferred assignment MUST be kept")
+	}
+}
+
+func TestStoreReviewCampaignReport(t *testing.T) {
+	store := initReviewStore(t)
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	campaign := createReviewCampaign(t, store)
+
+	items, err := store.ReviewItemList(context.Background(), campaign.ID(), EntityRef{})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.ReviewItemDecide(context.Background(), items[0].ID(), NewEntityRef("user", "MANAGER_01"), REVIEW_DECISION_REVOKE, "no longer, needed")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	buffer := bytes.Buffer{}
+
+	if err := store.ReviewCampaignReport(context.Background(), campaign.ID(), &buffer); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	records, err := csv.NewReader(&buffer).ReadAll()
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(records) != 4 {
+		t.Fatal("unexpected records length:", len(records))
+	}
+
+	if records[0][0] != COLUMN_CAMPAIGN_ID || records[0][8] != COLUMN_DECISION {
+		t.Fatal("unexpected header:", records[0])
+	}
+
+	if records[1][8] != REVIEW_DECISION_REVOKE || records[1][9] != "no longer, needed" || records[1][10] == "" {
+		t.Fatal("unexpected first record:", records[1])
+	}
+
+	if records[2][8] != REVIEW_DECISION_PENDING || records[2][10] != "" {
+		t.Fatal("unexpected second record:", records[2])
+	}
+
+	err = store.ReviewCampaignReport(context.Background(), "NOT_FOUND", &buffer)
+
+	if !errors.Is(err, ErrReviewCampaignNotFound) {
+		t.Fatal("expected ErrReviewCampaignNotFound, got:", err)
+	}
+}
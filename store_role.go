@@ -3,13 +3,15 @@ package rolestore
 import (
 	"context"
 	"errors"
+	"maps"
+	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
 	"github.com/dromara/carbon/v2"
 	"github.com/gouniverse/base/database"
-	"github.com/gouniverse/sb"
 	"github.com/samber/lo"
 	"github.com/spf13/cast"
 )
@@ -19,6 +21,10 @@ func (store *store) RoleCount(ctx context.Context, options RoleQueryInterface) (
 
 	q, _, err := store.roleSelectQuery(options)
 
+	if err != nil {
+		return -1, err
+	}
+
 	sqlStr, params, errSql := q.Prepared(true).
 		Limit(1).
 		Select(goqu.COUNT(goqu.Star()).As("count")).
@@ -165,6 +171,10 @@ func (store *store) RoleList(ctx context.Context, query RoleQueryInterface) ([]R
 
 	q, columns, err := store.roleSelectQuery(query)
 
+	if err != nil {
+		return []RoleInterface{}, err
+	}
+
 	sqlStr, sqlParams, errSql := q.Prepared(true).Select(columns...).ToSQL()
 
 	if errSql != nil {
@@ -193,6 +203,103 @@ func (store *store) RoleList(ctx context.Context, query RoleQueryInterface) ([]R
 	return list, nil
 }
 
+// RoleListByCursor returns a page of roles using keyset (cursor) pagination.
+// The query must have a limit, which is the page size. The next page is
+// requested by setting the returned NextCursor on the same query.
+func (store *store) RoleListByCursor(ctx context.Context, query RoleQueryInterface) (CursorPage[RoleInterface], error) {
+	page := CursorPage[RoleInterface]{Items: []RoleInterface{}}
+
+	if query == nil {
+		return page, errors.New("at role list by cursor > role query is nil")
+	}
+
+	if !query.HasLimit() {
+		return page, errors.New("at role list by cursor > limit is required")
+	}
+
+	orderBys := queryOrderBys(query.OrderBys(), query.OrderBy(), query.SortDirection())
+	keys := cursorKeys(orderBys, query.SortDirection())
+
+	if len(query.Columns()) > 0 {
+		query = query.Clone().SetColumns(cursorEnsureColumns(query.Columns(), keys))
+	}
+
+	q, columns, err := store.roleSelectQuery(query)
+
+	if err != nil {
+		return page, err
+	}
+
+	sqlStr, sqlParams, errSql := q.Prepared(true).
+		ClearOrder().
+		Order(cursorOrder(keys, store.roleTableName)...).
+		Limit(cast.ToUint(query.Limit() + 1)). // one more, to find if there is a next page
+		Select(columns...).
+		ToSQL()
+
+	if errSql != nil {
+		return page, errSql
+	}
+
+	store.logSql("select", sqlStr, sqlParams...)
+
+	if store.db == nil {
+		return page, errors.New("rolestore: database is nil")
+	}
+
+	modelMaps, err := database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, sqlParams...)
+
+	if err != nil {
+		return page, err
+	}
+
+	if len(modelMaps) > query.Limit() {
+		page.HasMore = true
+		modelMaps = modelMaps[:query.Limit()]
+	}
+
+	for _, modelMap := range modelMaps {
+		page.Items = append(page.Items, NewRoleFromExistingData(modelMap))
+	}
+
+	if page.HasMore {
+		page.NextCursor, err = cursorEncode(keys, modelMaps[len(modelMaps)-1])
+
+		if err != nil {
+			return page, err
+		}
+	}
+
+	return page, nil
+}
+
+// RoleListPaged returns a page of roles together with the total count
+// and the pagination details, based on the offset and limit of the query.
+// The query is cloned, so the caller's query is not modified.
+func (store *store) RoleListPaged(ctx context.Context, query RoleQueryInterface) (PagedResult[RoleInterface], error) {
+	if query == nil {
+		return PagedResult[RoleInterface]{}, errors.New("at role list paged > role query is nil")
+	}
+
+	if err := query.Validate(); err != nil {
+		return PagedResult[RoleInterface]{}, err
+	}
+
+	list, err := store.RoleList(ctx, query.Clone())
+
+	if err != nil {
+		return PagedResult[RoleInterface]{}, err
+	}
+
+	total, err := store.RoleCount(ctx, query.Clone())
+
+	if err != nil {
+		return PagedResult[RoleInterface]{}, err
+	}
+
+	return newPagedResult(list, total, query.Offset(), query.Limit()), nil
+}
+
 func (store *store) RoleSoftDelete(ctx context.Context, role RoleInterface) error {
 	if role == nil {
 		return errors.New("at role soft delete > role is nil")
@@ -222,17 +329,22 @@ func (store *store) RoleUpdate(ctx context.Context, role RoleInterface) error {
 
 	dataChanged := role.DataChanged()
 
-	delete(dataChanged, COLUMN_ID) // ID is not updateable
+	delete(dataChanged, COLUMN_ID)      // ID is not updateable
+	delete(dataChanged, COLUMN_VERSION) // version is managed by the store
 
 	if len(dataChanged) < 1 {
 		return nil
 	}
 
+	version := role.Version()
+	dataChanged[COLUMN_VERSION] = cast.ToString(version + 1)
+
 	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
 		Update(store.roleTableName).
 		Prepared(true).
 		Set(dataChanged).
 		Where(goqu.C(COLUMN_ID).Eq(role.ID())).
+		Where(goqu.C(COLUMN_VERSION).Eq(version)).
 		ToSQL()
 
 	if errSql != nil {
@@ -245,11 +357,26 @@ func (store *store) RoleUpdate(ctx context.Context, role RoleInterface) error {
 		return errors.New("rolestore: database is nil")
 	}
 
-	_, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+	result, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
 
+	if err != nil {
+		return err
+	}
+
+	if affected < 1 {
+		return ErrConflict
+	}
+
+	role.SetVersion(version + 1)
 	role.MarkAsNotDirty()
 
-	return err
+	return nil
 }
 
 func (store *store) roleSelectQuery(options RoleQueryInterface) (selectDataset *goqu.SelectDataset, columns []any, err error) {
@@ -271,6 +398,10 @@ func (store *store) roleSelectQuery(options RoleQueryInterface) (selectDataset *
 		q = q.Where(goqu.C(COLUMN_ID).In(options.IDIn()))
 	}
 
+	if options.HasIDNotIn() {
+		q = q.Where(goqu.C(COLUMN_ID).NotIn(options.IDNotIn()))
+	}
+
 	if options.HasStatus() {
 		q = q.Where(goqu.C(COLUMN_STATUS).Eq(options.Status()))
 	}
@@ -279,14 +410,26 @@ func (store *store) roleSelectQuery(options RoleQueryInterface) (selectDataset *
 		q = q.Where(goqu.C(COLUMN_STATUS).In(options.StatusIn()))
 	}
 
+	if options.HasStatusNotIn() {
+		q = q.Where(goqu.C(COLUMN_STATUS).NotIn(options.StatusNotIn()))
+	}
+
 	if options.HasHandle() {
 		q = q.Where(goqu.C(COLUMN_HANDLE).Eq(options.Handle()))
 	}
 
+	if options.HasHandleIn() {
+		q = q.Where(goqu.C(COLUMN_HANDLE).In(options.HandleIn()))
+	}
+
 	if options.HasTitleLike() {
 		q = q.Where(goqu.C(COLUMN_TITLE).ILike(`%` + options.TitleLike() + `%`))
 	}
 
+	if options.HasSearch() {
+		q = q.Where(roleSearchCondition(options.Search()))
+	}
+
 	if options.HasCreatedAtGte() && options.HasCreatedAtLte() {
 		q = q.Where(
 			goqu.C(COLUMN_CREATED_AT).Gte(options.CreatedAtGte()),
@@ -298,6 +441,46 @@ func (store *store) roleSelectQuery(options RoleQueryInterface) (selectDataset *
 		q = q.Where(goqu.C(COLUMN_CREATED_AT).Lte(options.CreatedAtLte()))
 	}
 
+	if options.HasUpdatedAtGte() && options.HasUpdatedAtLte() {
+		q = q.Where(
+			goqu.C(COLUMN_UPDATED_AT).Gte(options.UpdatedAtGte()),
+			goqu.C(COLUMN_UPDATED_AT).Lte(options.UpdatedAtLte()),
+		)
+	} else if options.HasUpdatedAtGte() {
+		q = q.Where(goqu.C(COLUMN_UPDATED_AT).Gte(options.UpdatedAtGte()))
+	} else if options.HasUpdatedAtLte() {
+		q = q.Where(goqu.C(COLUMN_UPDATED_AT).Lte(options.UpdatedAtLte()))
+	}
+
+	metaKeys := slices.Sorted(maps.Keys(options.MetaEquals())) // sorted, for a stable SQL
+
+	for _, key := range metaKeys {
+		q = q.Where(store.metaExpression(goqu.C(COLUMN_METAS), key).Eq(options.MetaEquals()[key]))
+	}
+
+	for _, key := range options.MetaExists() {
+		q = q.Where(store.metaExpression(goqu.C(COLUMN_METAS), key).IsNotNull())
+	}
+
+	orderBys := queryOrderBys(options.OrderBys(), options.OrderBy(), options.SortDirection())
+	keys := cursorKeys(orderBys, options.SortDirection())
+
+	if options.HasCursor() {
+		cursor, err := cursorDecode(options.Cursor())
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		condition, err := cursorCondition(keys, cursor, store.roleTableName)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		q = q.Where(condition)
+	}
+
 	if !options.IsCountOnly() {
 		if options.HasLimit() {
 			q = q.Limit(cast.ToUint(options.Limit()))
@@ -308,13 +491,10 @@ func (store *store) roleSelectQuery(options RoleQueryInterface) (selectDataset *
 		}
 	}
 
-	if options.HasOrderBy() {
-		sort := lo.Ternary(options.HasSortDirection(), options.SortDirection(), sb.DESC)
-		if strings.EqualFold(sort, sb.ASC) {
-			q = q.Order(goqu.I(options.OrderBy()).Asc())
-		} else {
-			q = q.Order(goqu.I(options.OrderBy()).Desc())
-		}
+	if options.HasCursor() {
+		q = q.Order(cursorOrder(keys, store.roleTableName)...)
+	} else if len(orderBys) > 0 {
+		q = q.Order(orderExpressions(orderBys, store.roleTableName)...)
 	}
 
 	columns = []any{}
@@ -332,3 +512,25 @@ func (store *store) roleSelectQuery(options RoleQueryInterface) (selectDataset *
 
 	return q.Where(softDeleted), columns, nil
 }
+
+// likeEscaper escapes the LIKE wildcards, so the search term matches literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// roleSearchCondition returns a case-insensitive condition matching the
+// search term anywhere in the handle, title or memo of the role.
+// LOWER(...) LIKE is used, as it behaves the same on all supported dialects.
+// The escape character is passed as a value, as MySQL and Postgres quote
+// a backslash in string literals differently
+func roleSearchCondition(search string) exp.Expression {
+	term := "%" + likeEscaper.Replace(strings.ToLower(strings.TrimSpace(search))) + "%"
+
+	like := func(column string) exp.Expression {
+		return goqu.L("LOWER(?) LIKE ? ESCAPE ?", goqu.C(column), term, `\`)
+	}
+
+	return goqu.Or(
+		like(COLUMN_HANDLE),
+		like(COLUMN_TITLE),
+		like(COLUMN_MEMO),
+	)
+}
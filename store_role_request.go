@@ -0,0 +1,547 @@
+package rolestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/base/database"
+	"github.com/samber/lo"
+)
+
+// RoleRequestHook is called after a role request is saved, with one of the
+// ROLE_REQUEST_EVENT_* constants as event, i.e. for notifying the approvers
+// of a new request, or the requester of the decision
+type RoleRequestHook func(ctx context.Context, event string, request RoleRequestInterface)
+
+// RoleRequestCreate creates a pending request of an entity for a role.
+// The role must be active and allow the entity type, and the entity must
+// neither hold the role already, nor have a pending request for it
+func (store *store) RoleRequestCreate(ctx context.Context, request RoleRequestInterface) error {
+	if err := store.roleRequestEnabled("RoleRequestCreate"); err != nil {
+		return err
+	}
+
+	if request == nil {
+		return errors.New("rolestore > RoleRequestCreate. request is nil")
+	}
+
+	if request.RoleID() == "" {
+		return errors.New("rolestore > RoleRequestCreate. request roleID is empty")
+	}
+
+	if NewEntityRef(request.EntityType(), request.EntityID()).IsEmpty() {
+		return errors.New("rolestore > RoleRequestCreate. request entity type and ID are required")
+	}
+
+	err := store.WithTx(ctx, func(txCtx context.Context) error {
+		if err := store.roleRequestValidate(txCtx, request); err != nil {
+			return err
+		}
+
+		request.SetStatus(ROLE_REQUEST_STATUS_PENDING)
+		request.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+		request.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+		sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+			Insert(store.roleRequestTableName).
+			Prepared(true).
+			Rows(request.Data()).
+			ToSQL()
+
+		if errSql != nil {
+			return errSql
+		}
+
+		store.logSql("insert", sqlStr, params...)
+
+		_, err := database.Execute(store.toQuerableContext(txCtx), sqlStr, params...)
+
+		return err
+	})
+
+	if err != nil {
+		return err
+	}
+
+	request.MarkAsNotDirty()
+
+	store.roleRequestNotify(ctx, ROLE_REQUEST_EVENT_CREATED, request)
+
+	return nil
+}
+
+// RoleRequestApprove approves a pending request, and assigns the role to the
+// requesting entity, recording the approver as granted by and the
+// justification as reason. The approver must be one of the approvers of the
+// role, and cannot approve its own request. If the assignment fails (i.e. it
+// would break a separation of duties constraint), the request stays pending
+func (store *store) RoleRequestApprove(ctx context.Context, requestID string, approver EntityRef, note string) (EntityRoleInterface, error) {
+	if err := store.roleRequestEnabled("RoleRequestApprove"); err != nil {
+		return nil, err
+	}
+
+	if approver.IsEmpty() {
+		return nil, errors.New("rolestore > RoleRequestApprove. approver entity type and ID are required")
+	}
+
+	var entityRole EntityRoleInterface
+
+	request, err := store.roleRequestTransition(ctx, requestID, func(txCtx context.Context, request RoleRequestInterface) error {
+		if err := store.roleRequestCheckApprover(txCtx, request, approver); err != nil {
+			return err
+		}
+
+		entityRole = NewEntityRole().
+			SetEntityType(request.EntityType()).
+			SetEntityID(request.EntityID()).
+			SetRoleID(request.RoleID()).
+			SetGrantedByType(approver.EntityType).
+			SetGrantedByID(approver.EntityID).
+			SetReason(request.Justification()).
+			SetSource(ENTITY_ROLE_SOURCE_REQUEST)
+
+		if err := store.EntityRoleCreate(txCtx, entityRole); err != nil {
+			return err
+		}
+
+		request.SetStatus(ROLE_REQUEST_STATUS_APPROVED)
+		request.SetDecidedByType(approver.EntityType)
+		request.SetDecidedByID(approver.EntityID)
+		request.SetDecisionNote(note)
+		request.SetEntityRoleID(entityRole.ID())
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	store.roleRequestNotify(ctx, ROLE_REQUEST_EVENT_APPROVED, request)
+
+	return entityRole, nil
+}
+
+// RoleRequestReject rejects a pending request. The approver must be
+// one of the approvers of the role, and cannot reject its own request
+func (store *store) RoleRequestReject(ctx context.Context, requestID string, approver EntityRef, note string) error {
+	if err := store.roleRequestEnabled("RoleRequestReject"); err != nil {
+		return err
+	}
+
+	if approver.IsEmpty() {
+		return errors.New("rolestore > RoleRequestReject. approver entity type and ID are required")
+	}
+
+	request, err := store.roleRequestTransition(ctx, requestID, func(txCtx context.Context, request RoleRequestInterface) error {
+		if err := store.roleRequestCheckApprover(txCtx, request, approver); err != nil {
+			return err
+		}
+
+		request.SetStatus(ROLE_REQUEST_STATUS_REJECTED)
+		request.SetDecidedByType(approver.EntityType)
+		request.SetDecidedByID(approver.EntityID)
+		request.SetDecisionNote(note)
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	store.roleRequestNotify(ctx, ROLE_REQUEST_EVENT_REJECTED, request)
+
+	return nil
+}
+
+// RoleRequestCancel cancels a pending request. Checking that the caller
+// is the requesting entity is left to the application
+func (store *store) RoleRequestCancel(ctx context.Context, requestID string) error {
+	if err := store.roleRequestEnabled("RoleRequestCancel"); err != nil {
+		return err
+	}
+
+	request, err := store.roleRequestTransition(ctx, requestID, func(_ context.Context, request RoleRequestInterface) error {
+		request.SetStatus(ROLE_REQUEST_STATUS_CANCELLED)
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	store.roleRequestNotify(ctx, ROLE_REQUEST_EVENT_CANCELLED, request)
+
+	return nil
+}
+
+// RoleRequestExpireStale marks the pending requests past their expiry date
+// as expired, and returns how many were expired. It is meant to be run
+// periodically, i.e. by a scheduled task
+func (store *store) RoleRequestExpireStale(ctx context.Context) (int64, error) {
+	if err := store.roleRequestEnabled("RoleRequestExpireStale"); err != nil {
+		return 0, err
+	}
+
+	expired := []RoleRequestInterface{}
+
+	err := store.WithTx(ctx, func(txCtx context.Context) error {
+		stale, err := store.roleRequestList(
+			txCtx,
+			NewRoleRequestQuery().SetStatus(ROLE_REQUEST_STATUS_PENDING),
+			goqu.C(COLUMN_EXPIRES_AT).Lte(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)),
+		)
+
+		if err != nil {
+			return err
+		}
+
+		for _, request := range stale {
+			request.SetStatus(ROLE_REQUEST_STATUS_EXPIRED)
+
+			if err := store.roleRequestUpdate(txCtx, request); err != nil {
+				return err
+			}
+
+			expired = append(expired, request)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	for _, request := range expired {
+		store.roleRequestNotify(ctx, ROLE_REQUEST_EVENT_EXPIRED, request)
+	}
+
+	return int64(len(expired)), nil
+}
+
+// RoleRequestFindByID returns a role request by its ID, or nil if not found
+func (store *store) RoleRequestFindByID(ctx context.Context, id string) (RoleRequestInterface, error) {
+	if id == "" {
+		return nil, errors.New("rolestore > RoleRequestFindByID. request id is empty")
+	}
+
+	list, err := store.RoleRequestList(ctx, NewRoleRequestQuery().SetID(id).SetLimit(1))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) > 0 {
+		return list[0], nil
+	}
+
+	return nil, nil
+}
+
+// RoleRequestList returns the role requests matching the query,
+// ordered by creation date unless the query sets the order
+func (store *store) RoleRequestList(ctx context.Context, query RoleRequestQueryInterface) ([]RoleRequestInterface, error) {
+	if query == nil {
+		return []RoleRequestInterface{}, errors.New("rolestore > RoleRequestList. query is nil")
+	}
+
+	return store.roleRequestList(ctx, query)
+}
+
+// RoleRequestListForApprover returns the pending, unexpired requests
+// for the roles the entity is an approver of, oldest first
+func (store *store) RoleRequestListForApprover(ctx context.Context, approver EntityRef) ([]RoleRequestInterface, error) {
+	if approver.IsEmpty() {
+		return []RoleRequestInterface{}, errors.New("rolestore > RoleRequestListForApprover. approver entity type and ID are required")
+	}
+
+	roles, err := store.RoleList(ctx, NewRoleQuery().SetMetaExists(ROLE_META_APPROVERS))
+
+	if err != nil {
+		return []RoleRequestInterface{}, err
+	}
+
+	roleIDs := lo.FilterMap(roles, func(role RoleInterface, _ int) (string, bool) {
+		return role.ID(), role.IsApprover(approver)
+	})
+
+	if len(roleIDs) == 0 {
+		return []RoleRequestInterface{}, nil
+	}
+
+	return store.roleRequestList(
+		ctx,
+		NewRoleRequestQuery().
+			SetStatus(ROLE_REQUEST_STATUS_PENDING).
+			SetRoleIDIn(roleIDs),
+		goqu.C(COLUMN_EXPIRES_AT).Gt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)),
+	)
+}
+
+// roleRequestTransition loads a pending, unexpired request in a transaction,
+// lets change apply the decision to it, and saves it. The saved request is
+// returned, so that the hook can be called once the transaction is done
+func (store *store) roleRequestTransition(
+	ctx context.Context,
+	requestID string,
+	change func(txCtx context.Context, request RoleRequestInterface) error,
+) (RoleRequestInterface, error) {
+	if requestID == "" {
+		return nil, errors.New("rolestore > RoleRequest. request id is empty")
+	}
+
+	var request RoleRequestInterface
+
+	err := store.WithTx(ctx, func(txCtx context.Context) error {
+		var err error
+		request, err = store.RoleRequestFindByID(txCtx, requestID)
+
+		if err != nil {
+			return err
+		}
+
+		if request == nil {
+			return ErrRoleRequestNotFound
+		}
+
+		if !request.IsPending() {
+			return fmt.Errorf("%w: request %s is %s", ErrRoleRequestNotPending, request.ID(), request.Status())
+		}
+
+		if request.IsExpired() {
+			return fmt.Errorf("%w: request %s has expired", ErrRoleRequestNotPending, request.ID())
+		}
+
+		if err := change(txCtx, request); err != nil {
+			return err
+		}
+
+		request.SetDecidedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+		return store.roleRequestUpdate(txCtx, request)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// roleRequestValidate checks a new request against the role,
+// the existing assignments and the pending requests
+func (store *store) roleRequestValidate(ctx context.Context, request RoleRequestInterface) error {
+	if err := store.validateEntity(request.EntityType(), request.EntityID()); err != nil {
+		return err
+	}
+
+	role, err := store.RoleFindByID(ctx, request.RoleID())
+
+	if err != nil {
+		return err
+	}
+
+	if role == nil || !role.IsActive() {
+		return errors.New("rolestore > RoleRequestCreate. role not found or not active: " + request.RoleID())
+	}
+
+	if !role.AllowsEntityType(request.EntityType()) {
+		return fmt.Errorf("%w: role %s, entity type %s", ErrEntityTypeNotAllowed, role.Handle(), request.EntityType())
+	}
+
+	entityRole, err := store.EntityRoleFindByEntityAndRole(ctx, request.EntityType(), request.EntityID(), request.RoleID())
+
+	if err != nil {
+		return err
+	}
+
+	if entityRole != nil {
+		return errors.New("rolestore > RoleRequestCreate. entity already holds the role")
+	}
+
+	// requests past their expiry, not yet swept by RoleRequestExpireStale, do not count
+	pending, err := store.roleRequestList(
+		ctx,
+		NewRoleRequestQuery().
+			SetEntityType(request.EntityType()).
+			SetEntityID(request.EntityID()).
+			SetRoleID(request.RoleID()).
+			SetStatus(ROLE_REQUEST_STATUS_PENDING).
+			SetLimit(1),
+		goqu.C(COLUMN_EXPIRES_AT).Gt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if len(pending) > 0 {
+		return errors.New("rolestore > RoleRequestCreate. entity already has a pending request for the role")
+	}
+
+	return nil
+}
+
+// roleRequestCheckApprover returns ErrRoleRequestApproverNotAllowed, if the
+// approver is not an approver of the requested role, or is the requester
+func (store *store) roleRequestCheckApprover(ctx context.Context, request RoleRequestInterface, approver EntityRef) error {
+	if approver == NewEntityRef(request.EntityType(), request.EntityID()) {
+		return fmt.Errorf("%w: entities cannot decide on their own requests", ErrRoleRequestApproverNotAllowed)
+	}
+
+	role, err := store.RoleFindByID(ctx, request.RoleID())
+
+	if err != nil {
+		return err
+	}
+
+	if role == nil || !role.IsApprover(approver) {
+		return fmt.Errorf("%w: %s %s", ErrRoleRequestApproverNotAllowed, approver.EntityType, approver.EntityID)
+	}
+
+	return nil
+}
+
+// roleRequestList returns the requests matching the query and the conditions
+func (store *store) roleRequestList(ctx context.Context, query RoleRequestQueryInterface, conditions ...goqu.Expression) ([]RoleRequestInterface, error) {
+	if err := store.roleRequestEnabled("RoleRequestList"); err != nil {
+		return []RoleRequestInterface{}, err
+	}
+
+	if err := query.Validate(); err != nil {
+		return []RoleRequestInterface{}, err
+	}
+
+	q := goqu.Dialect(store.dbDriverName).
+		From(store.roleRequestTableName).
+		Prepared(true).
+		Where(conditions...)
+
+	if query.HasID() {
+		q = q.Where(goqu.C(COLUMN_ID).Eq(query.ID()))
+	}
+
+	if query.HasEntityType() {
+		q = q.Where(goqu.C(COLUMN_ENTITY_TYPE).Eq(query.EntityType()))
+	}
+
+	if query.HasEntityID() {
+		q = q.Where(goqu.C(COLUMN_ENTITY_ID).Eq(query.EntityID()))
+	}
+
+	if query.HasRoleID() {
+		q = q.Where(goqu.C(COLUMN_ROLE_ID).Eq(query.RoleID()))
+	}
+
+	if query.HasRoleIDIn() {
+		q = q.Where(goqu.C(COLUMN_ROLE_ID).In(query.RoleIDIn()))
+	}
+
+	if query.HasStatus() {
+		q = q.Where(goqu.C(COLUMN_STATUS).Eq(query.Status()))
+	}
+
+	if query.HasStatusIn() {
+		q = q.Where(goqu.C(COLUMN_STATUS).In(query.StatusIn()))
+	}
+
+	orderBys := queryOrderBys([]OrderBy{}, query.OrderBy(), query.SortDirection())
+
+	if len(orderBys) == 0 {
+		orderBys = []OrderBy{{Column: COLUMN_CREATED_AT, Direction: ASC}, {Column: COLUMN_ID, Direction: ASC}}
+	}
+
+	q = q.Order(orderExpressions(orderBys, store.roleRequestTableName)...)
+
+	if query.HasLimit() {
+		q = q.Limit(uint(query.Limit()))
+	}
+
+	if query.HasOffset() {
+		q = q.Offset(uint(query.Offset()))
+	}
+
+	sqlStr, params, errSql := q.ToSQL()
+
+	if errSql != nil {
+		return []RoleRequestInterface{}, errSql
+	}
+
+	rows, err := store.selectToMaps(ctx, sqlStr, params...)
+
+	if err != nil {
+		return []RoleRequestInterface{}, err
+	}
+
+	return lo.Map(rows, func(row map[string]string, _ int) RoleRequestInterface {
+		return NewRoleRequestFromExistingData(row)
+	}), nil
+}
+
+// roleRequestUpdate saves the changed fields of a request, which must still
+// be pending in the database, otherwise ErrConflict is returned, as it was
+// decided on concurrently
+func (store *store) roleRequestUpdate(ctx context.Context, request RoleRequestInterface) error {
+	request.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	dataChanged := request.DataChanged()
+
+	delete(dataChanged, COLUMN_ID) // ID is not updateable
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Update(store.roleRequestTableName).
+		Prepared(true).
+		Set(dataChanged).
+		Where(
+			goqu.C(COLUMN_ID).Eq(request.ID()),
+			goqu.C(COLUMN_STATUS).Eq(ROLE_REQUEST_STATUS_PENDING),
+		).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	store.logSql("update", sqlStr, params...)
+
+	result, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrConflict
+	}
+
+	request.MarkAsNotDirty()
+
+	return nil
+}
+
+// roleRequestNotify calls the role request hook, if set
+func (store *store) roleRequestNotify(ctx context.Context, event string, request RoleRequestInterface) {
+	if store.roleRequestHook != nil {
+		store.roleRequestHook(ctx, event, request)
+	}
+}
+
+// roleRequestEnabled returns an error, if no role request table is configured
+func (store *store) roleRequestEnabled(method string) error {
+	if store.roleRequestTableName == "" {
+		return errors.New("rolestore > " + method + ". role requests are disabled, RoleRequestTableName is not set")
+	}
+
+	return nil
+}
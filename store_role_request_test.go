@@ -0,0 +1,317 @@
+package rolestore
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/dromara/carbon/v2"
+)
+
+type roleRequestEvent struct {
+	event     string
+	requestID string
+}
+
+func initRoleRequestStore(t *testing.T, hook RoleRequestHook) StoreInterface {
+	return initStoreWithOptions(t, NewStoreOptions{
+		RoleRequestTableName: "roles_role_request_table",
+		RoleRequestHook:      hook,
+	})
+}
+
+func createApprovableRole(t *testing.T, store StoreInterface, approvers []EntityRef) RoleInterface {
+	return createTestRole(t, store, "payments-admin", func(role RoleInterface) error {
+		return role.SetApprovers(approvers)
+	})
+}
+
+func TestStoreRoleRequestApprove(t *testing.T) {
+	events := []roleRequestEvent{}
+
+	store := initRoleRequestStore(t, func(_ context.Context, event string, request RoleRequestInterface) {
+		events = append(events, roleRequestEvent{event: event, requestID: request.ID()})
+	})
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	approver := NewEntityRef("user", "MANAGER_01")
+	role := createApprovableRole(t, store, []EntityRef{approver})
+
+	request := NewRoleRequest().
+		SetEntityType("user").
+		SetEntityID("USER_01").
+		SetRoleID(role.ID()).
+		SetJustification("month end closing")
+
+	if err := store.RoleRequestCreate(context.Background(), request); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err := store.RoleRequestCreate(context.Background(), NewRoleRequest().
+		SetEntityType("user").
+		SetEntityID("USER_01").
+		SetRoleID(role.ID()))
+
+	if err == nil {
+		t.Fatal("error MUST NOT be nil for a second pending request")
+	}
+
+	// not an approver
+	_, err = store.RoleRequestApprove(context.Background(), request.ID(), NewEntityRef("user", "USER_02"), "")
+
+	if !errors.Is(err, ErrRoleRequestApproverNotAllowed) {
+		t.Fatal("expected ErrRoleRequestApproverNotAllowed, got:", err)
+	}
+
+	entityRole, err := store.RoleRequestApprove(context.Background(), request.ID(), approver, "approved for Q4")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if entityRole.Source() != ENTITY_ROLE_SOURCE_REQUEST {
+		t.Fatal("unexpected source:", entityRole.Source())
+	}
+
+	if entityRole.GrantedByID() != "MANAGER_01" || entityRole.Reason() != "month end closing" {
+		t.Fatal("unexpected provenance:", entityRole.GrantedByID(), entityRole.Reason())
+	}
+
+	found, err := store.RoleRequestFindByID(context.Background(), request.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if found.Status() != ROLE_REQUEST_STATUS_APPROVED {
+		t.Fatal("unexpected status:", found.Status())
+	}
+
+	if found.EntityRoleID() != entityRole.ID() || found.DecisionNote() != "approved for Q4" {
+		t.Fatal("unexpected decision:", found.EntityRoleID(), found.DecisionNote())
+	}
+
+	assigned, err := store.EntityRoleFindByEntityAndRole(context.Background(), "user", "USER_01", role.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if assigned == nil {
+		t.Fatal("role MUST be assigned after approval")
+	}
+
+	_, err = store.RoleRequestApprove(context.Background(), request.ID(), approver, "")
+
+	if !errors.Is(err, ErrRoleRequestNotPending) {
+		t.Fatal("expected ErrRoleRequestNotPending, got:", err)
+	}
+
+	if len(events) != 2 {
+		t.Fatal("unexpected events length:", len(events))
+	}
+
+	if events[0].event != ROLE_REQUEST_EVENT_CREATED || events[1].event != ROLE_REQUEST_EVENT_APPROVED {
+		t.Fatal("unexpected events:", events)
+	}
+}
+
+func TestStoreRoleRequestRejectAndCancel(t *testing.T) {
+	store := initRoleRequestStore(t, nil)
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	approver := NewEntityRef("user", "MANAGER_01")
+	role := createApprovableRole(t, store, []EntityRef{approver, NewEntityRef("user", "USER_01")})
+
+	rejected := NewRoleRequest().SetEntityType("user").SetEntityID("USER_01").SetRoleID(role.ID())
+
+	if err := store.RoleRequestCreate(context.Background(), rejected); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// approvers cannot decide on their own requests
+	err := store.RoleRequestReject(context.Background(), rejected.ID(), NewEntityRef("user", "USER_01"), "")
+
+	if !errors.Is(err, ErrRoleRequestApproverNotAllowed) {
+		t.Fatal("expected ErrRoleRequestApproverNotAllowed, got:", err)
+	}
+
+	if err := store.RoleRequestReject(context.Background(), rejected.ID(), approver, "not needed"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	cancelled := NewRoleRequest().SetEntityType("user").SetEntityID("USER_02").SetRoleID(role.ID())
+
+	if err := store.RoleRequestCreate(context.Background(), cancelled); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.RoleRequestCancel(context.Background(), cancelled.ID()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	list, err := store.RoleRequestList(context.Background(), NewRoleRequestQuery().
+		SetStatusIn([]string{ROLE_REQUEST_STATUS_REJECTED, ROLE_REQUEST_STATUS_CANCELLED}).
+		SetOrderBy(COLUMN_ENTITY_ID).
+		SetSortDirection(ASC))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(list) != 2 {
+		t.Fatal("unexpected requests length:", len(list))
+	}
+
+	if list[0].Status() != ROLE_REQUEST_STATUS_REJECTED || list[0].DecidedByID() != "MANAGER_01" {
+		t.Fatal("unexpected first request:", list[0].Status(), list[0].DecidedByID())
+	}
+
+	if list[1].Status() != ROLE_REQUEST_STATUS_CANCELLED {
+		t.Fatal("unexpected second request status:", list[1].Status())
+	}
+
+	count, err := store.EntityRoleCount(context.Background(), NewEntityRoleQuery().SetRoleID(role.ID()))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 0 {
+		t.Fatal("role MUST NOT be assigned, found:", count)
+	}
+}
+
+func TestStoreRoleRequestExpireStale(t *testing.T) {
+	events := []roleRequestEvent{}
+
+	store := initRoleRequestStore(t, func(_ context.Context, event string, request RoleRequestInterface) {
+		events = append(events, roleRequestEvent{event: event, requestID: request.ID()})
+	})
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	approver := NewEntityRef("user", "MANAGER_01")
+	role := createApprovableRole(t, store, []EntityRef{approver})
+
+	stale := NewRoleRequest().
+		SetEntityType("user").
+		SetEntityID("USER_01").
+		SetRoleID(role.ID()).
+		SetExpiresAt(carbon.Now(carbon.UTC).SubHour().ToDateTimeString(carbon.UTC))
+
+	fresh := NewRoleRequest().
+		SetEntityType("user").
+		SetEntityID("USER_02").
+		SetRoleID(role.ID())
+
+	for _, request := range []RoleRequestInterface{stale, fresh} {
+		if err := store.RoleRequestCreate(context.Background(), request); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	pending, err := store.RoleRequestListForApprover(context.Background(), approver)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(pending) != 1 || pending[0].ID() != fresh.ID() {
+		t.Fatal("unexpected pending requests:", len(pending))
+	}
+
+	_, err = store.RoleRequestApprove(context.Background(), stale.ID(), approver, "")
+
+	if !errors.Is(err, ErrRoleRequestNotPending) {
+		t.Fatal("expected ErrRoleRequestNotPending, got:", err)
+	}
+
+	// a stale request, not swept yet, does not block a new one
+	err = store.RoleRequestCreate(context.Background(), NewRoleRequest().
+		SetEntityType("user").
+		SetEntityID("USER_01").
+		SetRoleID(role.ID()))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	expired, err := store.RoleRequestExpireStale(context.Background())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if expired != 1 {
+		t.Fatal("unexpected expired count:", expired)
+	}
+
+	found, err := store.RoleRequestFindByID(context.Background(), stale.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if found.Status() != ROLE_REQUEST_STATUS_EXPIRED {
+		t.Fatal("unexpected status:", found.Status())
+	}
+
+	last := events[len(events)-1]
+
+	if last.event != ROLE_REQUEST_EVENT_EXPIRED || last.requestID != stale.ID() {
+		t.Fatal("unexpected last event:", last)
+	}
+
+	others, err := store.RoleRequestListForApprover(context.Background(), NewEntityRef("user", "USER_09"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(others) != 0 {
+		t.Fatal("unexpected requests for a non approver:", len(others))
+	}
+}
+
+func TestRoleSetApprovers_Separators(t *testing.T) {
+	role := NewRole()
+
+	invalid := []EntityRef{
+		NewEntityRef("user,group", "MANAGER_01"),
+		NewEntityRef("user:admin", "MANAGER_01"),
+		NewEntityRef("user", "MANAGER_01,MANAGER_02"),
+	}
+
+	for _, approver := range invalid {
+		if err := role.SetApprovers([]EntityRef{approver}); err == nil {
+			t.Fatal("error MUST NOT be nil for approver:", approver)
+		}
+	}
+
+	// a colon in the ID is kept, as the type is cut at the first colon
+	approvers := []EntityRef{NewEntityRef("user", "MANAGER_01"), NewEntityRef("service", "urn:ops")}
+
+	if err := role.SetApprovers(approvers); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !slices.Equal(role.Approvers(), approvers) {
+		t.Fatal("unexpected approvers:", role.Approvers())
+	}
+}
@@ -2,6 +2,7 @@ package rolestore
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
 
@@ -536,3 +537,507 @@ func TestStoreRoleSoftDeleteByID(t *testing.T) {
 		t.Fatal("Role MUST be soft deleted")
 	}
 }
+
+func TestStoreRoleUpdate(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	role := NewRole().
+		SetStatus(ROLE_STATUS_ACTIVE).
+		SetHandle("ROLE_HANDLE").
+		SetTitle("ROLE_TITLE")
+
+	err = store.RoleCreate(context.Background(), role)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if role.Version() != 1 {
+		t.Fatal("Version MUST be 1, found:", role.Version())
+	}
+
+	role.SetTitle("ROLE_TITLE_2")
+
+	err = store.RoleUpdate(context.Background(), role)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if role.Version() != 2 {
+		t.Fatal("Version MUST be 2, found:", role.Version())
+	}
+
+	roleFound, err := store.RoleFindByID(context.Background(), role.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if roleFound == nil {
+		t.Fatal("Role MUST NOT be nil")
+	}
+
+	if roleFound.Title() != "ROLE_TITLE_2" {
+		t.Fatal("Title MUST be ROLE_TITLE_2, found:", roleFound.Title())
+	}
+
+	if roleFound.Version() != 2 {
+		t.Fatal("Version MUST be 2, found:", roleFound.Version())
+	}
+}
+
+func TestStoreRoleUpdate_Conflict(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	role := NewRole().
+		SetStatus(ROLE_STATUS_ACTIVE).
+		SetHandle("ROLE_HANDLE").
+		SetTitle("ROLE_TITLE")
+
+	err = store.RoleCreate(context.Background(), role)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	role1, err := store.RoleFindByID(context.Background(), role.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	role2, err := store.RoleFindByID(context.Background(), role.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.RoleUpdate(context.Background(), role1.SetTitle("ROLE_TITLE_1"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.RoleUpdate(context.Background(), role2.SetTitle("ROLE_TITLE_2"))
+
+	if !errors.Is(err, ErrConflict) {
+		t.Fatal("must return ErrConflict as role was modified concurrently, found:", err)
+	}
+
+	roleFound, err := store.RoleFindByID(context.Background(), role.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if roleFound.Title() != "ROLE_TITLE_1" {
+		t.Fatal("Title MUST be ROLE_TITLE_1, found:", roleFound.Title())
+	}
+}
+
+func TestStoreRoleListByCursor(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	handles := []string{"ROLE_A", "ROLE_B", "ROLE_C", "ROLE_D", "ROLE_E"}
+
+	for _, handle := range handles {
+		err = store.RoleCreate(context.Background(), NewRole().
+			SetStatus(ROLE_STATUS_ACTIVE).
+			SetHandle(handle).
+			SetTitle(handle))
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	query := NewRoleQuery().
+		SetOrderBy(COLUMN_HANDLE).
+		SetSortDirection(sb.ASC).
+		SetLimit(2)
+
+	found := []string{}
+	pages := 0
+
+	for {
+		page, err := store.RoleListByCursor(context.Background(), query)
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		pages++
+
+		for _, role := range page.Items {
+			found = append(found, role.Handle())
+		}
+
+		if !page.HasMore {
+			if page.NextCursor != "" {
+				t.Fatal("NextCursor MUST be empty on the last page")
+			}
+			break
+		}
+
+		query.SetCursor(page.NextCursor)
+	}
+
+	if pages != 3 {
+		t.Fatal("unexpected pages:", pages)
+	}
+
+	if strings.Join(found, ",") != strings.Join(handles, ",") {
+		t.Fatal("unexpected handles:", found)
+	}
+
+	_, err = store.RoleListByCursor(context.Background(), NewRoleQuery().
+		SetOrderBy(COLUMN_TITLE).
+		SetCursor(query.Cursor()).
+		SetLimit(2))
+
+	if err == nil {
+		t.Fatal("must return error as the cursor does not match the order of the query")
+	}
+
+	_, err = store.RoleList(context.Background(), NewRoleQuery().SetCursor("not-a-cursor"))
+
+	if err == nil {
+		t.Fatal("must return error for an invalid cursor")
+	}
+}
+
+func TestStoreRoleListPaged(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	for _, handle := range []string{"ROLE_A", "ROLE_B", "ROLE_C", "ROLE_D", "ROLE_E"} {
+		err = store.RoleCreate(context.Background(), NewRole().
+			SetStatus(ROLE_STATUS_ACTIVE).
+			SetHandle(handle).
+			SetTitle(handle))
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	query := NewRoleQuery().
+		SetOrderBy(COLUMN_HANDLE).
+		SetSortDirection(sb.ASC).
+		SetOffset(2).
+		SetLimit(2)
+
+	result, err := store.RoleListPaged(context.Background(), query)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(result.Items) != 2 {
+		t.Fatal("unexpected items length:", len(result.Items))
+	}
+
+	if result.Items[0].Handle() != "ROLE_C" {
+		t.Fatal("unexpected first item:", result.Items[0].Handle())
+	}
+
+	if result.Total != 5 {
+		t.Fatal("unexpected total:", result.Total)
+	}
+
+	if result.Page != 2 || result.PerPage != 2 || result.TotalPages != 3 {
+		t.Fatal("unexpected pagination:", result.Page, result.PerPage, result.TotalPages)
+	}
+
+	if query.HasCountOnly() {
+		t.Fatal("query MUST NOT be modified")
+	}
+}
+
+func TestStoreRoleList_Filters(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	roles := []RoleInterface{
+		NewRole().SetStatus(ROLE_STATUS_ACTIVE).SetHandle("admin").SetTitle("Administrator"),
+		NewRole().SetStatus(ROLE_STATUS_ACTIVE).SetHandle("manager").SetTitle("Manager").SetMemo("Manages the ADMIN team"),
+		NewRole().SetStatus(ROLE_STATUS_INACTIVE).SetHandle("guest").SetTitle("Guest"),
+	}
+
+	for _, role := range roles {
+		err = store.RoleCreate(context.Background(), role)
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query RoleQueryInterface
+		count int
+	}{
+		{"handle_in", NewRoleQuery().SetHandleIn([]string{"admin", "guest"}), 2},
+		{"id_not_in", NewRoleQuery().SetIDNotIn([]string{roles[0].ID()}), 2},
+		{"status_not_in", NewRoleQuery().SetStatusNotIn([]string{ROLE_STATUS_INACTIVE}), 2},
+		{"search", NewRoleQuery().SetSearch("Admin"), 2},
+		{"search_title", NewRoleQuery().SetSearch("guest"), 1},
+		{"search_none", NewRoleQuery().SetSearch("superuser"), 0},
+		{"updated_at_lte", NewRoleQuery().SetUpdatedAtLte(sb.MAX_DATETIME), 3},
+		{"updated_at_gte", NewRoleQuery().SetUpdatedAtGte(sb.MAX_DATETIME), 0},
+	}
+
+	for _, test := range tests {
+		list, err := store.RoleList(context.Background(), test.query)
+
+		if err != nil {
+			t.Fatal(test.name, "unexpected error:", err)
+		}
+
+		if len(list) != test.count {
+			t.Fatal(test.name, "unexpected list length:", len(list))
+		}
+	}
+
+	if err := NewRoleQuery().SetSearch(" ").Validate(); err == nil {
+		t.Fatal("must return error as search is empty")
+	}
+}
+
+func TestStoreRoleList_SearchWildcards(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	roles := []RoleInterface{
+		NewRole().SetStatus(ROLE_STATUS_ACTIVE).SetHandle("ops_admin").SetTitle("Ops Admin"),
+		NewRole().SetStatus(ROLE_STATUS_ACTIVE).SetHandle("opsadmin").SetTitle("Ops 100% Admin"),
+		NewRole().SetStatus(ROLE_STATUS_ACTIVE).SetHandle("share").SetTitle(`Share C:\ops`),
+	}
+
+	for _, role := range roles {
+		if err := store.RoleCreate(context.Background(), role); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		search string
+		count  int
+	}{
+		{"underscore", "ops_", 1},
+		{"percent", "%", 1},
+		{"backslash", `\ops`, 1},
+		{"plain", "ops", 3},
+	}
+
+	for _, test := range tests {
+		list, err := store.RoleList(context.Background(), NewRoleQuery().SetSearch(test.search))
+
+		if err != nil {
+			t.Fatal(test.name, "unexpected error:", err)
+		}
+
+		if len(list) != test.count {
+			t.Fatal(test.name, "unexpected list length:", len(list))
+		}
+	}
+}
+
+func TestStoreRoleList_OrderBys(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	roles := []RoleInterface{
+		NewRole().SetStatus(ROLE_STATUS_INACTIVE).SetHandle("ROLE_1").SetTitle("A"),
+		NewRole().SetStatus(ROLE_STATUS_ACTIVE).SetHandle("ROLE_2").SetTitle("A"),
+		NewRole().SetStatus(ROLE_STATUS_ACTIVE).SetHandle("ROLE_3").SetTitle("B"),
+		NewRole().SetStatus(ROLE_STATUS_INACTIVE).SetHandle("ROLE_4").SetTitle("C"),
+	}
+
+	for _, role := range roles {
+		err = store.RoleCreate(context.Background(), role)
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	orderBys := []OrderBy{{COLUMN_STATUS, ASC}, {COLUMN_TITLE, DESC}}
+
+	list, err := store.RoleList(context.Background(), NewRoleQuery().SetOrderBys(orderBys))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	handles := []string{}
+
+	for _, role := range list {
+		handles = append(handles, role.Handle())
+	}
+
+	if strings.Join(handles, ",") != "ROLE_3,ROLE_2,ROLE_4,ROLE_1" {
+		t.Fatal("unexpected order:", handles)
+	}
+
+	query := NewRoleQuery().SetOrderBys(orderBys).SetLimit(3)
+
+	page, err := store.RoleListByCursor(context.Background(), query)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	page, err = store.RoleListByCursor(context.Background(), query.SetCursor(page.NextCursor))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(page.Items) != 1 || page.Items[0].Handle() != "ROLE_1" {
+		t.Fatal("unexpected second page:", page.Items)
+	}
+
+	_, err = store.RoleList(context.Background(), NewRoleQuery().SetOrderBy("titel"))
+
+	if err == nil {
+		t.Fatal("must return error as order_by is not a valid column")
+	}
+
+	_, err = store.RoleList(context.Background(), NewRoleQuery().SetOrderBys([]OrderBy{{"titel", ASC}}))
+
+	if err == nil {
+		t.Fatal("must return error as order_bys column is not a valid column")
+	}
+}
+
+func TestStoreRoleList_Metas(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	metas := []map[string]string{
+		{"department": "finance", "region": "eu"},
+		{"department": "finance"},
+		{"department": "sales", "region": "us"},
+	}
+
+	for i, meta := range metas {
+		role := NewRole().
+			SetStatus(ROLE_STATUS_ACTIVE).
+			SetHandle("ROLE_" + strings.Repeat("X", i+1)).
+			SetTitle("ROLE_TITLE")
+
+		if err := role.SetMetas(meta); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if err := store.RoleCreate(context.Background(), role); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query RoleQueryInterface
+		count int
+	}{
+		{"meta_equals", NewRoleQuery().SetMetaEquals("department", "finance"), 2},
+		{"meta_equals_multiple", NewRoleQuery().SetMetaEquals("department", "finance").SetMetaEquals("region", "eu"), 1},
+		{"meta_equals_none", NewRoleQuery().SetMetaEquals("department", "hr"), 0},
+		{"meta_exists", NewRoleQuery().SetMetaExists("region"), 2},
+		{"meta_exists_none", NewRoleQuery().SetMetaExists("manager"), 0},
+	}
+
+	for _, test := range tests {
+		list, err := store.RoleList(context.Background(), test.query)
+
+		if err != nil {
+			t.Fatal(test.name, "unexpected error:", err)
+		}
+
+		if len(list) != test.count {
+			t.Fatal(test.name, "unexpected list length:", len(list))
+		}
+	}
+
+	if err := NewRoleQuery().SetMetaExists(`dep"artment`).Validate(); err == nil {
+		t.Fatal("must return error as meta key contains a quote")
+	}
+}
@@ -0,0 +1,369 @@
+package rolestore
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/base/database"
+)
+
+// SessionCreate creates a role activation session for the entity, which
+// expires after the ttl. Roles assigned to the entity have to be activated
+// in the session, before SessionHasRole reports them
+func (store *store) SessionCreate(ctx context.Context, entityType string, entityID string, ttl time.Duration) (SessionInterface, error) {
+	if err := store.sessionEnabled("SessionCreate"); err != nil {
+		return nil, err
+	}
+
+	if NewEntityRef(entityType, entityID).IsEmpty() {
+		return nil, errors.New("rolestore > SessionCreate. entity type and ID are required")
+	}
+
+	if ttl <= 0 {
+		return nil, errors.New("rolestore > SessionCreate. ttl must be positive")
+	}
+
+	if err := store.validateEntity(entityType, entityID); err != nil {
+		return nil, err
+	}
+
+	session := NewSession().
+		SetEntityType(entityType).
+		SetEntityID(entityID).
+		SetExpiresAt(carbon.CreateFromStdTime(time.Now().Add(ttl)).ToDateTimeString(carbon.UTC))
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Insert(store.sessionTableName).
+		Prepared(true).
+		Rows(session.Data()).
+		ToSQL()
+
+	if errSql != nil {
+		return nil, errSql
+	}
+
+	store.logSql("insert", sqlStr, params...)
+
+	if store.db == nil {
+		return nil, errors.New("rolestore: database is nil")
+	}
+
+	_, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	session.MarkAsNotDirty()
+
+	return session, nil
+}
+
+// SessionFindByID returns a session by its ID, or nil if the session
+// does not exist or has expired
+func (store *store) SessionFindByID(ctx context.Context, id string) (SessionInterface, error) {
+	if err := store.sessionEnabled("SessionFindByID"); err != nil {
+		return nil, err
+	}
+
+	if id == "" {
+		return nil, errors.New("rolestore > SessionFindByID. session id is empty")
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(store.sessionTableName).
+		Prepared(true).
+		Where(
+			goqu.C(COLUMN_ID).Eq(id),
+			goqu.C(COLUMN_EXPIRES_AT).Gt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)),
+		).
+		Limit(1).
+		ToSQL()
+
+	if errSql != nil {
+		return nil, errSql
+	}
+
+	rows, err := store.selectToMaps(ctx, sqlStr, params...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) > 0 {
+		return NewSessionFromExistingData(rows[0]), nil
+	}
+
+	return nil, nil
+}
+
+// SessionDelete deletes a session by its ID, i.e. when the entity logs out
+func (store *store) SessionDelete(ctx context.Context, id string) error {
+	if err := store.sessionEnabled("SessionDelete"); err != nil {
+		return err
+	}
+
+	if id == "" {
+		return errors.New("rolestore > SessionDelete. session id is empty")
+	}
+
+	_, err := store.sessionDeleteWhere(ctx, goqu.C(COLUMN_ID).Eq(id))
+
+	return err
+}
+
+// SessionDeleteExpired deletes the expired sessions,
+// and returns how many were deleted
+func (store *store) SessionDeleteExpired(ctx context.Context) (int64, error) {
+	if err := store.sessionEnabled("SessionDeleteExpired"); err != nil {
+		return 0, err
+	}
+
+	return store.sessionDeleteWhere(ctx, goqu.C(COLUMN_EXPIRES_AT).Lte(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)))
+}
+
+// SessionActivateRole activates the role in the session. The role must be
+// active, and assigned to the entity of the session, and activating it must
+// not break a dynamic separation of duties constraint, otherwise a
+// SodViolationError is returned. If the active roles of the session were
+// changed concurrently, ErrConflict is returned. Activating an already
+// active role does nothing
+func (store *store) SessionActivateRole(ctx context.Context, sessionID string, roleID string) error {
+	if err := store.sessionEnabled("SessionActivateRole"); err != nil {
+		return err
+	}
+
+	if roleID == "" {
+		return errors.New("rolestore > SessionActivateRole. role id is empty")
+	}
+
+	return store.WithTx(ctx, func(txCtx context.Context) error {
+		session, err := store.SessionFindByID(txCtx, sessionID)
+
+		if err != nil {
+			return err
+		}
+
+		if session == nil {
+			return ErrSessionNotFound
+		}
+
+		entityRole, err := store.EntityRoleFindByEntityAndRole(txCtx, session.EntityType(), session.EntityID(), roleID)
+
+		if err != nil {
+			return err
+		}
+
+		if entityRole == nil {
+			return errors.New("rolestore > SessionActivateRole. role is not assigned to the session entity")
+		}
+
+		role, err := store.RoleFindByID(txCtx, roleID)
+
+		if err != nil {
+			return err
+		}
+
+		if role == nil || !role.IsActive() {
+			return errors.New("rolestore > SessionActivateRole. role not found or not active: " + roleID)
+		}
+
+		activeRoleIDs, err := session.ActiveRoleIDs()
+
+		if err != nil {
+			return err
+		}
+
+		if slices.Contains(activeRoleIDs, roleID) {
+			return nil
+		}
+
+		err = store.sodCheckKind(txCtx, SOD_KIND_DYNAMIC, session.EntityType(), session.EntityID(), roleID, func() ([]string, error) {
+			return activeRoleIDs, nil
+		})
+
+		if err != nil {
+			return err
+		}
+
+		expected := session.Data()[COLUMN_ACTIVE_ROLE_IDS]
+
+		if err := session.SetActiveRoleIDs(append(activeRoleIDs, roleID)); err != nil {
+			return err
+		}
+
+		return store.sessionUpdate(txCtx, session, expected)
+	})
+}
+
+// SessionDeactivateRole deactivates the role in the session.
+// Deactivating an inactive role does nothing
+func (store *store) SessionDeactivateRole(ctx context.Context, sessionID string, roleID string) error {
+	if err := store.sessionEnabled("SessionDeactivateRole"); err != nil {
+		return err
+	}
+
+	if roleID == "" {
+		return errors.New("rolestore > SessionDeactivateRole. role id is empty")
+	}
+
+	return store.WithTx(ctx, func(txCtx context.Context) error {
+		session, err := store.SessionFindByID(txCtx, sessionID)
+
+		if err != nil {
+			return err
+		}
+
+		if session == nil {
+			return ErrSessionNotFound
+		}
+
+		activeRoleIDs, err := session.ActiveRoleIDs()
+
+		if err != nil {
+			return err
+		}
+
+		if !slices.Contains(activeRoleIDs, roleID) {
+			return nil
+		}
+
+		expected := session.Data()[COLUMN_ACTIVE_ROLE_IDS]
+
+		activeRoleIDs = slices.DeleteFunc(activeRoleIDs, func(activeRoleID string) bool {
+			return activeRoleID == roleID
+		})
+
+		if err := session.SetActiveRoleIDs(activeRoleIDs); err != nil {
+			return err
+		}
+
+		return store.sessionUpdate(txCtx, session, expected)
+	})
+}
+
+// SessionHasRole returns whether the role is active in the session, and
+// still assigned to the entity of the session. An expired session has no roles
+func (store *store) SessionHasRole(ctx context.Context, sessionID string, roleID string) (bool, error) {
+	if roleID == "" {
+		return false, errors.New("rolestore > SessionHasRole. role id is empty")
+	}
+
+	session, err := store.SessionFindByID(ctx, sessionID)
+
+	if err != nil {
+		return false, err
+	}
+
+	if session == nil {
+		return false, nil
+	}
+
+	activeRoleIDs, err := session.ActiveRoleIDs()
+
+	if err != nil {
+		return false, err
+	}
+
+	if !slices.Contains(activeRoleIDs, roleID) {
+		return false, nil
+	}
+
+	entityRole, err := store.EntityRoleFindByEntityAndRole(ctx, session.EntityType(), session.EntityID(), roleID)
+
+	if err != nil {
+		return false, err
+	}
+
+	return entityRole != nil, nil
+}
+
+// sessionUpdate saves the changed fields of the session, if it has not
+// expired, and its active roles are still the expected ones, as loaded.
+// Otherwise, i.e. if a concurrent activation changed them, ErrConflict
+// is returned, so that no activation is lost or skips the constraints
+func (store *store) sessionUpdate(ctx context.Context, session SessionInterface, expectedActiveRoleIDs string) error {
+	session.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	dataChanged := session.DataChanged()
+
+	delete(dataChanged, COLUMN_ID) // ID is not updateable
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Update(store.sessionTableName).
+		Prepared(true).
+		Set(dataChanged).
+		Where(
+			goqu.C(COLUMN_ID).Eq(session.ID()),
+			goqu.C(COLUMN_ACTIVE_ROLE_IDS).Eq(expectedActiveRoleIDs),
+			goqu.C(COLUMN_EXPIRES_AT).Gt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)),
+		).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	store.logSql("update", sqlStr, params...)
+
+	result, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrConflict
+	}
+
+	session.MarkAsNotDirty()
+
+	return nil
+}
+
+// sessionDeleteWhere deletes the sessions matching the condition,
+// and returns how many were deleted
+func (store *store) sessionDeleteWhere(ctx context.Context, condition goqu.Expression) (int64, error) {
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Delete(store.sessionTableName).
+		Prepared(true).
+		Where(condition).
+		ToSQL()
+
+	if errSql != nil {
+		return 0, errSql
+	}
+
+	store.logSql("delete", sqlStr, params...)
+
+	if store.db == nil {
+		return 0, errors.New("rolestore: database is nil")
+	}
+
+	result, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// sessionEnabled returns an error, if no session table is configured
+func (store *store) sessionEnabled(method string) error {
+	if store.sessionTableName == "" {
+		return errors.New("rolestore > " + method + ". sessions are disabled, SessionTableName is not set")
+	}
+
+	return nil
+}
@@ -0,0 +1,256 @@
+package rolestore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStoreSessionActivateRole(t *testing.T) {
+	store := initStoreWithOptions(t, NewStoreOptions{
+		SodConstraintTableName: "roles_sod_constraint_table",
+		SessionTableName:       "roles_session_table",
+	})
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	initiator := createTestRole(t, store, "initiator", nil)
+	approver := createTestRole(t, store, "approver", nil)
+
+	constraint := NewSodConstraint().SetKind(SOD_KIND_DYNAMIC).SetMaxRoles(1)
+
+	if err := constraint.SetRoleIDs([]string{initiator.ID(), approver.ID()}); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.SodConstraintCreate(context.Background(), constraint); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// conflicting roles may be held, as the constraint is dynamic
+	for _, roleID := range []string{initiator.ID(), approver.ID()} {
+		err := store.EntityRoleCreate(context.Background(), NewEntityRole().
+			SetEntityType("user").
+			SetEntityID("USER_01").
+			SetRoleID(roleID))
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	session, err := store.SessionCreate(context.Background(), "user", "USER_01", time.Hour)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	hasRole, err := store.SessionHasRole(context.Background(), session.ID(), initiator.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if hasRole {
+		t.Fatal("role MUST NOT be active before activation")
+	}
+
+	if err := store.SessionActivateRole(context.Background(), session.ID(), initiator.ID()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	hasRole, err = store.SessionHasRole(context.Background(), session.ID(), initiator.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !hasRole {
+		t.Fatal("role MUST be active after activation")
+	}
+
+	err = store.SessionActivateRole(context.Background(), session.ID(), approver.ID())
+
+	if !errors.Is(err, ErrSodViolation) {
+		t.Fatal("error MUST be ErrSodViolation, found:", err)
+	}
+
+	// a role not assigned to the entity cannot be activated
+	if err := store.SessionActivateRole(context.Background(), session.ID(), "ROLE_OTHER"); err == nil {
+		t.Fatal("error MUST NOT be nil for an unassigned role")
+	}
+
+	// after deactivation the conflicting role can be activated
+	if err := store.SessionDeactivateRole(context.Background(), session.ID(), initiator.ID()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.SessionActivateRole(context.Background(), session.ID(), approver.ID()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	found, err := store.SessionFindByID(context.Background(), session.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	activeRoleIDs, err := found.ActiveRoleIDs()
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(activeRoleIDs) != 1 || activeRoleIDs[0] != approver.ID() {
+		t.Fatal("unexpected active roles:", activeRoleIDs)
+	}
+
+	// a revoked assignment is no longer active
+	if _, err := store.EntityRevokeAll(context.Background(), "user", "USER_01", false); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	hasRole, err = store.SessionHasRole(context.Background(), session.ID(), approver.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if hasRole {
+		t.Fatal("revoked role MUST NOT be active")
+	}
+}
+
+func TestStoreSessionActivateRole_InactiveRoleAndConflict(t *testing.T) {
+	store := initStoreWithOptions(t, NewStoreOptions{SessionTableName: "roles_session_table"})
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	viewer := createTestRole(t, store, "viewer", nil)
+	editor := createTestRole(t, store, "editor", nil)
+
+	for _, role := range []RoleInterface{viewer, editor} {
+		err := store.EntityRoleCreate(context.Background(), NewEntityRole().
+			SetEntityType("user").
+			SetEntityID("USER_01").
+			SetRoleID(role.ID()))
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	session, err := store.SessionCreate(context.Background(), "user", "USER_01", time.Hour)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// an inactive role cannot be activated, even if assigned
+	if err := store.RoleUpdate(context.Background(), editor.SetStatus(ROLE_STATUS_INACTIVE)); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.SessionActivateRole(context.Background(), session.ID(), editor.ID()); err == nil {
+		t.Fatal("error MUST NOT be nil for an inactive role")
+	}
+
+	// a session saved from a stale copy, i.e. by a concurrent activation, conflicts
+	stale, err := store.SessionFindByID(context.Background(), session.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	expected := stale.Data()[COLUMN_ACTIVE_ROLE_IDS]
+
+	if err := store.SessionActivateRole(context.Background(), session.ID(), viewer.ID()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := stale.SetActiveRoleIDs([]string{editor.ID()}); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.(interface {
+		sessionUpdate(ctx context.Context, session SessionInterface, expectedActiveRoleIDs string) error
+	}).sessionUpdate(context.Background(), stale, expected)
+
+	if !errors.Is(err, ErrConflict) {
+		t.Fatal("error MUST be ErrConflict, found:", err)
+	}
+
+	hasRole, err := store.SessionHasRole(context.Background(), session.ID(), viewer.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !hasRole {
+		t.Fatal("the first activation MUST NOT be lost")
+	}
+}
+
+func TestStoreSessionExpiry(t *testing.T) {
+	store := initStoreWithOptions(t, NewStoreOptions{
+		SodConstraintTableName: "roles_sod_constraint_table",
+		SessionTableName:       "roles_session_table",
+	})
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	session, err := store.SessionCreate(context.Background(), "user", "USER_01", time.Hour)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	_, err = store.DB().Exec("UPDATE roles_session_table SET expires_at = ? WHERE id = ?", "2020-01-01 00:00:00", session.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	found, err := store.SessionFindByID(context.Background(), session.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if found != nil {
+		t.Fatal("expired session MUST NOT be found")
+	}
+
+	err = store.SessionActivateRole(context.Background(), session.ID(), "ROLE_01")
+
+	if !errors.Is(err, ErrSessionNotFound) {
+		t.Fatal("error MUST be ErrSessionNotFound, found:", err)
+	}
+
+	deleted, err := store.SessionDeleteExpired(context.Background())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if deleted != 1 {
+		t.Fatal("unexpected deleted count:", deleted)
+	}
+
+	if _, err := store.SessionCreate(context.Background(), "user", "USER_01", 0); err == nil {
+		t.Fatal("error MUST NOT be nil for a zero ttl")
+	}
+}
@@ -0,0 +1,451 @@
+package rolestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/base/database"
+	"github.com/gouniverse/sb"
+	"github.com/samber/lo"
+)
+
+// SodViolation describes an entity holding (or about to hold) more roles
+// from the role set of a separation of duties constraint than allowed
+type SodViolation struct {
+	// ConstraintID is the ID of the violated constraint
+	ConstraintID string
+
+	// EntityType is the type of the entity
+	EntityType string
+
+	// EntityID is the ID of the entity
+	EntityID string
+
+	// RoleIDs are the roles from the constraint set the entity holds (or has active in a session)
+	RoleIDs []string
+
+	// MaxRoles is the maximum number of roles from the set allowed by the constraint
+	MaxRoles int
+}
+
+// SodViolationError is returned when an assignment (static constraints)
+// or a role activation in a session (dynamic constraints) would break
+// a separation of duties constraint. It matches ErrSodViolation
+type SodViolationError struct {
+	SodViolation
+}
+
+func (e *SodViolationError) Error() string {
+	return fmt.Sprintf(
+		"%s: constraint %s allows at most %d of the roles, entity %s %s would have %s",
+		ErrSodViolation.Error(),
+		e.ConstraintID,
+		e.MaxRoles,
+		e.EntityType,
+		e.EntityID,
+		strings.Join(e.RoleIDs, ", "),
+	)
+}
+
+func (e *SodViolationError) Unwrap() error {
+	return ErrSodViolation
+}
+
+// SodConstraintCreate creates a separation of duties constraint.
+// The constraint must have at least two roles, and allow at least one
+// but fewer roles than it has
+func (store *store) SodConstraintCreate(ctx context.Context, constraint SodConstraintInterface) error {
+	if err := store.sodEnabled("SodConstraintCreate"); err != nil {
+		return err
+	}
+
+	if constraint == nil {
+		return errors.New("rolestore > SodConstraintCreate. constraint is nil")
+	}
+
+	if err := validateSodConstraint(constraint); err != nil {
+		return err
+	}
+
+	constraint.SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+	constraint.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Insert(store.sodConstraintTableName).
+		Prepared(true).
+		Rows(constraint.Data()).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	store.logSql("insert", sqlStr, params...)
+
+	if store.db == nil {
+		return errors.New("rolestore: database is nil")
+	}
+
+	_, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	if err != nil {
+		return err
+	}
+
+	constraint.MarkAsNotDirty()
+
+	return nil
+}
+
+// SodConstraintDelete deletes a separation of duties constraint
+func (store *store) SodConstraintDelete(ctx context.Context, constraint SodConstraintInterface) error {
+	if constraint == nil {
+		return errors.New("rolestore > SodConstraintDelete. constraint is nil")
+	}
+
+	return store.SodConstraintDeleteByID(ctx, constraint.ID())
+}
+
+// SodConstraintDeleteByID deletes a separation of duties constraint by its ID
+func (store *store) SodConstraintDeleteByID(ctx context.Context, id string) error {
+	if err := store.sodEnabled("SodConstraintDeleteByID"); err != nil {
+		return err
+	}
+
+	if id == "" {
+		return errors.New("rolestore > SodConstraintDeleteByID. constraint id is empty")
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		Delete(store.sodConstraintTableName).
+		Prepared(true).
+		Where(goqu.C(COLUMN_ID).Eq(id)).
+		ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	store.logSql("delete", sqlStr, params...)
+
+	_, err := database.Execute(store.toQuerableContext(ctx), sqlStr, params...)
+
+	return err
+}
+
+// SodConstraintFindByID returns a separation of duties constraint by its ID,
+// or nil if not found
+func (store *store) SodConstraintFindByID(ctx context.Context, id string) (SodConstraintInterface, error) {
+	if id == "" {
+		return nil, errors.New("rolestore > SodConstraintFindByID. constraint id is empty")
+	}
+
+	list, err := store.sodConstraintList(ctx, goqu.C(COLUMN_ID).Eq(id))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list) > 0 {
+		return list[0], nil
+	}
+
+	return nil, nil
+}
+
+// SodConstraintList returns the live separation of duties constraints,
+// ordered by creation date
+func (store *store) SodConstraintList(ctx context.Context) ([]SodConstraintInterface, error) {
+	return store.sodConstraintList(ctx)
+}
+
+// SodViolations reports the entities, which in the current data hold more
+// roles from the role set of a static constraint than allowed, i.e. because
+// they were assigned before the constraint was created
+func (store *store) SodViolations(ctx context.Context) ([]SodViolation, error) {
+	if err := store.sodEnabled("SodViolations"); err != nil {
+		return []SodViolation{}, err
+	}
+
+	constraints, err := store.sodConstraintList(ctx, goqu.C(COLUMN_KIND).Eq(SOD_KIND_STATIC))
+
+	if err != nil {
+		return []SodViolation{}, err
+	}
+
+	violations := []SodViolation{}
+
+	for _, constraint := range constraints {
+		roleIDs, err := constraint.RoleIDs()
+
+		if err != nil {
+			return []SodViolation{}, err
+		}
+
+		sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+			From(store.entityRoleTableName).
+			Prepared(true).
+			Select(goqu.C(COLUMN_ENTITY_TYPE), goqu.C(COLUMN_ENTITY_ID)).
+			Where(
+				goqu.C(COLUMN_SOFT_DELETED_AT).Gt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)),
+				goqu.C(COLUMN_ROLE_ID).In(roleIDs),
+			).
+			GroupBy(goqu.C(COLUMN_ENTITY_TYPE), goqu.C(COLUMN_ENTITY_ID)).
+			Having(goqu.COUNT(goqu.DISTINCT(goqu.C(COLUMN_ROLE_ID))).Gt(constraint.MaxRoles())).
+			Order(goqu.C(COLUMN_ENTITY_TYPE).Asc(), goqu.C(COLUMN_ENTITY_ID).Asc()).
+			ToSQL()
+
+		if errSql != nil {
+			return []SodViolation{}, errSql
+		}
+
+		rows, err := store.selectToMaps(ctx, sqlStr, params...)
+
+		if err != nil {
+			return []SodViolation{}, err
+		}
+
+		for _, row := range rows {
+			held, err := store.entityHeldRoleIDs(ctx, row[COLUMN_ENTITY_TYPE], row[COLUMN_ENTITY_ID], "")
+
+			if err != nil {
+				return []SodViolation{}, err
+			}
+
+			violations = append(violations, SodViolation{
+				ConstraintID: constraint.ID(),
+				EntityType:   row[COLUMN_ENTITY_TYPE],
+				EntityID:     row[COLUMN_ENTITY_ID],
+				RoleIDs:      lo.Intersect(roleIDs, held),
+				MaxRoles:     constraint.MaxRoles(),
+			})
+		}
+	}
+
+	return violations, nil
+}
+
+// sodCheck returns a SodViolationError, if assigning (or delegating) the role
+// to the entity would break a static separation of duties constraint. The
+// roles delegated to the entity count as held. The mapping with the ID
+// excludeID (if any) is not counted as held, as it is being changed
+func (store *store) sodCheck(ctx context.Context, entityType string, entityID string, roleID string, excludeID string) error {
+	return store.sodCheckKind(ctx, SOD_KIND_STATIC, entityType, entityID, roleID, func() ([]string, error) {
+		held, err := store.entityHeldRoleIDs(ctx, entityType, entityID, excludeID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		delegated, err := store.entityDelegatedRoleIDs(ctx, NewEntityRef(entityType, entityID))
+
+		if err != nil {
+			return nil, err
+		}
+
+		return append(held, delegated...), nil
+	})
+}
+
+// sodLockForUpdate locks the role row, together with the rows of the other
+// roles of the static constraints containing the role, until the end of the
+// transaction. Concurrent assignments of different roles of a constraint are
+// serialized, so each sodCheck sees the assignment the other made. Like
+// roleLockForUpdate it must be the first statement of the transaction, on
+// MySQL the constraints are read with a locking read, which does not fix
+// the REPEATABLE READ snapshot
+func (store *store) sodLockForUpdate(ctx context.Context, roleID string) error {
+	if store.sodConstraintTableName == "" {
+		return store.roleLockForUpdate(ctx, roleID)
+	}
+
+	if store.dbDriverName != sb.DIALECT_MYSQL && store.dbDriverName != sb.DIALECT_POSTGRES {
+		return nil
+	}
+
+	q := goqu.Dialect(store.dbDriverName).
+		From(store.sodConstraintTableName).
+		Prepared(true).
+		Where(goqu.C(COLUMN_SOFT_DELETED_AT).Gt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))).
+		Where(goqu.C(COLUMN_KIND).Eq(SOD_KIND_STATIC))
+
+	if store.dbDriverName == sb.DIALECT_MYSQL {
+		q = q.ForShare(exp.Wait)
+	}
+
+	sqlStr, params, errSql := q.ToSQL()
+
+	if errSql != nil {
+		return errSql
+	}
+
+	rows, err := store.selectToMaps(ctx, sqlStr, params...)
+
+	if err != nil {
+		return err
+	}
+
+	lockRoleIDs := []string{roleID}
+
+	for _, row := range rows {
+		roleIDs, err := NewSodConstraintFromExistingData(row).RoleIDs()
+
+		if err != nil {
+			return err
+		}
+
+		if slices.Contains(roleIDs, roleID) {
+			lockRoleIDs = append(lockRoleIDs, roleIDs...)
+		}
+	}
+
+	return store.roleLockForUpdate(ctx, lo.Uniq(lockRoleIDs)...)
+}
+
+// sodCheckKind returns a SodViolationError, if adding the role to the roles
+// returned by current would break a constraint of the given kind. The current
+// roles are only looked up, if a constraint contains the role
+func (store *store) sodCheckKind(
+	ctx context.Context,
+	kind string,
+	entityType string,
+	entityID string,
+	roleID string,
+	current func() ([]string, error),
+) error {
+	if store.sodConstraintTableName == "" {
+		return nil
+	}
+
+	constraints, err := store.sodConstraintList(ctx, goqu.C(COLUMN_KIND).Eq(kind))
+
+	if err != nil {
+		return err
+	}
+
+	currentRoleIDs := []string{}
+	currentLoaded := false
+
+	for _, constraint := range constraints {
+		roleIDs, err := constraint.RoleIDs()
+
+		if err != nil {
+			return err
+		}
+
+		if !slices.Contains(roleIDs, roleID) {
+			continue
+		}
+
+		if !currentLoaded {
+			currentRoleIDs, err = current()
+
+			if err != nil {
+				return err
+			}
+
+			currentLoaded = true
+		}
+
+		wouldHave := lo.Intersect(roleIDs, lo.Uniq(append(slices.Clone(currentRoleIDs), roleID)))
+
+		if len(wouldHave) > constraint.MaxRoles() {
+			return &SodViolationError{SodViolation{
+				ConstraintID: constraint.ID(),
+				EntityType:   entityType,
+				EntityID:     entityID,
+				RoleIDs:      wouldHave,
+				MaxRoles:     constraint.MaxRoles(),
+			}}
+		}
+	}
+
+	return nil
+}
+
+// entityHeldRoleIDs returns the IDs of the roles the entity holds
+// by live mappings, except the mapping with the ID excludeID
+func (store *store) entityHeldRoleIDs(ctx context.Context, entityType string, entityID string, excludeID string) ([]string, error) {
+	entityRoles, err := store.entityLiveRoles(ctx, NewEntityRef(entityType, entityID))
+
+	if err != nil {
+		return nil, err
+	}
+
+	held := []string{}
+
+	for _, entityRole := range entityRoles {
+		if entityRole.ID() != excludeID {
+			held = append(held, entityRole.RoleID())
+		}
+	}
+
+	return held, nil
+}
+
+// sodConstraintList returns the live constraints matching the conditions
+func (store *store) sodConstraintList(ctx context.Context, conditions ...goqu.Expression) ([]SodConstraintInterface, error) {
+	if err := store.sodEnabled("SodConstraintList"); err != nil {
+		return []SodConstraintInterface{}, err
+	}
+
+	sqlStr, params, errSql := goqu.Dialect(store.dbDriverName).
+		From(store.sodConstraintTableName).
+		Prepared(true).
+		Where(goqu.C(COLUMN_SOFT_DELETED_AT).Gt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))).
+		Where(conditions...).
+		Order(goqu.C(COLUMN_CREATED_AT).Asc(), goqu.C(COLUMN_ID).Asc()).
+		ToSQL()
+
+	if errSql != nil {
+		return []SodConstraintInterface{}, errSql
+	}
+
+	rows, err := store.selectToMaps(ctx, sqlStr, params...)
+
+	if err != nil {
+		return []SodConstraintInterface{}, err
+	}
+
+	return lo.Map(rows, func(row map[string]string, _ int) SodConstraintInterface {
+		return NewSodConstraintFromExistingData(row)
+	}), nil
+}
+
+// sodEnabled returns an error, if no separation of duties constraint table is configured
+func (store *store) sodEnabled(method string) error {
+	if store.sodConstraintTableName == "" {
+		return errors.New("rolestore > " + method + ". separation of duties constraints are disabled, SodConstraintTableName is not set")
+	}
+
+	return nil
+}
+
+// validateSodConstraint checks a constraint before it is stored
+func validateSodConstraint(constraint SodConstraintInterface) error {
+	if constraint.Kind() != SOD_KIND_STATIC && constraint.Kind() != SOD_KIND_DYNAMIC {
+		return errors.New("rolestore > SodConstraintCreate. constraint kind is invalid: " + constraint.Kind())
+	}
+
+	roleIDs, err := constraint.RoleIDs()
+
+	if err != nil {
+		return err
+	}
+
+	if len(lo.Uniq(roleIDs)) < 2 {
+		return errors.New("rolestore > SodConstraintCreate. constraint must have at least two roles")
+	}
+
+	if constraint.MaxRoles() < 1 || constraint.MaxRoles() >= len(lo.Uniq(roleIDs)) {
+		return errors.New("rolestore > SodConstraintCreate. constraint max roles must be at least 1 and less than the number of roles")
+	}
+
+	return nil
+}
@@ -0,0 +1,375 @@
+package rolestore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/gouniverse/sb"
+)
+
+func TestStoreSodConstraintCreate(t *testing.T) {
+	store := initStoreWithOptions(t, NewStoreOptions{SodConstraintTableName: "roles_sod_constraint_table"})
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	constraint := NewSodConstraint().SetTitle("Payments").SetMaxRoles(1)
+
+	if err := constraint.SetRoleIDs([]string{"ROLE_INITIATOR", "ROLE_APPROVER"}); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.SodConstraintCreate(context.Background(), constraint); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	found, err := store.SodConstraintFindByID(context.Background(), constraint.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if found == nil {
+		t.Fatal("constraint MUST be found")
+	}
+
+	roleIDs, err := found.RoleIDs()
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(roleIDs) != 2 || roleIDs[0] != "ROLE_INITIATOR" || found.MaxRoles() != 1 || found.Kind() != SOD_KIND_STATIC {
+		t.Fatal("unexpected constraint:", roleIDs, found.MaxRoles(), found.Kind())
+	}
+
+	invalid := NewSodConstraint().SetMaxRoles(2)
+
+	if err := invalid.SetRoleIDs([]string{"ROLE_INITIATOR", "ROLE_APPROVER"}); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.SodConstraintCreate(context.Background(), invalid); err == nil {
+		t.Fatal("error MUST NOT be nil when max roles allows all roles")
+	}
+
+	if err := store.SodConstraintDelete(context.Background(), constraint); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	list, err := store.SodConstraintList(context.Background())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(list) != 0 {
+		t.Fatal("unexpected constraints length:", len(list))
+	}
+}
+
+func TestStoreSodConstraint_Enforced(t *testing.T) {
+	store := initStoreWithOptions(t, NewStoreOptions{SodConstraintTableName: "roles_sod_constraint_table"})
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	constraint := NewSodConstraint().SetMaxRoles(1)
+
+	if err := constraint.SetRoleIDs([]string{"ROLE_INITIATOR", "ROLE_APPROVER"}); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.SodConstraintCreate(context.Background(), constraint); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err := store.EntityRoleCreate(context.Background(), NewEntityRole().
+		SetEntityType("user").
+		SetEntityID("USER_01").
+		SetRoleID("ROLE_INITIATOR"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// roles outside the set are not affected
+	err = store.EntityRoleCreate(context.Background(), NewEntityRole().
+		SetEntityType("user").
+		SetEntityID("USER_01").
+		SetRoleID("ROLE_VIEWER"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.EntityRoleCreate(context.Background(), NewEntityRole().
+		SetEntityType("user").
+		SetEntityID("USER_01").
+		SetRoleID("ROLE_APPROVER"))
+
+	if !errors.Is(err, ErrSodViolation) {
+		t.Fatal("error MUST be ErrSodViolation, found:", err)
+	}
+
+	var violationErr *SodViolationError
+
+	if !errors.As(err, &violationErr) {
+		t.Fatal("error MUST be SodViolationError, found:", err)
+	}
+
+	if violationErr.ConstraintID != constraint.ID() || len(violationErr.RoleIDs) != 2 {
+		t.Fatal("unexpected violation:", violationErr.SodViolation)
+	}
+
+	// bulk paths are enforced too
+	err = store.EntityRoleCreate(context.Background(), NewEntityRole().
+		SetEntityType("user").
+		SetEntityID("USER_02").
+		SetRoleID("ROLE_APPROVER"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	_, err = store.EntityCopyRoles(context.Background(), NewEntityRef("user", "USER_01"), NewEntityRef("user", "USER_02"))
+
+	if !errors.Is(err, ErrSodViolation) {
+		t.Fatal("copy error MUST be ErrSodViolation, found:", err)
+	}
+
+	count, err := store.EntityRoleCount(context.Background(), NewEntityRoleQuery().SetEntityType("user").SetEntityID("USER_02"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 1 {
+		t.Fatal("copy MUST be rolled back, found roles:", count)
+	}
+
+	_, err = store.EntityTransferRoles(context.Background(), NewEntityRef("user", "USER_01"), NewEntityRef("user", "USER_02"))
+
+	if !errors.Is(err, ErrSodViolation) {
+		t.Fatal("transfer error MUST be ErrSodViolation, found:", err)
+	}
+}
+
+func TestStoreSodConstraint_Concurrent(t *testing.T) {
+	// a file database, as each connection to :memory: is a database of its own.
+	// Immediate transactions wait for each other, instead of failing as busy
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "concurrent.db")+"?_pragma=busy_timeout(5000)&_txlock=immediate")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		DB:                     db,
+		RoleTableName:          "roles_role_table",
+		EntityRoleTableName:    "roles_entity_role_table",
+		SodConstraintTableName: "roles_sod_constraint_table",
+		AutomigrateEnabled:     true,
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	initiator := createTestRole(t, store, "initiator", nil)
+	approver := createTestRole(t, store, "approver", nil)
+
+	constraint := NewSodConstraint().SetMaxRoles(1)
+
+	if err := constraint.SetRoleIDs([]string{initiator.ID(), approver.ID()}); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.SodConstraintCreate(context.Background(), constraint); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	roleIDs := []string{initiator.ID(), approver.ID()}
+	errs := make([]error, len(roleIDs))
+	wg := sync.WaitGroup{}
+
+	for i, roleID := range roleIDs {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			errs[i] = store.EntityRoleCreate(context.Background(), NewEntityRole().
+				SetEntityType("user").
+				SetEntityID("USER_01").
+				SetRoleID(roleID))
+		}()
+	}
+
+	wg.Wait()
+
+	succeeded := 0
+
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+		} else if !errors.Is(err, ErrSodViolation) {
+			t.Fatal("error MUST be ErrSodViolation, found:", err)
+		}
+	}
+
+	if succeeded != 1 {
+		t.Fatal("exactly one assignment MUST succeed, found:", succeeded)
+	}
+
+	violations, err := store.SodViolations(context.Background())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(violations) != 0 {
+		t.Fatal("unexpected violations:", violations)
+	}
+}
+
+func TestStoreSodConstraint_EnforcedOnRestore(t *testing.T) {
+	store := initStoreWithOptions(t, NewStoreOptions{SodConstraintTableName: "roles_sod_constraint_table"})
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	constraint := NewSodConstraint().SetMaxRoles(1)
+
+	if err := constraint.SetRoleIDs([]string{"ROLE_INITIATOR", "ROLE_APPROVER"}); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.SodConstraintCreate(context.Background(), constraint); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	initiator := NewEntityRole().
+		SetEntityType("user").
+		SetEntityID("USER_01").
+		SetRoleID("ROLE_INITIATOR")
+
+	if err := store.EntityRoleCreate(context.Background(), initiator); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.EntityRoleSoftDelete(context.Background(), initiator); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err := store.EntityRoleCreate(context.Background(), NewEntityRole().
+		SetEntityType("user").
+		SetEntityID("USER_01").
+		SetRoleID("ROLE_APPROVER"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	initiator.SetSoftDeletedAt(sb.MAX_DATETIME)
+
+	err = store.EntityRoleUpdate(context.Background(), initiator)
+
+	if !errors.Is(err, ErrSodViolation) {
+		t.Fatal("restore error MUST be ErrSodViolation, found:", err)
+	}
+
+	found, err := store.EntityRoleFindByEntityAndRole(context.Background(), "user", "USER_01", "ROLE_INITIATOR")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if found != nil {
+		t.Fatal("violating mapping MUST NOT be restored")
+	}
+}
+
+func TestStoreSodViolations(t *testing.T) {
+	store := initStoreWithOptions(t, NewStoreOptions{SodConstraintTableName: "roles_sod_constraint_table"})
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// assigned before the constraint exists
+	entityRoles := []EntityRoleInterface{
+		NewEntityRole().SetEntityType("user").SetEntityID("USER_01").SetRoleID("ROLE_INITIATOR"),
+		NewEntityRole().SetEntityType("user").SetEntityID("USER_01").SetRoleID("ROLE_APPROVER"),
+		NewEntityRole().SetEntityType("user").SetEntityID("USER_02").SetRoleID("ROLE_APPROVER"),
+	}
+
+	for _, entityRole := range entityRoles {
+		if err := store.EntityRoleCreate(context.Background(), entityRole); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	constraint := NewSodConstraint().SetMaxRoles(1)
+
+	if err := constraint.SetRoleIDs([]string{"ROLE_INITIATOR", "ROLE_APPROVER"}); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.SodConstraintCreate(context.Background(), constraint); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	violations, err := store.SodViolations(context.Background())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(violations) != 1 {
+		t.Fatal("unexpected violations length:", len(violations))
+	}
+
+	if violations[0].EntityID != "USER_01" || len(violations[0].RoleIDs) != 2 || violations[0].MaxRoles != 1 {
+		t.Fatal("unexpected violation:", violations[0])
+	}
+}
+
+func TestStoreSodConstraint_Disabled(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	if _, err := store.SodViolations(context.Background()); err == nil {
+		t.Fatal("error MUST NOT be nil when constraints are disabled")
+	}
+}
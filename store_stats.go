@@ -0,0 +1,190 @@
+package rolestore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/base/database"
+	"github.com/samber/lo"
+	"github.com/spf13/cast"
+)
+
+const (
+	statsCountAlias       = "rolestore_count"
+	statsRoleCountAlias   = "rolestore_role_count"
+	statsEntityCountAlias = "rolestore_entity_count"
+)
+
+// RoleAssignmentCount is the number of live assignments of a role
+type RoleAssignmentCount struct {
+	// RoleID is the ID of the role
+	RoleID string
+
+	// EntityType is the type of the entities, empty if not grouped by entity type
+	EntityType string
+
+	// Count is the number of live (not soft deleted) assignments
+	Count int64
+}
+
+// EntityRoleCountBucket is a bucket of the histogram of how many roles entities hold
+type EntityRoleCountBucket struct {
+	// RoleCount is the number of roles held
+	RoleCount int64
+
+	// EntityCount is the number of entities holding exactly RoleCount roles
+	EntityCount int64
+}
+
+// RoleAssignmentCounts returns the number of live assignments grouped by role,
+// and optionally by entity type too. Roles without assignments, and soft
+// deleted roles, are not included, see RoleListUnused for the former
+func (store *store) RoleAssignmentCounts(ctx context.Context, groupByEntityType bool) ([]RoleAssignmentCount, error) {
+	now := carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+	entityRoleTable := goqu.T(store.entityRoleTableName)
+	roleTable := goqu.T(store.roleTableName)
+
+	columns := []any{entityRoleTable.Col(COLUMN_ROLE_ID).As(COLUMN_ROLE_ID)}
+	groupBy := []any{entityRoleTable.Col(COLUMN_ROLE_ID)}
+	orderBy := []exp.OrderedExpression{entityRoleTable.Col(COLUMN_ROLE_ID).Asc()}
+
+	if groupByEntityType {
+		columns = append(columns, entityRoleTable.Col(COLUMN_ENTITY_TYPE).As(COLUMN_ENTITY_TYPE))
+		groupBy = append(groupBy, entityRoleTable.Col(COLUMN_ENTITY_TYPE))
+		orderBy = append(orderBy, entityRoleTable.Col(COLUMN_ENTITY_TYPE).Asc())
+	}
+
+	sqlStr, sqlParams, errSql := goqu.Dialect(store.dbDriverName).
+		From(entityRoleTable).
+		InnerJoin(roleTable, goqu.On(roleTable.Col(COLUMN_ID).Eq(entityRoleTable.Col(COLUMN_ROLE_ID)))).
+		Prepared(true).
+		Select(append(columns, goqu.COUNT(goqu.Star()).As(statsCountAlias))...).
+		Where(
+			entityRoleTable.Col(COLUMN_SOFT_DELETED_AT).Gt(now),
+			roleTable.Col(COLUMN_SOFT_DELETED_AT).Gt(now),
+		).
+		GroupBy(groupBy...).
+		Order(orderBy...).
+		ToSQL()
+
+	if errSql != nil {
+		return []RoleAssignmentCount{}, errSql
+	}
+
+	rows, err := store.selectToMaps(ctx, sqlStr, sqlParams...)
+
+	if err != nil {
+		return []RoleAssignmentCount{}, err
+	}
+
+	counts := lo.Map(rows, func(row map[string]string, _ int) RoleAssignmentCount {
+		return RoleAssignmentCount{
+			RoleID:     row[COLUMN_ROLE_ID],
+			EntityType: row[COLUMN_ENTITY_TYPE],
+			Count:      cast.ToInt64(row[statsCountAlias]),
+		}
+	})
+
+	return counts, nil
+}
+
+// RoleListUnused returns the live roles, which have no live assignments,
+// ordered by handle
+func (store *store) RoleListUnused(ctx context.Context) ([]RoleInterface, error) {
+	now := carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)
+
+	assigned := goqu.Dialect(store.dbDriverName).
+		From(store.entityRoleTableName).
+		Select(goqu.C(COLUMN_ROLE_ID)).
+		Where(goqu.C(COLUMN_SOFT_DELETED_AT).Gt(now))
+
+	sqlStr, sqlParams, errSql := goqu.Dialect(store.dbDriverName).
+		From(store.roleTableName).
+		Prepared(true).
+		Where(
+			goqu.C(COLUMN_SOFT_DELETED_AT).Gt(now),
+			goqu.C(COLUMN_ID).NotIn(assigned),
+		).
+		Order(goqu.C(COLUMN_HANDLE).Asc(), goqu.C(COLUMN_ID).Asc()).
+		ToSQL()
+
+	if errSql != nil {
+		return []RoleInterface{}, errSql
+	}
+
+	rows, err := store.selectToMaps(ctx, sqlStr, sqlParams...)
+
+	if err != nil {
+		return []RoleInterface{}, err
+	}
+
+	list := lo.Map(rows, func(row map[string]string, _ int) RoleInterface {
+		return NewRoleFromExistingData(row)
+	})
+
+	return list, nil
+}
+
+// EntityRoleCountHistogram returns how many entities hold each number of
+// live roles, ordered by the number of roles. Entities without roles are
+// not included. If entityType is not empty, only entities of that type
+// are counted
+func (store *store) EntityRoleCountHistogram(ctx context.Context, entityType string) ([]EntityRoleCountBucket, error) {
+	perEntity := goqu.Dialect(store.dbDriverName).
+		From(store.entityRoleTableName).
+		Select(
+			goqu.C(COLUMN_ENTITY_TYPE),
+			goqu.C(COLUMN_ENTITY_ID),
+			goqu.COUNT(goqu.Star()).As(statsRoleCountAlias),
+		).
+		Where(goqu.C(COLUMN_SOFT_DELETED_AT).Gt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))).
+		GroupBy(goqu.C(COLUMN_ENTITY_TYPE), goqu.C(COLUMN_ENTITY_ID))
+
+	if entityType != "" {
+		perEntity = perEntity.Where(goqu.C(COLUMN_ENTITY_TYPE).Eq(entityType))
+	}
+
+	sqlStr, sqlParams, errSql := goqu.Dialect(store.dbDriverName).
+		From(perEntity.As("rolestore_per_entity")).
+		Prepared(true).
+		Select(
+			goqu.C(statsRoleCountAlias),
+			goqu.COUNT(goqu.Star()).As(statsEntityCountAlias),
+		).
+		GroupBy(goqu.C(statsRoleCountAlias)).
+		Order(goqu.C(statsRoleCountAlias).Asc()).
+		ToSQL()
+
+	if errSql != nil {
+		return []EntityRoleCountBucket{}, errSql
+	}
+
+	rows, err := store.selectToMaps(ctx, sqlStr, sqlParams...)
+
+	if err != nil {
+		return []EntityRoleCountBucket{}, err
+	}
+
+	buckets := lo.Map(rows, func(row map[string]string, _ int) EntityRoleCountBucket {
+		return EntityRoleCountBucket{
+			RoleCount:   cast.ToInt64(row[statsRoleCountAlias]),
+			EntityCount: cast.ToInt64(row[statsEntityCountAlias]),
+		}
+	})
+
+	return buckets, nil
+}
+
+// selectToMaps runs a select and returns the rows as string maps
+func (store *store) selectToMaps(ctx context.Context, sqlStr string, sqlParams ...any) ([]map[string]string, error) {
+	store.logSql("select", sqlStr, sqlParams...)
+
+	if store.db == nil {
+		return nil, errors.New("rolestore: database is nil")
+	}
+
+	return database.SelectToMapString(store.toQuerableContext(ctx), sqlStr, sqlParams...)
+}
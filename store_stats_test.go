@@ -0,0 +1,192 @@
+package rolestore
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStoreRoleStatistics(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	roleAdmin := NewRole().SetStatus(ROLE_STATUS_ACTIVE).SetHandle("admin").SetTitle("Admin")
+	roleManager := NewRole().SetStatus(ROLE_STATUS_ACTIVE).SetHandle("manager").SetTitle("Manager")
+	roleGuest := NewRole().SetStatus(ROLE_STATUS_ACTIVE).SetHandle("guest").SetTitle("Guest")
+	roleAuditor := NewRole().SetStatus(ROLE_STATUS_ACTIVE).SetHandle("auditor").SetTitle("Auditor")
+
+	for _, role := range []RoleInterface{roleAdmin, roleManager, roleGuest, roleAuditor} {
+		if err := store.RoleCreate(context.Background(), role); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	entityRoles := []EntityRoleInterface{
+		NewEntityRole().SetEntityType("USER").SetEntityID("USER_01").SetRoleID(roleAdmin.ID()),
+		NewEntityRole().SetEntityType("USER").SetEntityID("USER_01").SetRoleID(roleManager.ID()),
+		NewEntityRole().SetEntityType("USER").SetEntityID("USER_02").SetRoleID(roleManager.ID()),
+		NewEntityRole().SetEntityType("GROUP").SetEntityID("GROUP_01").SetRoleID(roleManager.ID()),
+		NewEntityRole().SetEntityType("USER").SetEntityID("USER_03").SetRoleID(roleGuest.ID()),
+	}
+
+	for _, entityRole := range entityRoles {
+		if err := store.EntityRoleCreate(context.Background(), entityRole); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	// the only assignment of guest is soft deleted, so guest is unused
+	if err := store.EntityRoleSoftDelete(context.Background(), entityRoles[4]); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// assignment counts per role
+	counts, err := store.RoleAssignmentCounts(context.Background(), false)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	countsByRole := map[string]int64{}
+
+	for _, count := range counts {
+		if count.EntityType != "" {
+			t.Fatal("Entity type must be empty, found:", count.EntityType)
+		}
+		countsByRole[count.RoleID] = count.Count
+	}
+
+	if len(countsByRole) != 2 {
+		t.Fatal("Role count must be 2, found:", len(countsByRole))
+	}
+
+	if countsByRole[roleAdmin.ID()] != 1 {
+		t.Fatal("Admin count must be 1, found:", countsByRole[roleAdmin.ID()])
+	}
+
+	if countsByRole[roleManager.ID()] != 3 {
+		t.Fatal("Manager count must be 3, found:", countsByRole[roleManager.ID()])
+	}
+
+	// assignment counts per role and entity type
+	counts, err = store.RoleAssignmentCounts(context.Background(), true)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(counts) != 3 {
+		t.Fatal("Count rows must be 3, found:", len(counts))
+	}
+
+	for _, count := range counts {
+		if count.RoleID == roleManager.ID() && count.EntityType == "USER" && count.Count != 2 {
+			t.Fatal("Manager USER count must be 2, found:", count.Count)
+		}
+
+		if count.RoleID == roleManager.ID() && count.EntityType == "GROUP" && count.Count != 1 {
+			t.Fatal("Manager GROUP count must be 1, found:", count.Count)
+		}
+	}
+
+	// unused roles
+	unused, err := store.RoleListUnused(context.Background())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(unused) != 2 {
+		t.Fatal("Unused role count must be 2, found:", len(unused))
+	}
+
+	if unused[0].Handle() != "auditor" || unused[1].Handle() != "guest" {
+		t.Fatal("Unused roles must be auditor and guest, found:", unused[0].Handle(), unused[1].Handle())
+	}
+
+	// histogram of roles per entity
+	buckets, err := store.EntityRoleCountHistogram(context.Background(), "")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(buckets) != 2 {
+		t.Fatal("Bucket count must be 2, found:", len(buckets))
+	}
+
+	if buckets[0].RoleCount != 1 || buckets[0].EntityCount != 2 {
+		t.Fatal("First bucket must be 1 role / 2 entities, found:", buckets[0])
+	}
+
+	if buckets[1].RoleCount != 2 || buckets[1].EntityCount != 1 {
+		t.Fatal("Second bucket must be 2 roles / 1 entity, found:", buckets[1])
+	}
+
+	buckets, err = store.EntityRoleCountHistogram(context.Background(), "GROUP")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(buckets) != 1 || buckets[0].RoleCount != 1 || buckets[0].EntityCount != 1 {
+		t.Fatal("GROUP histogram must be a single 1 role / 1 entity bucket, found:", buckets)
+	}
+}
+
+func TestStoreRoleAssignmentCounts_SoftDeletedRole(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	roleAdmin := createTestRole(t, store, "admin", nil)
+	roleLegacy := createTestRole(t, store, "legacy", nil)
+
+	for _, roleID := range []string{roleAdmin.ID(), roleLegacy.ID()} {
+		err := store.EntityRoleCreate(context.Background(), NewEntityRole().
+			SetEntityType("USER").
+			SetEntityID("USER_01").
+			SetRoleID(roleID))
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	// the assignment of the soft deleted role is kept, but MUST NOT be counted
+	if err := store.RoleSoftDelete(context.Background(), roleLegacy); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	for _, groupByEntityType := range []bool{false, true} {
+		counts, err := store.RoleAssignmentCounts(context.Background(), groupByEntityType)
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if len(counts) != 1 || counts[0].RoleID != roleAdmin.ID() || counts[0].Count != 1 {
+			t.Fatal("unexpected counts:", counts)
+		}
+
+		if groupByEntityType && counts[0].EntityType != "USER" {
+			t.Fatal("unexpected entity type:", counts[0].EntityType)
+		}
+	}
+}
@@ -1,11 +1,13 @@
 package rolestore
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"errors"
 	"log/slog"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/gouniverse/base/database"
@@ -59,6 +61,51 @@ func initStore(filepath string) (StoreInterface, error) {
 	return store, nil
 }
 
+// initStoreWithOptions returns a store on an in-memory database, with the
+// role and entity role tables of initStore, and the optional tables, hooks
+// and settings set in options
+func initStoreWithOptions(t *testing.T, options NewStoreOptions) StoreInterface {
+	db, err := initDB(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	options.DB = db
+	options.RoleTableName = "roles_role_table"
+	options.EntityRoleTableName = "roles_entity_role_table"
+	options.AutomigrateEnabled = true
+
+	store, err := NewStore(options)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	return store
+}
+
+// createTestRole creates an active role with the handle as title,
+// configured by configure before it is saved (may be nil)
+func createTestRole(t *testing.T, store StoreInterface, handle string, configure func(role RoleInterface) error) RoleInterface {
+	role := NewRole().
+		SetStatus(ROLE_STATUS_ACTIVE).
+		SetHandle(handle).
+		SetTitle(handle)
+
+	if configure != nil {
+		if err := configure(role); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	if err := store.RoleCreate(context.Background(), role); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	return role
+}
+
 func TestStoreWithTx(t *testing.T) {
 	store, err := initStore("test_store_with_tx.db")
 
@@ -144,3 +191,172 @@ func TestStoreWithTx(t *testing.T) {
 		t.Fatal("Role MUST be ROLE_TITLE_2, as transaction committed")
 	}
 }
+
+func TestStoreAutoMigrate_LegacyTables(t *testing.T) {
+	db, err := initDB(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// the tables, as created before the version and provenance columns were added
+	legacy := []string{
+		`CREATE TABLE roles_role_table (id TEXT PRIMARY KEY, status TEXT, handle TEXT, title TEXT, metas TEXT, memo TEXT, created_at DATETIME, updated_at DATETIME, soft_deleted_at DATETIME)`,
+		`INSERT INTO roles_role_table VALUES ('ROLE_01', 'active', 'admin', 'Admin', '{}', '', '2020-01-01 00:00:00', '2020-01-01 00:00:00', '9999-12-31 23:59:59')`,
+		`CREATE TABLE roles_entity_role_table (id TEXT PRIMARY KEY, entity_type TEXT, entity_id TEXT, role_id TEXT, metas TEXT, memo TEXT, created_at DATETIME, updated_at DATETIME, soft_deleted_at DATETIME)`,
+		`INSERT INTO roles_entity_role_table VALUES ('ENTITY_ROLE_01', 'user', 'USER_01', 'ROLE_01', '{}', '', '2020-01-01 00:00:00', '2020-01-01 00:00:00', '9999-12-31 23:59:59')`,
+	}
+
+	for _, sqlStr := range legacy {
+		if _, err := db.Exec(sqlStr); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	for range 2 { // migrating twice is a no-op
+		_, err = NewStore(NewStoreOptions{
+			DB:                  db,
+			RoleTableName:       "roles_role_table",
+			EntityRoleTableName: "roles_entity_role_table",
+			AutomigrateEnabled:  true,
+		})
+
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	store, err := NewStore(NewStoreOptions{
+		DB:                  db,
+		RoleTableName:       "roles_role_table",
+		EntityRoleTableName: "roles_entity_role_table",
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	role, err := store.RoleFindByID(context.Background(), "ROLE_01")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if role == nil || role.Version() != 1 {
+		t.Fatal("legacy role MUST be found with version 1")
+	}
+
+	role.SetTitle("Administrator")
+
+	if err := store.RoleUpdate(context.Background(), role); err != nil {
+		t.Fatal("legacy role MUST be updatable, found:", err)
+	}
+
+	if role.Version() != 2 {
+		t.Fatal("unexpected version:", role.Version())
+	}
+
+	entityRole, err := store.EntityRoleFindByID(context.Background(), "ENTITY_ROLE_01")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if entityRole == nil || entityRole.Version() != 1 || entityRole.Source() != ENTITY_ROLE_SOURCE_MANUAL {
+		t.Fatal("legacy entity role MUST be found with version 1 and source manual")
+	}
+
+	entityRole.SetMemo("migrated")
+
+	if err := store.EntityRoleUpdate(context.Background(), entityRole); err != nil {
+		t.Fatal("legacy entity role MUST be updatable, found:", err)
+	}
+}
+
+func TestStoreAutoMigrate_LegacyDuplicateEntityRoles(t *testing.T) {
+	db, err := initDB(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// an entity role table of an earlier version, holding a duplicate live mapping
+	legacy := []string{
+		`CREATE TABLE roles_entity_role_table (id TEXT PRIMARY KEY, entity_type TEXT, entity_id TEXT, role_id TEXT, metas TEXT, memo TEXT, created_at DATETIME, updated_at DATETIME, soft_deleted_at DATETIME)`,
+		`INSERT INTO roles_entity_role_table VALUES ('ENTITY_ROLE_01', 'user', 'USER_01', 'ROLE_01', '{}', '', '2020-01-01 00:00:00', '2020-01-01 00:00:00', '9999-12-31 23:59:59')`,
+		`INSERT INTO roles_entity_role_table VALUES ('ENTITY_ROLE_02', 'user', 'USER_01', 'ROLE_01', '{}', '', '2020-01-02 00:00:00', '2020-01-02 00:00:00', '9999-12-31 23:59:59')`,
+	}
+
+	for _, sqlStr := range legacy {
+		if _, err := db.Exec(sqlStr); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	logs := &bytes.Buffer{}
+
+	store, err := NewStore(NewStoreOptions{
+		DB:                  db,
+		RoleTableName:       "roles_role_table",
+		EntityRoleTableName: "roles_entity_role_table",
+		AutomigrateEnabled:  true,
+		SqlLogger:           slog.New(slog.NewTextHandler(logs, nil)),
+	})
+
+	if err != nil {
+		t.Fatal("duplicates MUST NOT fail the migration, found:", err)
+	}
+
+	if !strings.Contains(logs.String(), "duplicate live entity role") || !strings.Contains(logs.String(), "USER_01") {
+		t.Fatal("duplicates MUST be reported, found:", logs.String())
+	}
+
+	indexCount := func() int {
+		count := 0
+
+		if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name = 'roles_entity_role_table_live_assignment'").Scan(&count); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		return count
+	}
+
+	if indexCount() != 0 {
+		t.Fatal("the unique index MUST NOT be added while there are duplicates")
+	}
+
+	// without the index, the upsert finds and then writes
+	err = store.EntityRoleUpsert(context.Background(), NewEntityRole().
+		SetEntityType("user").
+		SetEntityID("USER_01").
+		SetRoleID("ROLE_01").
+		SetMemo("upserted"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.EntityRoleDeleteByID(context.Background(), "ENTITY_ROLE_02"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.AutoMigrate(); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if indexCount() != 1 {
+		t.Fatal("the unique index MUST be added, once the duplicates are removed")
+	}
+}
@@ -0,0 +1,143 @@
+package rolestore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+
+	"github.com/gouniverse/base/database"
+	"github.com/gouniverse/sb"
+)
+
+// txDepthKey is the context key holding the savepoint nesting depth
+type txDepthKey struct{}
+
+// WithTx runs fn within a transaction, using the default isolation level
+// configured for the store.
+//
+// The transaction is committed when fn returns nil, and rolled back when
+// fn returns an error or panics. All store operations inside fn must use
+// the supplied txCtx.
+//
+// If ctx already carries a transaction (i.e. WithTx is nested), a savepoint
+// is created instead, so that only the work of the inner fn is rolled back
+// on error. Dialects without savepoint support simply join the outer transaction.
+func (store *store) WithTx(ctx context.Context, fn func(txCtx context.Context) error) error {
+	return store.WithTxOptions(ctx, nil, fn)
+}
+
+// WithTxOptions is the same as WithTx, but allows to specify the transaction
+// options (i.e. isolation level, read only) explicitly.
+//
+// The options are ignored when the call is nested in an outer transaction.
+func (store *store) WithTxOptions(ctx context.Context, opts *sql.TxOptions, fn func(txCtx context.Context) error) error {
+	if fn == nil {
+		return errors.New("rolestore: transaction func is nil")
+	}
+
+	if qctx, ok := ctx.(database.QueryableContext); ok && qctx.IsTx() {
+		return store.withSavepoint(qctx, fn)
+	}
+
+	if opts == nil {
+		opts = &sql.TxOptions{Isolation: store.txIsolationLevel}
+	}
+
+	tx, err := store.beginTx(ctx, opts)
+
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(database.Context(ctx, tx)); err != nil {
+		if errRollback := tx.Rollback(); errRollback != nil {
+			return errors.Join(err, errRollback)
+		}
+
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// beginTx begins a transaction on the queryable carried by the context,
+// or on the store database if there is none
+func (store *store) beginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	if qctx, ok := ctx.(database.QueryableContext); ok {
+		switch queryable := qctx.Queryable().(type) {
+		case *sql.DB:
+			return queryable.BeginTx(ctx, opts)
+		case *sql.Conn:
+			return queryable.BeginTx(ctx, opts)
+		}
+	}
+
+	if store.db == nil {
+		return nil, errors.New("rolestore: database is nil")
+	}
+
+	return store.db.BeginTx(ctx, opts)
+}
+
+// withSavepoint runs fn within a savepoint of the transaction carried by
+// the context, rolling back to the savepoint if fn fails
+func (store *store) withSavepoint(ctx database.QueryableContext, fn func(txCtx context.Context) error) error {
+	if !store.savepointsSupported() {
+		return fn(ctx)
+	}
+
+	depth, _ := ctx.Value(txDepthKey{}).(int)
+	depth++
+
+	savepoint := "rolestore_sp_" + strconv.Itoa(depth)
+	txCtx := database.Context(context.WithValue(ctx, txDepthKey{}, depth), ctx.Queryable())
+
+	if err := store.execTxStatement(txCtx, "SAVEPOINT "+savepoint); err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = store.execTxStatement(txCtx, "ROLLBACK TO SAVEPOINT "+savepoint)
+			panic(p)
+		}
+	}()
+
+	if err := fn(txCtx); err != nil {
+		if errRollback := store.execTxStatement(txCtx, "ROLLBACK TO SAVEPOINT "+savepoint); errRollback != nil {
+			return errors.Join(err, errRollback)
+		}
+
+		return err
+	}
+
+	return store.execTxStatement(txCtx, "RELEASE SAVEPOINT "+savepoint)
+}
+
+// execTxStatement executes a transaction control statement
+func (store *store) execTxStatement(ctx database.QueryableContext, sqlStr string) error {
+	store.logSql("transaction", sqlStr)
+
+	_, err := database.Execute(ctx, sqlStr)
+
+	return err
+}
+
+// savepointsSupported returns whether the database dialect supports
+// the standard SAVEPOINT, ROLLBACK TO SAVEPOINT and RELEASE SAVEPOINT statements
+func (store *store) savepointsSupported() bool {
+	switch store.dbDriverName {
+	case sb.DIALECT_MYSQL, sb.DIALECT_POSTGRES, sb.DIALECT_SQLITE:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,210 @@
+package rolestore
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestStoreWithTxCommit(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	role := NewRole().
+		SetStatus(ROLE_STATUS_ACTIVE).
+		SetHandle("ROLE_HANDLE").
+		SetTitle("ROLE_TITLE")
+
+	err = store.WithTx(context.Background(), func(txCtx context.Context) error {
+		if err := store.RoleCreate(txCtx, role); err != nil {
+			return err
+		}
+
+		return store.RoleUpdate(txCtx, role.SetTitle("ROLE_TITLE_2"))
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	roleFound, err := store.RoleFindByID(context.Background(), role.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if roleFound == nil {
+		t.Fatal("Role MUST NOT be nil, as transaction committed")
+	}
+
+	if roleFound.Title() != "ROLE_TITLE_2" {
+		t.Fatal("Title MUST be ROLE_TITLE_2, found:", roleFound.Title())
+	}
+}
+
+func TestStoreWithTxRollback(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	role := NewRole().
+		SetStatus(ROLE_STATUS_ACTIVE).
+		SetHandle("ROLE_HANDLE").
+		SetTitle("ROLE_TITLE")
+
+	errExpected := errors.New("expected error")
+
+	err = store.WithTx(context.Background(), func(txCtx context.Context) error {
+		if err := store.RoleCreate(txCtx, role); err != nil {
+			return err
+		}
+
+		return errExpected
+	})
+
+	if !errors.Is(err, errExpected) {
+		t.Fatal("must return the error of the transaction func, found:", err)
+	}
+
+	roleFound, err := store.RoleFindByID(context.Background(), role.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if roleFound != nil {
+		t.Fatal("Role MUST be nil, as transaction rolled back")
+	}
+}
+
+func TestStoreWithTxRollbackOnPanic(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	role := NewRole().
+		SetStatus(ROLE_STATUS_ACTIVE).
+		SetHandle("ROLE_HANDLE").
+		SetTitle("ROLE_TITLE")
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("panic MUST be propagated")
+			}
+		}()
+
+		_ = store.WithTx(context.Background(), func(txCtx context.Context) error {
+			if err := store.RoleCreate(txCtx, role); err != nil {
+				return err
+			}
+
+			panic("expected panic")
+		})
+	}()
+
+	roleFound, err := store.RoleFindByID(context.Background(), role.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if roleFound != nil {
+		t.Fatal("Role MUST be nil, as transaction rolled back")
+	}
+}
+
+func TestStoreWithTxNestedSavepoint(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	roleOuter := NewRole().
+		SetStatus(ROLE_STATUS_ACTIVE).
+		SetHandle("ROLE_OUTER").
+		SetTitle("ROLE_OUTER")
+
+	roleInner := NewRole().
+		SetStatus(ROLE_STATUS_ACTIVE).
+		SetHandle("ROLE_INNER").
+		SetTitle("ROLE_INNER")
+
+	errExpected := errors.New("expected error")
+
+	err = store.WithTx(context.Background(), func(txCtx context.Context) error {
+		if err := store.RoleCreate(txCtx, roleOuter); err != nil {
+			return err
+		}
+
+		errInner := store.WithTx(txCtx, func(innerCtx context.Context) error {
+			if err := store.RoleCreate(innerCtx, roleInner); err != nil {
+				return err
+			}
+
+			return errExpected
+		})
+
+		if !errors.Is(errInner, errExpected) {
+			t.Fatal("must return the error of the inner transaction func, found:", errInner)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	roleFound, err := store.RoleFindByID(context.Background(), roleOuter.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if roleFound == nil {
+		t.Fatal("Outer role MUST NOT be nil, as outer transaction committed")
+	}
+
+	roleFound, err = store.RoleFindByID(context.Background(), roleInner.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if roleFound != nil {
+		t.Fatal("Inner role MUST be nil, as savepoint rolled back")
+	}
+}
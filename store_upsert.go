@@ -0,0 +1,132 @@
+package rolestore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/dromara/carbon/v2"
+)
+
+// RoleUpsertByHandle creates the role, or updates the live role with the
+// same handle if one already exists.
+//
+// When updating, the ID, version and creation date of the existing role
+// are copied onto the given role, so that afterwards it represents the
+// stored record.
+//
+// Native ON CONFLICT / ON DUPLICATE KEY cannot be used on any dialect,
+// because the role table has no unique index on the handle (soft deleted
+// roles keep their handle, and live roles may share one). The lookup and
+// the write are therefore run in one serializable transaction, or in a
+// savepoint when ctx already carries a transaction. A savepoint keeps the
+// isolation level of the outer transaction, so when nesting, begin the
+// outer transaction as serializable (see WithTxOptions), otherwise
+// concurrent upserts of the same handle may both create a role.
+func (store *store) RoleUpsertByHandle(ctx context.Context, role RoleInterface) error {
+	if role == nil {
+		return errors.New("rolestore > RoleUpsertByHandle. role is nil")
+	}
+
+	if role.Handle() == "" {
+		return errors.New("rolestore > RoleUpsertByHandle. role handle is empty")
+	}
+
+	opts := &sql.TxOptions{Isolation: sql.LevelSerializable}
+
+	return store.WithTxOptions(ctx, opts, func(txCtx context.Context) error {
+		existing, err := store.RoleFindByHandle(txCtx, role.Handle())
+
+		if err != nil {
+			return err
+		}
+
+		if existing == nil {
+			return store.RoleCreate(txCtx, role)
+		}
+
+		role.SetID(existing.ID())
+		role.SetCreatedAt(existing.CreatedAtCarbon().ToDateTimeString(carbon.UTC))
+		role.SetVersion(existing.Version())
+
+		return store.RoleUpdate(txCtx, role)
+	})
+}
+
+// EntityRoleUpsert creates the role entity mapping, or updates the live
+// mapping with the same entity type, entity ID and role ID if one already
+// exists.
+//
+// When updating, the ID, version and creation date of the existing mapping
+// are copied onto the given one.
+//
+// On SQLite and Postgres the insert is an INSERT ... ON CONFLICT on the
+// partial unique index AutoMigrate adds on the triple of the mappings
+// without a soft delete date, so a mapping inserted concurrently is updated
+// instead of duplicated, whatever the isolation level. If the index is
+// missing (i.e. AutoMigrate found duplicates), and on MySQL, which has no
+// partial indexes, the upsert relies on the serializable transaction, like
+// RoleUpsertByHandle, and is not protected when nested in a transaction
+// with a weaker isolation level.
+func (store *store) EntityRoleUpsert(ctx context.Context, entityRole EntityRoleInterface) error {
+	if entityRole == nil {
+		return errors.New("rolestore > EntityRoleUpsert. entityRole is nil")
+	}
+
+	if entityRole.RoleID() == "" {
+		return errors.New("rolestore > EntityRoleUpsert. entityRole roleID is empty")
+	}
+
+	if entityRole.EntityID() == "" {
+		return errors.New("rolestore > EntityRoleUpsert. entityRole entityID is empty")
+	}
+
+	if entityRole.EntityType() == "" {
+		return errors.New("rolestore > EntityRoleUpsert. entityRole entityType is empty")
+	}
+
+	opts := &sql.TxOptions{Isolation: sql.LevelSerializable}
+
+	return store.WithTxOptions(ctx, opts, func(txCtx context.Context) error {
+		existing, err := store.EntityRoleFindByEntityAndRole(
+			txCtx,
+			entityRole.EntityType(),
+			entityRole.EntityID(),
+			entityRole.RoleID(),
+		)
+
+		if err != nil {
+			return err
+		}
+
+		if existing == nil {
+			return store.entityRoleUpsertCreate(txCtx, entityRole)
+		}
+
+		entityRole.SetID(existing.ID())
+		entityRole.SetCreatedAt(existing.CreatedAtCarbon().ToDateTimeString(carbon.UTC))
+		entityRole.SetVersion(existing.Version())
+
+		return store.EntityRoleUpdate(txCtx, entityRole)
+	})
+}
+
+// entityRoleUpsertCreate creates the mapping of EntityRoleUpsert, with
+// ON CONFLICT on the partial unique index of the live mappings, if it exists
+func (store *store) entityRoleUpsertCreate(ctx context.Context, entityRole EntityRoleInterface) error {
+	onConflict, err := store.indexExists(ctx, store.entityRoleLiveUniqueIndex())
+
+	if err != nil {
+		return err
+	}
+
+	if !onConflict {
+		return store.EntityRoleCreate(ctx, entityRole)
+	}
+
+	if err := validateEntityRoleProvenance("EntityRoleUpsert", entityRole); err != nil {
+		return err
+	}
+
+	return store.entityRoleCreate(ctx, entityRole, true)
+}
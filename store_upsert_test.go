@@ -0,0 +1,228 @@
+package rolestore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/gouniverse/sb"
+)
+
+func TestStoreRoleUpsertByHandle(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	role := NewRole().
+		SetStatus(ROLE_STATUS_ACTIVE).
+		SetHandle("admin").
+		SetTitle("Admin")
+
+	if err := store.RoleUpsertByHandle(context.Background(), role); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	roleUpdated := NewRole().
+		SetStatus(ROLE_STATUS_INACTIVE).
+		SetHandle("admin").
+		SetTitle("Administrator")
+
+	if err := store.RoleUpsertByHandle(context.Background(), roleUpdated); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if roleUpdated.ID() != role.ID() {
+		t.Fatal("ID MUST be the ID of the existing role, found:", roleUpdated.ID())
+	}
+
+	if roleUpdated.Version() != 2 {
+		t.Fatal("unexpected version:", roleUpdated.Version())
+	}
+
+	count, err := store.RoleCount(context.Background(), NewRoleQuery().SetHandle("admin"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 1 {
+		t.Fatal("unexpected role count:", count)
+	}
+
+	roleFound, err := store.RoleFindByID(context.Background(), role.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if roleFound.Title() != "Administrator" || roleFound.Status() != ROLE_STATUS_INACTIVE {
+		t.Fatal("role MUST be updated, found:", roleFound.Title(), roleFound.Status())
+	}
+
+	if roleFound.CreatedAtCarbon().ToDateTimeString() != role.CreatedAtCarbon().ToDateTimeString() {
+		t.Fatal("created at MUST be kept, found:", roleFound.CreatedAt())
+	}
+
+	if err := store.RoleUpsertByHandle(context.Background(), NewRole()); err == nil {
+		t.Fatal("error MUST NOT be nil for an empty handle")
+	}
+}
+
+func TestStoreEntityRoleUpsert(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	entityRole := NewEntityRole().
+		SetEntityType("USER").
+		SetEntityID("USER_01").
+		SetRoleID("ROLE_01").
+		SetMemo("first")
+
+	if err := store.EntityRoleUpsert(context.Background(), entityRole); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	entityRoleUpdated := NewEntityRole().
+		SetEntityType("USER").
+		SetEntityID("USER_01").
+		SetRoleID("ROLE_01").
+		SetMemo("second")
+
+	if err := store.EntityRoleUpsert(context.Background(), entityRoleUpdated); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if entityRoleUpdated.ID() != entityRole.ID() {
+		t.Fatal("ID MUST be the ID of the existing entity role, found:", entityRoleUpdated.ID())
+	}
+
+	list, err := store.EntityRoleList(context.Background(), NewEntityRoleQuery().
+		SetEntityType("USER").
+		SetEntityID("USER_01"))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(list) != 1 {
+		t.Fatal("unexpected entity roles length:", len(list))
+	}
+
+	if list[0].Memo() != "second" {
+		t.Fatal("entity role MUST be updated, found:", list[0].Memo())
+	}
+
+	// a soft deleted mapping is not reused
+	if err := store.EntityRoleSoftDelete(context.Background(), list[0]); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	entityRoleNew := NewEntityRole().
+		SetEntityType("USER").
+		SetEntityID("USER_01").
+		SetRoleID("ROLE_01")
+
+	if err := store.EntityRoleUpsert(context.Background(), entityRoleNew); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if entityRoleNew.ID() == entityRole.ID() {
+		t.Fatal("a new entity role MUST be created")
+	}
+}
+
+func TestStoreEntityRoleUpsert_LiveUniqueIndex(t *testing.T) {
+	store, err := initStore(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	role := createTestRole(t, store, "editor", nil)
+
+	previous := NewEntityRole().SetEntityType("user").SetEntityID("USER_01").SetRoleID(role.ID())
+
+	if err := store.EntityRoleCreate(context.Background(), previous); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.EntityRoleSoftDelete(context.Background(), previous); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = store.EntityRoleUpsert(context.Background(), NewEntityRole().
+		SetEntityType("user").
+		SetEntityID("USER_01").
+		SetRoleID(role.ID()))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// the index rejects a second live mapping of the same triple
+	err = store.EntityRoleUpdate(context.Background(), previous.SetSoftDeletedAt(sb.MAX_DATETIME))
+
+	if err == nil {
+		t.Fatal("error MUST NOT be nil for a duplicate live mapping")
+	}
+
+	count, err := store.EntityRoleCount(context.Background(), NewEntityRoleQuery().SetRoleID(role.ID()))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if count != 1 {
+		t.Fatal("unexpected count:", count)
+	}
+
+	// a concurrent upsert, inserting after the lookup, updates the live mapping
+	duplicate := NewEntityRole().SetEntityType("user").SetEntityID("USER_01").SetRoleID(role.ID()).SetMemo("concurrent")
+
+	sqlStr, params, errSql := goqu.Insert("roles_entity_role_table").Prepared(true).Rows(duplicate.Data()).ToSQL()
+
+	if errSql != nil {
+		t.Fatal("unexpected error:", errSql)
+	}
+
+	sqlStr += store.(interface {
+		sqlEntityRoleLiveOnConflict(columns []string) string
+	}).sqlEntityRoleLiveOnConflict([]string{COLUMN_MEMO})
+
+	if _, err := store.DB().Exec(sqlStr, params...); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	live, err := store.EntityRoleFindByEntityAndRole(context.Background(), "user", "USER_01", role.ID())
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if live == nil || live.ID() == duplicate.ID() || live.Memo() != "concurrent" || live.Version() != 2 {
+		t.Fatal("the live mapping MUST be updated on conflict")
+	}
+}
@@ -0,0 +1,218 @@
+package rolestore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/samber/lo"
+)
+
+// UserRoles returns the active roles of the user, ordered by handle,
+// including the roles delegated to the user, if delegations are enabled.
+// The user is treated as an entity of type ENTITY_TYPE_USER
+func (store *store) UserRoles(ctx context.Context, user UserInterface) ([]RoleInterface, error) {
+	if err := validateUser("UserRoles", user); err != nil {
+		return []RoleInterface{}, err
+	}
+
+	return store.EntityEffectiveRoles(ctx, ENTITY_TYPE_USER, user.ID())
+}
+
+// userDirectRoles returns the active roles assigned to the user, ordered by handle
+func (store *store) userDirectRoles(ctx context.Context, user UserInterface) ([]RoleInterface, error) {
+	rolesByUser, err := store.EntitiesRoles(ctx, ENTITY_TYPE_USER, []string{user.ID()})
+
+	if err != nil {
+		return []RoleInterface{}, err
+	}
+
+	roles, ok := rolesByUser[user.ID()]
+
+	if !ok {
+		return []RoleInterface{}, nil
+	}
+
+	return roles, nil
+}
+
+// UserAssignRole assigns the role with the given handle to the user,
+// unless the user already holds it.
+//
+// With USER_ROLE_SYNC_FROM_ENTITY_ROLES the legacy role field of the user
+// is synced afterwards. The user itself is not saved, this is left to the caller
+func (store *store) UserAssignRole(ctx context.Context, user UserInterface, handle string) error {
+	if err := validateUser("UserAssignRole", user); err != nil {
+		return err
+	}
+
+	if handle == "" {
+		return errors.New("rolestore > UserAssignRole. role handle is empty")
+	}
+
+	return store.WithTx(ctx, func(txCtx context.Context) error {
+		if err := store.userAssignRole(txCtx, user, handle, ENTITY_ROLE_SOURCE_MANUAL); err != nil {
+			return err
+		}
+
+		if store.userRoleSyncMode == USER_ROLE_SYNC_FROM_ENTITY_ROLES {
+			return store.userRoleSyncFromEntityRoles(txCtx, user)
+		}
+
+		return nil
+	})
+}
+
+// UserHasRole returns whether the user holds the active role with the given handle,
+// directly, or by a delegation, if delegations are enabled
+func (store *store) UserHasRole(ctx context.Context, user UserInterface, handle string) (bool, error) {
+	if err := validateUser("UserHasRole", user); err != nil {
+		return false, err
+	}
+
+	if handle == "" {
+		return false, errors.New("rolestore > UserHasRole. role handle is empty")
+	}
+
+	role, err := store.RoleFindByHandle(ctx, handle)
+
+	if err != nil {
+		return false, err
+	}
+
+	if role == nil || !role.IsActive() {
+		return false, nil
+	}
+
+	return store.entityHoldsRole(ctx, NewEntityRef(ENTITY_TYPE_USER, user.ID()), role.ID(), false, map[EntityRef]bool{})
+}
+
+// UserSyncRole keeps the legacy role field of the user consistent with
+// the role entity mappings, in the direction of the configured sync mode:
+//   - USER_ROLE_SYNC_NONE does nothing
+//   - USER_ROLE_SYNC_TO_ENTITY_ROLES assigns the role with the handle
+//     in user.Role() to the user, if not already assigned, and revokes
+//     the roles assigned by an earlier sync, which no longer match it
+//   - USER_ROLE_SYNC_FROM_ENTITY_ROLES sets user.Role() to one of the active
+//     roles the user holds, keeping the current one if still held, or to
+//     empty if the user holds none. The user itself is not saved, this is
+//     left to the caller
+func (store *store) UserSyncRole(ctx context.Context, user UserInterface) error {
+	if err := validateUser("UserSyncRole", user); err != nil {
+		return err
+	}
+
+	switch store.userRoleSyncMode {
+	case USER_ROLE_SYNC_TO_ENTITY_ROLES:
+		return store.userRoleSyncToEntityRoles(ctx, user)
+	case USER_ROLE_SYNC_FROM_ENTITY_ROLES:
+		return store.userRoleSyncFromEntityRoles(ctx, user)
+	}
+
+	return nil
+}
+
+// userRoleSyncToEntityRoles assigns the role with the handle in user.Role()
+// to the user, and soft deletes the other assignments made by the sync
+func (store *store) userRoleSyncToEntityRoles(ctx context.Context, user UserInterface) error {
+	return store.WithTx(ctx, func(txCtx context.Context) error {
+		if user.Role() != "" {
+			if err := store.userAssignRole(txCtx, user, user.Role(), ENTITY_ROLE_SOURCE_SYNC); err != nil {
+				return err
+			}
+		}
+
+		synced, err := store.EntityRoleListWithRole(txCtx, NewEntityRoleQuery().
+			SetEntityType(ENTITY_TYPE_USER).
+			SetEntityID(user.ID()).
+			SetSource(ENTITY_ROLE_SOURCE_SYNC))
+
+		if err != nil {
+			return err
+		}
+
+		for _, assignment := range synced {
+			if assignment.Role.Handle() == user.Role() {
+				continue
+			}
+
+			if err := store.EntityRoleSoftDelete(txCtx, assignment.EntityRole); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// userAssignRole assigns the role with the given handle to the user with
+// the given source, unless the user already holds it
+func (store *store) userAssignRole(ctx context.Context, user UserInterface, handle string, source string) error {
+	role, err := store.RoleFindByHandle(ctx, handle)
+
+	if err != nil {
+		return err
+	}
+
+	if role == nil {
+		return errors.New("rolestore > UserAssignRole. role not found: " + handle)
+	}
+
+	existing, err := store.EntityRoleFindByEntityAndRole(ctx, ENTITY_TYPE_USER, user.ID(), role.ID())
+
+	if err != nil {
+		return err
+	}
+
+	if existing != nil {
+		return nil
+	}
+
+	entityRole := NewEntityRole().
+		SetEntityType(ENTITY_TYPE_USER).
+		SetEntityID(user.ID()).
+		SetRoleID(role.ID()).
+		SetSource(source)
+
+	return store.EntityRoleCreate(ctx, entityRole)
+}
+
+// userRoleSyncFromEntityRoles sets the legacy role field of the user
+// from the active roles assigned to the user. Delegated roles are
+// temporary, so are not synced
+func (store *store) userRoleSyncFromEntityRoles(ctx context.Context, user UserInterface) error {
+	roles, err := store.userDirectRoles(ctx, user)
+
+	if err != nil {
+		return err
+	}
+
+	handles := lo.Map(roles, func(role RoleInterface, _ int) string {
+		return role.Handle()
+	})
+
+	if lo.Contains(handles, user.Role()) {
+		return nil
+	}
+
+	if len(handles) == 0 {
+		user.SetRole("")
+		return nil
+	}
+
+	user.SetRole(handles[0])
+
+	return nil
+}
+
+// validateUser checks the user passed to the user helpers
+func validateUser(method string, user UserInterface) error {
+	if user == nil {
+		return errors.New("rolestore > " + method + ". user is nil")
+	}
+
+	if user.ID() == "" {
+		return errors.New("rolestore > " + method + ". user ID is empty")
+	}
+
+	return nil
+}
@@ -0,0 +1,241 @@
+package rolestore
+
+import (
+	"context"
+	"testing"
+)
+
+// testUser is a minimal UserInterface, implementing only what the store uses
+type testUser struct {
+	UserInterface
+	id   string
+	role string
+}
+
+func (u *testUser) ID() string {
+	return u.id
+}
+
+func (u *testUser) Role() string {
+	return u.role
+}
+
+func (u *testUser) SetRole(role string) UserInterface {
+	u.role = role
+	return u
+}
+
+func initUserStore(t *testing.T, syncMode string) StoreInterface {
+	store := initStoreWithOptions(t, NewStoreOptions{UserRoleSyncMode: syncMode})
+
+	for _, handle := range []string{"admin", "manager"} {
+		createTestRole(t, store, handle, nil)
+	}
+
+	return store
+}
+
+func TestStoreUserRoles(t *testing.T) {
+	store := initUserStore(t, USER_ROLE_SYNC_NONE)
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := &testUser{id: "USER_01"}
+
+	hasRole, err := store.UserHasRole(context.Background(), user, "admin")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if hasRole {
+		t.Fatal("user MUST NOT have the admin role yet")
+	}
+
+	for _, handle := range []string{"manager", "admin", "admin"} {
+		if err := store.UserAssignRole(context.Background(), user, handle); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	roles, err := store.UserRoles(context.Background(), user)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(roles) != 2 {
+		t.Fatal("unexpected roles length:", len(roles))
+	}
+
+	if roles[0].Handle() != "admin" || roles[1].Handle() != "manager" {
+		t.Fatal("unexpected roles:", roles[0].Handle(), roles[1].Handle())
+	}
+
+	hasRole, err = store.UserHasRole(context.Background(), user, "admin")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !hasRole {
+		t.Fatal("user MUST have the admin role")
+	}
+
+	entityRoles, err := store.EntityRoleList(context.Background(), NewEntityRoleQuery().SetEntityType(ENTITY_TYPE_USER))
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(entityRoles) != 2 {
+		t.Fatal("unexpected entity roles length:", len(entityRoles))
+	}
+
+	if err := store.UserAssignRole(context.Background(), user, "unknown"); err == nil {
+		t.Fatal("error MUST NOT be nil for an unknown role")
+	}
+
+	if user.Role() != "" {
+		t.Fatal("legacy role MUST NOT be synced, found:", user.Role())
+	}
+}
+
+func TestStoreUserSyncRole_ToEntityRoles(t *testing.T) {
+	store := initUserStore(t, USER_ROLE_SYNC_TO_ENTITY_ROLES)
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := &testUser{id: "USER_01", role: "manager"}
+
+	if err := store.UserSyncRole(context.Background(), user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	hasRole, err := store.UserHasRole(context.Background(), user, "manager")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !hasRole {
+		t.Fatal("user MUST have the manager role after sync")
+	}
+
+	// a manually assigned role is kept, when the legacy role changes
+	if err := store.UserAssignRole(context.Background(), user, "admin"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	user.SetRole("")
+
+	if err := store.UserSyncRole(context.Background(), user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	roles, err := store.UserRoles(context.Background(), user)
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(roles) != 1 || roles[0].Handle() != "admin" {
+		t.Fatal("synced manager role MUST be revoked, found roles:", len(roles))
+	}
+
+	user.SetRole("manager")
+
+	if err := store.UserSyncRole(context.Background(), user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	user.SetRole("admin")
+
+	if err := store.UserSyncRole(context.Background(), user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	hasRole, err = store.UserHasRole(context.Background(), user, "manager")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if hasRole {
+		t.Fatal("user MUST NOT keep the manager role after the legacy role changed")
+	}
+}
+
+func TestStoreUserSyncRole_FromEntityRoles(t *testing.T) {
+	store := initUserStore(t, USER_ROLE_SYNC_FROM_ENTITY_ROLES)
+
+	defer func() {
+		if err := store.DB().Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	user := &testUser{id: "USER_01", role: "legacy"}
+
+	if err := store.UserAssignRole(context.Background(), user, "manager"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if user.Role() != "manager" {
+		t.Fatal("legacy role MUST be synced, found:", user.Role())
+	}
+
+	// the current role is kept while still held
+	if err := store.UserAssignRole(context.Background(), user, "admin"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if user.Role() != "manager" {
+		t.Fatal("legacy role MUST be kept, found:", user.Role())
+	}
+
+	if _, err := store.EntityRevokeAll(context.Background(), ENTITY_TYPE_USER, user.ID(), false); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := store.UserSyncRole(context.Background(), user); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if user.Role() != "" {
+		t.Fatal("legacy role MUST be empty, found:", user.Role())
+	}
+}
+
+func TestNewStore_InvalidUserRoleSyncMode(t *testing.T) {
+	db, err := initDB(":memory:")
+
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	_, err = NewStore(NewStoreOptions{
+		DB:                  db,
+		RoleTableName:       "roles_role_table",
+		EntityRoleTableName: "roles_entity_role_table",
+		UserRoleSyncMode:    "both",
+	})
+
+	if err == nil {
+		t.Fatal("error MUST NOT be nil for an invalid sync mode")
+	}
+}
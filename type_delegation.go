@@ -0,0 +1,216 @@
+package rolestore
+
+import (
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/dataobject"
+	"github.com/gouniverse/sb"
+	"github.com/gouniverse/uid"
+	"github.com/spf13/cast"
+)
+
+// == CLASS ===================================================================
+
+type delegation struct {
+	dataobject.DataObject
+}
+
+var _ DelegationInterface = (*delegation)(nil)
+
+// == CONSTRUCTORS ============================================================
+
+// NewDelegation creates a new delegation, starting now and ending in 7 days
+func NewDelegation() DelegationInterface {
+	o := (&delegation{}).
+		SetID(uid.HumanUid()).
+		SetNoRedelegation(false).
+		SetMemo("").
+		SetStartsAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
+		SetEndsAt(carbon.Now(carbon.UTC).AddDays(7).ToDateTimeString(carbon.UTC)).
+		SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
+		SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
+		SetSoftDeletedAt(sb.MAX_DATETIME)
+
+	return o
+}
+
+func NewDelegationFromExistingData(data map[string]string) DelegationInterface {
+	o := &delegation{}
+	o.Hydrate(data)
+	return o
+}
+
+// == METHODS =================================================================
+
+// Delegate returns the entity the role is delegated to
+func (o *delegation) Delegate() EntityRef {
+	return NewEntityRef(o.DelegateType(), o.DelegateID())
+}
+
+// Delegator returns the entity delegating the role
+func (o *delegation) Delegator() EntityRef {
+	return NewEntityRef(o.DelegatorType(), o.DelegatorID())
+}
+
+// IsActive returns whether the delegation is not revoked,
+// and now is within its time window
+func (o *delegation) IsActive() bool {
+	now := carbon.Now(carbon.UTC)
+
+	return !o.IsSoftDeleted() &&
+		o.StartsAtCarbon().Compare("<=", now) &&
+		o.EndsAtCarbon().Compare(">", now)
+}
+
+func (o *delegation) IsSoftDeleted() bool {
+	return o.SoftDeletedAtCarbon().Compare("<", carbon.Now(carbon.UTC))
+}
+
+// == SETTERS AND GETTERS =====================================================
+
+func (o *delegation) CreatedAt() string {
+	return o.Get(COLUMN_CREATED_AT)
+}
+
+func (o *delegation) CreatedAtCarbon() carbon.Carbon {
+	return carbon.Parse(o.CreatedAt(), carbon.UTC)
+}
+
+func (o *delegation) SetCreatedAt(createdAt string) DelegationInterface {
+	o.Set(COLUMN_CREATED_AT, createdAt)
+	return o
+}
+
+// DelegateID returns the ID of the entity the role is delegated to
+func (o *delegation) DelegateID() string {
+	return o.Get(COLUMN_DELEGATE_ID)
+}
+
+func (o *delegation) SetDelegateID(delegateID string) DelegationInterface {
+	o.Set(COLUMN_DELEGATE_ID, delegateID)
+	return o
+}
+
+func (o *delegation) DelegateType() string {
+	return o.Get(COLUMN_DELEGATE_TYPE)
+}
+
+func (o *delegation) SetDelegateType(delegateType string) DelegationInterface {
+	o.Set(COLUMN_DELEGATE_TYPE, delegateType)
+	return o
+}
+
+// DelegatorID returns the ID of the entity delegating the role
+func (o *delegation) DelegatorID() string {
+	return o.Get(COLUMN_DELEGATOR_ID)
+}
+
+func (o *delegation) SetDelegatorID(delegatorID string) DelegationInterface {
+	o.Set(COLUMN_DELEGATOR_ID, delegatorID)
+	return o
+}
+
+func (o *delegation) DelegatorType() string {
+	return o.Get(COLUMN_DELEGATOR_TYPE)
+}
+
+func (o *delegation) SetDelegatorType(delegatorType string) DelegationInterface {
+	o.Set(COLUMN_DELEGATOR_TYPE, delegatorType)
+	return o
+}
+
+// EndsAt returns when the delegation ends (exclusive)
+func (o *delegation) EndsAt() string {
+	return o.Get(COLUMN_ENDS_AT)
+}
+
+func (o *delegation) EndsAtCarbon() carbon.Carbon {
+	return carbon.Parse(o.EndsAt(), carbon.UTC)
+}
+
+func (o *delegation) SetEndsAt(endsAt string) DelegationInterface {
+	o.Set(COLUMN_ENDS_AT, endsAt)
+	return o
+}
+
+func (o *delegation) ID() string {
+	return o.Get(COLUMN_ID)
+}
+
+func (o *delegation) SetID(id string) DelegationInterface {
+	o.Set(COLUMN_ID, id)
+	return o
+}
+
+func (o *delegation) Memo() string {
+	return o.Get(COLUMN_MEMO)
+}
+
+func (o *delegation) SetMemo(memo string) DelegationInterface {
+	o.Set(COLUMN_MEMO, memo)
+	return o
+}
+
+// NoRedelegation returns whether the delegate is not allowed
+// to delegate the role further
+func (o *delegation) NoRedelegation() bool {
+	return cast.ToBool(o.Get(COLUMN_NO_REDELEGATION))
+}
+
+func (o *delegation) SetNoRedelegation(noRedelegation bool) DelegationInterface {
+	if noRedelegation {
+		o.Set(COLUMN_NO_REDELEGATION, "1")
+	} else {
+		o.Set(COLUMN_NO_REDELEGATION, "0")
+	}
+
+	return o
+}
+
+func (o *delegation) RoleID() string {
+	return o.Get(COLUMN_ROLE_ID)
+}
+
+func (o *delegation) SetRoleID(roleID string) DelegationInterface {
+	o.Set(COLUMN_ROLE_ID, roleID)
+	return o
+}
+
+func (o *delegation) SoftDeletedAt() string {
+	return o.Get(COLUMN_SOFT_DELETED_AT)
+}
+
+func (o *delegation) SoftDeletedAtCarbon() carbon.Carbon {
+	return carbon.Parse(o.SoftDeletedAt(), carbon.UTC)
+}
+
+func (o *delegation) SetSoftDeletedAt(softDeletedAt string) DelegationInterface {
+	o.Set(COLUMN_SOFT_DELETED_AT, softDeletedAt)
+	return o
+}
+
+// StartsAt returns when the delegation starts
+func (o *delegation) StartsAt() string {
+	return o.Get(COLUMN_STARTS_AT)
+}
+
+func (o *delegation) StartsAtCarbon() carbon.Carbon {
+	return carbon.Parse(o.StartsAt(), carbon.UTC)
+}
+
+func (o *delegation) SetStartsAt(startsAt string) DelegationInterface {
+	o.Set(COLUMN_STARTS_AT, startsAt)
+	return o
+}
+
+func (o *delegation) UpdatedAt() string {
+	return o.Get(COLUMN_UPDATED_AT)
+}
+
+func (o *delegation) UpdatedAtCarbon() carbon.Carbon {
+	return carbon.Parse(o.UpdatedAt(), carbon.UTC)
+}
+
+func (o *delegation) SetUpdatedAt(updatedAt string) DelegationInterface {
+	o.Set(COLUMN_UPDATED_AT, updatedAt)
+	return o
+}
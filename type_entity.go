@@ -7,6 +7,7 @@ import (
 	"github.com/gouniverse/sb"
 	"github.com/gouniverse/uid"
 	"github.com/gouniverse/utils"
+	"github.com/spf13/cast"
 )
 
 // == CLASS ===================================================================
@@ -23,9 +24,14 @@ func NewEntityRole() EntityRoleInterface {
 	o := (&entityRole{}).
 		SetID(uid.HumanUid()).
 		SetMemo("").
+		SetGrantedByType("").
+		SetGrantedByID("").
+		SetReason("").
+		SetSource(ENTITY_ROLE_SOURCE_MANUAL).
 		SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
 		SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
-		SetSoftDeletedAt(sb.MAX_DATETIME)
+		SetSoftDeletedAt(sb.MAX_DATETIME).
+		SetVersion(1)
 
 	err := o.SetMetas(map[string]string{})
 
@@ -81,6 +87,26 @@ func (o *entityRole) SetEntityID(entityID string) EntityRoleInterface {
 	return o
 }
 
+// GrantedByID returns the ID of the entity, which granted the role
+func (o *entityRole) GrantedByID() string {
+	return o.Get(COLUMN_GRANTED_BY_ID)
+}
+
+func (o *entityRole) SetGrantedByID(grantedByID string) EntityRoleInterface {
+	o.Set(COLUMN_GRANTED_BY_ID, grantedByID)
+	return o
+}
+
+// GrantedByType returns the type of the entity, which granted the role
+func (o *entityRole) GrantedByType() string {
+	return o.Get(COLUMN_GRANTED_BY_TYPE)
+}
+
+func (o *entityRole) SetGrantedByType(grantedByType string) EntityRoleInterface {
+	o.Set(COLUMN_GRANTED_BY_TYPE, grantedByType)
+	return o
+}
+
 func (o *entityRole) ID() string {
 	return o.Get(COLUMN_ID)
 }
@@ -170,6 +196,16 @@ func (o *entityRole) SetSoftDeletedAt(deletedAt string) EntityRoleInterface {
 	return o
 }
 
+// Reason returns why the role was granted
+func (o *entityRole) Reason() string {
+	return o.Get(COLUMN_REASON)
+}
+
+func (o *entityRole) SetReason(reason string) EntityRoleInterface {
+	o.Set(COLUMN_REASON, reason)
+	return o
+}
+
 func (o *entityRole) RoleID() string {
 	return o.Get(COLUMN_ROLE_ID)
 }
@@ -179,6 +215,16 @@ func (o *entityRole) SetRoleID(roleID string) EntityRoleInterface {
 	return o
 }
 
+// Source returns how the role was granted, one of the ENTITY_ROLE_SOURCE_* constants
+func (o *entityRole) Source() string {
+	return o.Get(COLUMN_SOURCE)
+}
+
+func (o *entityRole) SetSource(source string) EntityRoleInterface {
+	o.Set(COLUMN_SOURCE, source)
+	return o
+}
+
 func (o *entityRole) UpdatedAt() string {
 	return o.Get(COLUMN_UPDATED_AT)
 }
@@ -191,3 +237,12 @@ func (o *entityRole) SetUpdatedAt(updatedAt string) EntityRoleInterface {
 	o.Set(COLUMN_UPDATED_AT, updatedAt)
 	return o
 }
+
+func (o *entityRole) Version() int {
+	return cast.ToInt(o.Get(COLUMN_VERSION))
+}
+
+func (o *entityRole) SetVersion(version int) EntityRoleInterface {
+	o.Set(COLUMN_VERSION, cast.ToString(version))
+	return o
+}
@@ -0,0 +1,179 @@
+package rolestore
+
+import (
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/dataobject"
+	"github.com/gouniverse/sb"
+	"github.com/gouniverse/uid"
+	"github.com/gouniverse/utils"
+	"github.com/spf13/cast"
+)
+
+// == CLASS ===================================================================
+
+type reviewCampaign struct {
+	dataobject.DataObject
+}
+
+var _ ReviewCampaignInterface = (*reviewCampaign)(nil)
+
+// == CONSTRUCTORS ============================================================
+
+// NewReviewCampaign creates a new open access review campaign,
+// due in 14 days
+func NewReviewCampaign() ReviewCampaignInterface {
+	o := (&reviewCampaign{}).
+		SetID(uid.HumanUid()).
+		SetStatus(REVIEW_CAMPAIGN_STATUS_OPEN).
+		SetTitle("").
+		SetMemo("").
+		SetDueAt(carbon.Now(carbon.UTC).AddDays(14).ToDateTimeString(carbon.UTC)).
+		SetClosedAt(sb.NULL_DATETIME).
+		SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
+		SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	err := o.SetRoleIDs([]string{})
+
+	if err != nil {
+		return o
+	}
+
+	return o
+}
+
+func NewReviewCampaignFromExistingData(data map[string]string) ReviewCampaignInterface {
+	o := &reviewCampaign{}
+	o.Hydrate(data)
+	return o
+}
+
+// == METHODS =================================================================
+
+func (o *reviewCampaign) IsClosed() bool {
+	return o.Status() == REVIEW_CAMPAIGN_STATUS_CLOSED
+}
+
+func (o *reviewCampaign) IsOpen() bool {
+	return o.Status() == REVIEW_CAMPAIGN_STATUS_OPEN
+}
+
+// == SETTERS AND GETTERS =====================================================
+
+func (o *reviewCampaign) ClosedAt() string {
+	return o.Get(COLUMN_CLOSED_AT)
+}
+
+func (o *reviewCampaign) ClosedAtCarbon() carbon.Carbon {
+	return carbon.Parse(o.ClosedAt(), carbon.UTC)
+}
+
+func (o *reviewCampaign) SetClosedAt(closedAt string) ReviewCampaignInterface {
+	o.Set(COLUMN_CLOSED_AT, closedAt)
+	return o
+}
+
+func (o *reviewCampaign) CreatedAt() string {
+	return o.Get(COLUMN_CREATED_AT)
+}
+
+func (o *reviewCampaign) CreatedAtCarbon() carbon.Carbon {
+	return carbon.Parse(o.CreatedAt(), carbon.UTC)
+}
+
+func (o *reviewCampaign) SetCreatedAt(createdAt string) ReviewCampaignInterface {
+	o.Set(COLUMN_CREATED_AT, createdAt)
+	return o
+}
+
+// DueAt returns the date by which the reviewers should decide on their items
+func (o *reviewCampaign) DueAt() string {
+	return o.Get(COLUMN_DUE_AT)
+}
+
+func (o *reviewCampaign) DueAtCarbon() carbon.Carbon {
+	return carbon.Parse(o.DueAt(), carbon.UTC)
+}
+
+func (o *reviewCampaign) SetDueAt(dueAt string) ReviewCampaignInterface {
+	o.Set(COLUMN_DUE_AT, dueAt)
+	return o
+}
+
+func (o *reviewCampaign) ID() string {
+	return o.Get(COLUMN_ID)
+}
+
+func (o *reviewCampaign) SetID(id string) ReviewCampaignInterface {
+	o.Set(COLUMN_ID, id)
+	return o
+}
+
+func (o *reviewCampaign) Memo() string {
+	return o.Get(COLUMN_MEMO)
+}
+
+func (o *reviewCampaign) SetMemo(memo string) ReviewCampaignInterface {
+	o.Set(COLUMN_MEMO, memo)
+	return o
+}
+
+// RoleIDs returns the IDs of the roles, which assignments are reviewed
+func (o *reviewCampaign) RoleIDs() ([]string, error) {
+	roleIDsStr := o.Get(COLUMN_ROLE_IDS)
+
+	if roleIDsStr == "" {
+		roleIDsStr = "[]"
+	}
+
+	roleIDsJson, errJson := utils.FromJSON(roleIDsStr, []any{})
+
+	if errJson != nil {
+		return []string{}, errJson
+	}
+
+	return cast.ToStringSlice(roleIDsJson), nil
+}
+
+// SetRoleIDs stores the IDs of the reviewed roles as json string
+func (o *reviewCampaign) SetRoleIDs(roleIDs []string) error {
+	roleIDsStr, err := utils.ToJSON(roleIDs)
+
+	if err != nil {
+		return err
+	}
+
+	o.Set(COLUMN_ROLE_IDS, roleIDsStr)
+	return nil
+}
+
+// Status returns the status of the campaign, one of the REVIEW_CAMPAIGN_STATUS_* constants
+func (o *reviewCampaign) Status() string {
+	return o.Get(COLUMN_STATUS)
+}
+
+func (o *reviewCampaign) SetStatus(status string) ReviewCampaignInterface {
+	o.Set(COLUMN_STATUS, status)
+	return o
+}
+
+func (o *reviewCampaign) Title() string {
+	return o.Get(COLUMN_TITLE)
+}
+
+func (o *reviewCampaign) SetTitle(title string) ReviewCampaignInterface {
+	o.Set(COLUMN_TITLE, title)
+	return o
+}
+
+func (o *reviewCampaign) UpdatedAt() string {
+	return o.Get(COLUMN_UPDATED_AT)
+}
+
+func (o *reviewCampaign) UpdatedAtCarbon() carbon.Carbon {
+	return carbon.Parse(o.UpdatedAt(), carbon.UTC)
+}
+
+func (o *reviewCampaign) SetUpdatedAt(updatedAt string) ReviewCampaignInterface {
+	o.Set(COLUMN_UPDATED_AT, updatedAt)
+	return o
+}
@@ -0,0 +1,183 @@
+package rolestore
+
+import (
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/dataobject"
+	"github.com/gouniverse/sb"
+	"github.com/gouniverse/uid"
+)
+
+// == CLASS ===================================================================
+
+type reviewItem struct {
+	dataobject.DataObject
+}
+
+var _ ReviewItemInterface = (*reviewItem)(nil)
+
+// == CONSTRUCTORS ============================================================
+
+func NewReviewItem() ReviewItemInterface {
+	o := (&reviewItem{}).
+		SetID(uid.HumanUid()).
+		SetDecision(REVIEW_DECISION_PENDING).
+		SetDecisionNote("").
+		SetDecidedAt(sb.NULL_DATETIME).
+		SetReviewerType("").
+		SetReviewerID("").
+		SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
+		SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	return o
+}
+
+func NewReviewItemFromExistingData(data map[string]string) ReviewItemInterface {
+	o := &reviewItem{}
+	o.Hydrate(data)
+	return o
+}
+
+// == METHODS =================================================================
+
+func (o *reviewItem) IsPending() bool {
+	return o.Decision() == REVIEW_DECISION_PENDING
+}
+
+// Reviewer returns the entity assigned to review the item,
+// empty if no reviewer is assigned yet
+func (o *reviewItem) Reviewer() EntityRef {
+	return NewEntityRef(o.ReviewerType(), o.ReviewerID())
+}
+
+// == SETTERS AND GETTERS =====================================================
+
+func (o *reviewItem) CampaignID() string {
+	return o.Get(COLUMN_CAMPAIGN_ID)
+}
+
+func (o *reviewItem) SetCampaignID(campaignID string) ReviewItemInterface {
+	o.Set(COLUMN_CAMPAIGN_ID, campaignID)
+	return o
+}
+
+func (o *reviewItem) CreatedAt() string {
+	return o.Get(COLUMN_CREATED_AT)
+}
+
+func (o *reviewItem) CreatedAtCarbon() carbon.Carbon {
+	return carbon.Parse(o.CreatedAt(), carbon.UTC)
+}
+
+func (o *reviewItem) SetCreatedAt(createdAt string) ReviewItemInterface {
+	o.Set(COLUMN_CREATED_AT, createdAt)
+	return o
+}
+
+func (o *reviewItem) DecidedAt() string {
+	return o.Get(COLUMN_DECIDED_AT)
+}
+
+func (o *reviewItem) DecidedAtCarbon() carbon.Carbon {
+	return carbon.Parse(o.DecidedAt(), carbon.UTC)
+}
+
+func (o *reviewItem) SetDecidedAt(decidedAt string) ReviewItemInterface {
+	o.Set(COLUMN_DECIDED_AT, decidedAt)
+	return o
+}
+
+// Decision returns the decision of the reviewer, one of the REVIEW_DECISION_* constants
+func (o *reviewItem) Decision() string {
+	return o.Get(COLUMN_DECISION)
+}
+
+func (o *reviewItem) SetDecision(decision string) ReviewItemInterface {
+	o.Set(COLUMN_DECISION, decision)
+	return o
+}
+
+func (o *reviewItem) DecisionNote() string {
+	return o.Get(COLUMN_DECISION_NOTE)
+}
+
+func (o *reviewItem) SetDecisionNote(decisionNote string) ReviewItemInterface {
+	o.Set(COLUMN_DECISION_NOTE, decisionNote)
+	return o
+}
+
+func (o *reviewItem) EntityID() string {
+	return o.Get(COLUMN_ENTITY_ID)
+}
+
+func (o *reviewItem) SetEntityID(entityID string) ReviewItemInterface {
+	o.Set(COLUMN_ENTITY_ID, entityID)
+	return o
+}
+
+// EntityRoleID returns the ID of the reviewed role entity mapping
+func (o *reviewItem) EntityRoleID() string {
+	return o.Get(COLUMN_ENTITY_ROLE_ID)
+}
+
+func (o *reviewItem) SetEntityRoleID(entityRoleID string) ReviewItemInterface {
+	o.Set(COLUMN_ENTITY_ROLE_ID, entityRoleID)
+	return o
+}
+
+func (o *reviewItem) EntityType() string {
+	return o.Get(COLUMN_ENTITY_TYPE)
+}
+
+func (o *reviewItem) SetEntityType(entityType string) ReviewItemInterface {
+	o.Set(COLUMN_ENTITY_TYPE, entityType)
+	return o
+}
+
+func (o *reviewItem) ID() string {
+	return o.Get(COLUMN_ID)
+}
+
+func (o *reviewItem) SetID(id string) ReviewItemInterface {
+	o.Set(COLUMN_ID, id)
+	return o
+}
+
+func (o *reviewItem) ReviewerID() string {
+	return o.Get(COLUMN_REVIEWER_ID)
+}
+
+func (o *reviewItem) SetReviewerID(reviewerID string) ReviewItemInterface {
+	o.Set(COLUMN_REVIEWER_ID, reviewerID)
+	return o
+}
+
+func (o *reviewItem) ReviewerType() string {
+	return o.Get(COLUMN_REVIEWER_TYPE)
+}
+
+func (o *reviewItem) SetReviewerType(reviewerType string) ReviewItemInterface {
+	o.Set(COLUMN_REVIEWER_TYPE, reviewerType)
+	return o
+}
+
+func (o *reviewItem) RoleID() string {
+	return o.Get(COLUMN_ROLE_ID)
+}
+
+func (o *reviewItem) SetRoleID(roleID string) ReviewItemInterface {
+	o.Set(COLUMN_ROLE_ID, roleID)
+	return o
+}
+
+func (o *reviewItem) UpdatedAt() string {
+	return o.Get(COLUMN_UPDATED_AT)
+}
+
+func (o *reviewItem) UpdatedAtCarbon() carbon.Carbon {
+	return carbon.Parse(o.UpdatedAt(), carbon.UTC)
+}
+
+func (o *reviewItem) SetUpdatedAt(updatedAt string) ReviewItemInterface {
+	o.Set(COLUMN_UPDATED_AT, updatedAt)
+	return o
+}
@@ -1,12 +1,18 @@
 package rolestore
 
 import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
 	"github.com/dromara/carbon/v2"
 	"github.com/gouniverse/dataobject"
 	"github.com/gouniverse/maputils"
 	"github.com/gouniverse/sb"
 	"github.com/gouniverse/uid"
 	"github.com/gouniverse/utils"
+	"github.com/spf13/cast"
 )
 
 // == CLASS ===================================================================
@@ -26,7 +32,8 @@ func NewRole() RoleInterface {
 		SetMemo("").
 		SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
 		SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
-		SetSoftDeletedAt(sb.MAX_DATETIME)
+		SetSoftDeletedAt(sb.MAX_DATETIME).
+		SetVersion(1)
 
 	err := o.SetMetas(map[string]string{})
 
@@ -45,6 +52,29 @@ func NewRoleFromExistingData(data map[string]string) RoleInterface {
 
 // == METHODS =================================================================
 
+// AllowsEntityType returns whether the role may be assigned to entities
+// of the given type. A role without declared entity types allows any type
+func (o *role) AllowsEntityType(entityType string) bool {
+	entityTypes := o.EntityTypes()
+
+	if len(entityTypes) == 0 {
+		return true
+	}
+
+	return slices.Contains(entityTypes, entityType)
+}
+
+// IsBreakGlass returns whether the role may be elevated to without approval,
+// in an emergency. Such elevations are marked for mandatory review
+func (o *role) IsBreakGlass() bool {
+	return cast.ToBool(o.Meta(ROLE_META_BREAK_GLASS))
+}
+
+// IsApprover returns whether the entity may approve requests for the role
+func (o *role) IsApprover(entity EntityRef) bool {
+	return slices.Contains(o.Approvers(), entity)
+}
+
 func (o *role) IsActive() bool {
 	return o.Status() == ROLE_STATUS_ACTIVE
 }
@@ -59,6 +89,55 @@ func (o *role) IsInactive() bool {
 
 // == SETTERS AND GETTERS =====================================================
 
+// Approvers returns the entities, which may approve requests for the role,
+// stored in the ROLE_META_APPROVERS meta as a comma separated list of type:id
+func (o *role) Approvers() []EntityRef {
+	value := o.Meta(ROLE_META_APPROVERS)
+
+	if value == "" {
+		return []EntityRef{}
+	}
+
+	approvers := []EntityRef{}
+
+	for _, approver := range strings.Split(value, ",") {
+		entityType, entityID, _ := strings.Cut(approver, ":")
+		approvers = append(approvers, NewEntityRef(entityType, entityID))
+	}
+
+	return approvers
+}
+
+// SetApprovers sets the entities, which may approve requests for the role.
+// The separators of the stored list are rejected, a comma in the type or ID,
+// and a colon in the type
+func (o *role) SetApprovers(approvers []EntityRef) error {
+	values := make([]string, 0, len(approvers))
+
+	for _, approver := range approvers {
+		if strings.ContainsAny(approver.EntityType, ",:") {
+			return errors.New("rolestore > SetApprovers. approver type must not contain a comma or colon: " + approver.EntityType)
+		}
+
+		if strings.Contains(approver.EntityID, ",") {
+			return errors.New("rolestore > SetApprovers. approver ID must not contain a comma: " + approver.EntityID)
+		}
+
+		values = append(values, approver.EntityType+":"+approver.EntityID)
+	}
+
+	return o.SetMeta(ROLE_META_APPROVERS, strings.Join(values, ","))
+}
+
+// SetBreakGlass sets whether the role may be elevated to without approval
+func (o *role) SetBreakGlass(breakGlass bool) error {
+	if !breakGlass {
+		return o.SetMeta(ROLE_META_BREAK_GLASS, "")
+	}
+
+	return o.SetMeta(ROLE_META_BREAK_GLASS, "true")
+}
+
 func (o *role) CreatedAt() string {
 	return o.Get(COLUMN_CREATED_AT)
 }
@@ -72,6 +151,48 @@ func (o *role) SetCreatedAt(createdAt string) RoleInterface {
 	return o
 }
 
+// EntityTypes returns the entity types the role may be assigned to,
+// stored in the ROLE_META_ENTITY_TYPES meta as a comma separated list
+func (o *role) EntityTypes() []string {
+	value := o.Meta(ROLE_META_ENTITY_TYPES)
+
+	if value == "" {
+		return []string{}
+	}
+
+	return strings.Split(value, ",")
+}
+
+// SetEntityTypes sets the entity types the role may be assigned to,
+// an empty list allows any entity type. Empty types, and types with the
+// comma separator of the stored list, are rejected
+func (o *role) SetEntityTypes(entityTypes []string) error {
+	for _, entityType := range entityTypes {
+		if entityType == "" || strings.Contains(entityType, ",") {
+			return errors.New("rolestore > SetEntityTypes. entity type must not be empty or contain a comma: " + entityType)
+		}
+	}
+
+	return o.SetMeta(ROLE_META_ENTITY_TYPES, strings.Join(entityTypes, ","))
+}
+
+// ElevationMaxDuration returns the maximum duration of a just-in-time
+// elevation to the role, stored in the ROLE_META_ELEVATION_MAX_DURATION
+// meta as a duration string (i.e. "2h"). Zero means unlimited
+func (o *role) ElevationMaxDuration() time.Duration {
+	return cast.ToDuration(o.Meta(ROLE_META_ELEVATION_MAX_DURATION))
+}
+
+// SetElevationMaxDuration sets the maximum duration of a just-in-time
+// elevation to the role, zero removes the limit
+func (o *role) SetElevationMaxDuration(maxDuration time.Duration) error {
+	if maxDuration <= 0 {
+		return o.SetMeta(ROLE_META_ELEVATION_MAX_DURATION, "")
+	}
+
+	return o.SetMeta(ROLE_META_ELEVATION_MAX_DURATION, maxDuration.String())
+}
+
 func (o *role) Handle() string {
 	return o.Get(COLUMN_HANDLE)
 }
@@ -90,6 +211,22 @@ func (o *role) SetID(id string) RoleInterface {
 	return o
 }
 
+// MaxHolders returns the maximum number of entities which may hold the role,
+// stored in the ROLE_META_MAX_HOLDERS meta. Zero means unlimited
+func (o *role) MaxHolders() int {
+	return cast.ToInt(o.Meta(ROLE_META_MAX_HOLDERS))
+}
+
+// SetMaxHolders sets the maximum number of entities which may hold the role,
+// zero removes the limit
+func (o *role) SetMaxHolders(maxHolders int) error {
+	if maxHolders <= 0 {
+		return o.SetMeta(ROLE_META_MAX_HOLDERS, "")
+	}
+
+	return o.SetMeta(ROLE_META_MAX_HOLDERS, cast.ToString(maxHolders))
+}
+
 func (o *role) Memo() string {
 	return o.Get(COLUMN_MEMO)
 }
@@ -200,3 +337,12 @@ func (o *role) SetUpdatedAt(updatedAt string) RoleInterface {
 	o.Set(COLUMN_UPDATED_AT, updatedAt)
 	return o
 }
+
+func (o *role) Version() int {
+	return cast.ToInt(o.Get(COLUMN_VERSION))
+}
+
+func (o *role) SetVersion(version int) RoleInterface {
+	o.Set(COLUMN_VERSION, cast.ToString(version))
+	return o
+}
@@ -0,0 +1,205 @@
+package rolestore
+
+import (
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/dataobject"
+	"github.com/gouniverse/sb"
+	"github.com/gouniverse/uid"
+)
+
+// == CLASS ===================================================================
+
+type roleRequest struct {
+	dataobject.DataObject
+}
+
+var _ RoleRequestInterface = (*roleRequest)(nil)
+
+// == CONSTRUCTORS ============================================================
+
+// NewRoleRequest creates a new pending role request,
+// which expires in 14 days unless decided before
+func NewRoleRequest() RoleRequestInterface {
+	o := (&roleRequest{}).
+		SetID(uid.HumanUid()).
+		SetStatus(ROLE_REQUEST_STATUS_PENDING).
+		SetJustification("").
+		SetDecidedByType("").
+		SetDecidedByID("").
+		SetDecidedAt(sb.NULL_DATETIME).
+		SetDecisionNote("").
+		SetEntityRoleID("").
+		SetExpiresAt(carbon.Now(carbon.UTC).AddDays(14).ToDateTimeString(carbon.UTC)).
+		SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
+		SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	return o
+}
+
+func NewRoleRequestFromExistingData(data map[string]string) RoleRequestInterface {
+	o := &roleRequest{}
+	o.Hydrate(data)
+	return o
+}
+
+// == METHODS =================================================================
+
+// IsExpired returns whether the request is past its expiry date,
+// regardless of whether it has been marked as expired yet
+func (o *roleRequest) IsExpired() bool {
+	return o.ExpiresAtCarbon().Compare("<=", carbon.Now(carbon.UTC))
+}
+
+func (o *roleRequest) IsPending() bool {
+	return o.Status() == ROLE_REQUEST_STATUS_PENDING
+}
+
+// == SETTERS AND GETTERS =====================================================
+
+func (o *roleRequest) CreatedAt() string {
+	return o.Get(COLUMN_CREATED_AT)
+}
+
+func (o *roleRequest) CreatedAtCarbon() carbon.Carbon {
+	return carbon.Parse(o.CreatedAt(), carbon.UTC)
+}
+
+func (o *roleRequest) SetCreatedAt(createdAt string) RoleRequestInterface {
+	o.Set(COLUMN_CREATED_AT, createdAt)
+	return o
+}
+
+func (o *roleRequest) DecidedAt() string {
+	return o.Get(COLUMN_DECIDED_AT)
+}
+
+func (o *roleRequest) DecidedAtCarbon() carbon.Carbon {
+	return carbon.Parse(o.DecidedAt(), carbon.UTC)
+}
+
+func (o *roleRequest) SetDecidedAt(decidedAt string) RoleRequestInterface {
+	o.Set(COLUMN_DECIDED_AT, decidedAt)
+	return o
+}
+
+// DecidedByID returns the ID of the entity, which approved or rejected the request
+func (o *roleRequest) DecidedByID() string {
+	return o.Get(COLUMN_DECIDED_BY_ID)
+}
+
+func (o *roleRequest) SetDecidedByID(decidedByID string) RoleRequestInterface {
+	o.Set(COLUMN_DECIDED_BY_ID, decidedByID)
+	return o
+}
+
+// DecidedByType returns the type of the entity, which approved or rejected the request
+func (o *roleRequest) DecidedByType() string {
+	return o.Get(COLUMN_DECIDED_BY_TYPE)
+}
+
+func (o *roleRequest) SetDecidedByType(decidedByType string) RoleRequestInterface {
+	o.Set(COLUMN_DECIDED_BY_TYPE, decidedByType)
+	return o
+}
+
+// DecisionNote returns the note the approver left with the decision
+func (o *roleRequest) DecisionNote() string {
+	return o.Get(COLUMN_DECISION_NOTE)
+}
+
+func (o *roleRequest) SetDecisionNote(decisionNote string) RoleRequestInterface {
+	o.Set(COLUMN_DECISION_NOTE, decisionNote)
+	return o
+}
+
+func (o *roleRequest) EntityID() string {
+	return o.Get(COLUMN_ENTITY_ID)
+}
+
+func (o *roleRequest) SetEntityID(entityID string) RoleRequestInterface {
+	o.Set(COLUMN_ENTITY_ID, entityID)
+	return o
+}
+
+// EntityRoleID returns the ID of the role entity mapping created on approval
+func (o *roleRequest) EntityRoleID() string {
+	return o.Get(COLUMN_ENTITY_ROLE_ID)
+}
+
+func (o *roleRequest) SetEntityRoleID(entityRoleID string) RoleRequestInterface {
+	o.Set(COLUMN_ENTITY_ROLE_ID, entityRoleID)
+	return o
+}
+
+func (o *roleRequest) EntityType() string {
+	return o.Get(COLUMN_ENTITY_TYPE)
+}
+
+func (o *roleRequest) SetEntityType(entityType string) RoleRequestInterface {
+	o.Set(COLUMN_ENTITY_TYPE, entityType)
+	return o
+}
+
+func (o *roleRequest) ExpiresAt() string {
+	return o.Get(COLUMN_EXPIRES_AT)
+}
+
+func (o *roleRequest) ExpiresAtCarbon() carbon.Carbon {
+	return carbon.Parse(o.ExpiresAt(), carbon.UTC)
+}
+
+func (o *roleRequest) SetExpiresAt(expiresAt string) RoleRequestInterface {
+	o.Set(COLUMN_EXPIRES_AT, expiresAt)
+	return o
+}
+
+func (o *roleRequest) ID() string {
+	return o.Get(COLUMN_ID)
+}
+
+func (o *roleRequest) SetID(id string) RoleRequestInterface {
+	o.Set(COLUMN_ID, id)
+	return o
+}
+
+// Justification returns why the entity requests the role
+func (o *roleRequest) Justification() string {
+	return o.Get(COLUMN_JUSTIFICATION)
+}
+
+func (o *roleRequest) SetJustification(justification string) RoleRequestInterface {
+	o.Set(COLUMN_JUSTIFICATION, justification)
+	return o
+}
+
+func (o *roleRequest) RoleID() string {
+	return o.Get(COLUMN_ROLE_ID)
+}
+
+func (o *roleRequest) SetRoleID(roleID string) RoleRequestInterface {
+	o.Set(COLUMN_ROLE_ID, roleID)
+	return o
+}
+
+// Status returns the status of the request, one of the ROLE_REQUEST_STATUS_* constants
+func (o *roleRequest) Status() string {
+	return o.Get(COLUMN_STATUS)
+}
+
+func (o *roleRequest) SetStatus(status string) RoleRequestInterface {
+	o.Set(COLUMN_STATUS, status)
+	return o
+}
+
+func (o *roleRequest) UpdatedAt() string {
+	return o.Get(COLUMN_UPDATED_AT)
+}
+
+func (o *roleRequest) UpdatedAtCarbon() carbon.Carbon {
+	return carbon.Parse(o.UpdatedAt(), carbon.UTC)
+}
+
+func (o *roleRequest) SetUpdatedAt(updatedAt string) RoleRequestInterface {
+	o.Set(COLUMN_UPDATED_AT, updatedAt)
+	return o
+}
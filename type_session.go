@@ -0,0 +1,144 @@
+package rolestore
+
+import (
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/dataobject"
+	"github.com/gouniverse/uid"
+	"github.com/gouniverse/utils"
+	"github.com/spf13/cast"
+)
+
+// == CLASS ===================================================================
+
+type session struct {
+	dataobject.DataObject
+}
+
+var _ SessionInterface = (*session)(nil)
+
+// == CONSTRUCTORS ============================================================
+
+func NewSession() SessionInterface {
+	o := (&session{}).
+		SetID(uid.HumanUid()).
+		SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
+		SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
+		SetExpiresAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+
+	err := o.SetActiveRoleIDs([]string{})
+
+	if err != nil {
+		return o
+	}
+
+	return o
+}
+
+func NewSessionFromExistingData(data map[string]string) SessionInterface {
+	o := &session{}
+	o.Hydrate(data)
+	return o
+}
+
+// == METHODS =================================================================
+
+func (o *session) IsExpired() bool {
+	return o.ExpiresAtCarbon().Compare("<=", carbon.Now(carbon.UTC))
+}
+
+// == SETTERS AND GETTERS =====================================================
+
+// ActiveRoleIDs returns the IDs of the roles activated in the session
+func (o *session) ActiveRoleIDs() ([]string, error) {
+	roleIDsStr := o.Get(COLUMN_ACTIVE_ROLE_IDS)
+
+	if roleIDsStr == "" {
+		roleIDsStr = "[]"
+	}
+
+	roleIDsJson, errJson := utils.FromJSON(roleIDsStr, []any{})
+
+	if errJson != nil {
+		return []string{}, errJson
+	}
+
+	return cast.ToStringSlice(roleIDsJson), nil
+}
+
+// SetActiveRoleIDs stores the IDs of the roles activated in the session as json string
+func (o *session) SetActiveRoleIDs(roleIDs []string) error {
+	roleIDsStr, err := utils.ToJSON(roleIDs)
+
+	if err != nil {
+		return err
+	}
+
+	o.Set(COLUMN_ACTIVE_ROLE_IDS, roleIDsStr)
+	return nil
+}
+
+func (o *session) CreatedAt() string {
+	return o.Get(COLUMN_CREATED_AT)
+}
+
+func (o *session) CreatedAtCarbon() carbon.Carbon {
+	return carbon.Parse(o.CreatedAt(), carbon.UTC)
+}
+
+func (o *session) SetCreatedAt(createdAt string) SessionInterface {
+	o.Set(COLUMN_CREATED_AT, createdAt)
+	return o
+}
+
+func (o *session) EntityID() string {
+	return o.Get(COLUMN_ENTITY_ID)
+}
+
+func (o *session) SetEntityID(entityID string) SessionInterface {
+	o.Set(COLUMN_ENTITY_ID, entityID)
+	return o
+}
+
+func (o *session) EntityType() string {
+	return o.Get(COLUMN_ENTITY_TYPE)
+}
+
+func (o *session) SetEntityType(entityType string) SessionInterface {
+	o.Set(COLUMN_ENTITY_TYPE, entityType)
+	return o
+}
+
+func (o *session) ExpiresAt() string {
+	return o.Get(COLUMN_EXPIRES_AT)
+}
+
+func (o *session) ExpiresAtCarbon() carbon.Carbon {
+	return carbon.Parse(o.ExpiresAt(), carbon.UTC)
+}
+
+func (o *session) SetExpiresAt(expiresAt string) SessionInterface {
+	o.Set(COLUMN_EXPIRES_AT, expiresAt)
+	return o
+}
+
+func (o *session) ID() string {
+	return o.Get(COLUMN_ID)
+}
+
+func (o *session) SetID(id string) SessionInterface {
+	o.Set(COLUMN_ID, id)
+	return o
+}
+
+func (o *session) UpdatedAt() string {
+	return o.Get(COLUMN_UPDATED_AT)
+}
+
+func (o *session) UpdatedAtCarbon() carbon.Carbon {
+	return carbon.NewCarbon().Parse(o.Get(COLUMN_UPDATED_AT), carbon.UTC)
+}
+
+func (o *session) SetUpdatedAt(updatedAt string) SessionInterface {
+	o.Set(COLUMN_UPDATED_AT, updatedAt)
+	return o
+}
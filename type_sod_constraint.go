@@ -0,0 +1,167 @@
+package rolestore
+
+import (
+	"github.com/dromara/carbon/v2"
+	"github.com/gouniverse/dataobject"
+	"github.com/gouniverse/sb"
+	"github.com/gouniverse/uid"
+	"github.com/gouniverse/utils"
+	"github.com/spf13/cast"
+)
+
+// == CLASS ===================================================================
+
+type sodConstraint struct {
+	dataobject.DataObject
+}
+
+var _ SodConstraintInterface = (*sodConstraint)(nil)
+
+// == CONSTRUCTORS ============================================================
+
+func NewSodConstraint() SodConstraintInterface {
+	o := (&sodConstraint{}).
+		SetID(uid.HumanUid()).
+		SetKind(SOD_KIND_STATIC).
+		SetTitle("").
+		SetMaxRoles(1).
+		SetMemo("").
+		SetCreatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
+		SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
+		SetSoftDeletedAt(sb.MAX_DATETIME)
+
+	err := o.SetRoleIDs([]string{})
+
+	if err != nil {
+		return o
+	}
+
+	return o
+}
+
+func NewSodConstraintFromExistingData(data map[string]string) SodConstraintInterface {
+	o := &sodConstraint{}
+	o.Hydrate(data)
+	return o
+}
+
+// == METHODS =================================================================
+
+func (o *sodConstraint) IsSoftDeleted() bool {
+	return o.SoftDeletedAtCarbon().Compare("<", carbon.Now(carbon.UTC))
+}
+
+// == SETTERS AND GETTERS =====================================================
+
+func (o *sodConstraint) CreatedAt() string {
+	return o.Get(COLUMN_CREATED_AT)
+}
+
+func (o *sodConstraint) CreatedAtCarbon() carbon.Carbon {
+	return carbon.Parse(o.CreatedAt(), carbon.UTC)
+}
+
+func (o *sodConstraint) SetCreatedAt(createdAt string) SodConstraintInterface {
+	o.Set(COLUMN_CREATED_AT, createdAt)
+	return o
+}
+
+func (o *sodConstraint) ID() string {
+	return o.Get(COLUMN_ID)
+}
+
+func (o *sodConstraint) SetID(id string) SodConstraintInterface {
+	o.Set(COLUMN_ID, id)
+	return o
+}
+
+func (o *sodConstraint) Kind() string {
+	return o.Get(COLUMN_KIND)
+}
+
+func (o *sodConstraint) SetKind(kind string) SodConstraintInterface {
+	o.Set(COLUMN_KIND, kind)
+	return o
+}
+
+func (o *sodConstraint) MaxRoles() int {
+	return cast.ToInt(o.Get(COLUMN_MAX_ROLES))
+}
+
+func (o *sodConstraint) SetMaxRoles(maxRoles int) SodConstraintInterface {
+	o.Set(COLUMN_MAX_ROLES, cast.ToString(maxRoles))
+	return o
+}
+
+func (o *sodConstraint) Memo() string {
+	return o.Get(COLUMN_MEMO)
+}
+
+func (o *sodConstraint) SetMemo(memo string) SodConstraintInterface {
+	o.Set(COLUMN_MEMO, memo)
+	return o
+}
+
+// RoleIDs returns the IDs of the roles in the constraint set
+func (o *sodConstraint) RoleIDs() ([]string, error) {
+	roleIDsStr := o.Get(COLUMN_ROLE_IDS)
+
+	if roleIDsStr == "" {
+		roleIDsStr = "[]"
+	}
+
+	roleIDsJson, errJson := utils.FromJSON(roleIDsStr, []any{})
+
+	if errJson != nil {
+		return []string{}, errJson
+	}
+
+	return cast.ToStringSlice(roleIDsJson), nil
+}
+
+// SetRoleIDs stores the IDs of the roles in the constraint set as json string
+func (o *sodConstraint) SetRoleIDs(roleIDs []string) error {
+	roleIDsStr, err := utils.ToJSON(roleIDs)
+
+	if err != nil {
+		return err
+	}
+
+	o.Set(COLUMN_ROLE_IDS, roleIDsStr)
+	return nil
+}
+
+func (o *sodConstraint) SoftDeletedAt() string {
+	return o.Get(COLUMN_SOFT_DELETED_AT)
+}
+
+func (o *sodConstraint) SoftDeletedAtCarbon() carbon.Carbon {
+	return carbon.NewCarbon().Parse(o.SoftDeletedAt(), carbon.UTC)
+}
+
+func (o *sodConstraint) SetSoftDeletedAt(softDeletedAt string) SodConstraintInterface {
+	o.Set(COLUMN_SOFT_DELETED_AT, softDeletedAt)
+	return o
+}
+
+func (o *sodConstraint) Title() string {
+	return o.Get(COLUMN_TITLE)
+}
+
+func (o *sodConstraint) SetTitle(title string) SodConstraintInterface {
+	o.Set(COLUMN_TITLE, title)
+	return o
+}
+
+func (o *sodConstraint) UpdatedAt() string {
+	return o.Get(COLUMN_UPDATED_AT)
+}
+
+func (o *sodConstraint) UpdatedAtCarbon() carbon.Carbon {
+	return carbon.NewCarbon().Parse(o.Get(COLUMN_UPDATED_AT), carbon.UTC)
+}
+
+func (o *sodConstraint) SetUpdatedAt(updatedAt string) SodConstraintInterface {
+	o.Set(COLUMN_UPDATED_AT, updatedAt)
+	return o
+}